@@ -10,6 +10,7 @@ import (
 
 	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/notify"
 	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
 	"github.com/spf13/cobra"
@@ -87,6 +88,15 @@ func runAdd(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("worktree already exists: %s/%s", cfg.WorktreeDir, branch)
 	}
 
+	if err := checkDiskFloor(ctx, runner, projectRoot, cfg, branch); err != nil {
+		return err
+	}
+
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: worktreePath, Branch: branch}
+	if err := project.RunHooks(ctx, cfg, project.HookPreAdd, target, dry); err != nil {
+		return fmt.Errorf("worktree not created: %w", err)
+	}
+
 	hasRemote, err := runner.HasRemoteBranch(ctx, branch)
 	if err != nil {
 		return err
@@ -114,11 +124,12 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	runPostApplyHooks(ctx, cfg, target, result, dry)
 
 	msg := fmt.Sprintf("Worktree created: %s/%s (%d copied, %d symlinked)",
 		cfg.WorktreeDir, branch, result.Copied, result.Symlinked)
 
-	hasHooks := len(cfg.Setup) > 0 || len(cfg.ParallelSetup) > 0
+	hasHooks := cfg.HasSetup()
 	skipSetup, _ := cmd.Flags().GetBool("skip-setup")
 
 	if skipSetup && hasHooks {
@@ -149,10 +160,10 @@ func runAdd(cmd *cobra.Command, args []string) error {
 	}
 
 	if background {
-		return runSetupBackground(projectRoot, worktreePath, cfg, dry, msg)
+		return runSetupBackground(projectRoot, worktreePath, branch, cfg, dry, msg)
 	}
 
-	return runSetupForeground(cmd, worktreePath, cfg, dry, msg)
+	return runSetupForeground(cmd, projectRoot, worktreePath, branch, cfg, dry, msg)
 }
 
 // resolveBackgroundMode determines whether setup should run in background.
@@ -172,13 +183,16 @@ func resolveBackgroundMode(cmd *cobra.Command, cfg *config.Config) (bool, error)
 	return cfg.BackgroundSetup, nil
 }
 
-func runSetupForeground(cmd *cobra.Command, worktreePath string, cfg *config.Config, dry bool, msg string) error {
+func runSetupForeground(cmd *cobra.Command, projectRoot, worktreePath, branch string, cfg *config.Config, dry bool, msg string) error {
 	ctx := cmd.Context()
 	startedAt := time.Now()
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: worktreePath, Branch: branch}
 
-	var setupErr error
-	setupErr = project.RunSetupHooks(ctx, cfg, worktreePath, dry, nil)
-	if pErr := project.RunParallelSetupHooks(ctx, cfg, worktreePath, dry); pErr != nil {
+	setupErr := project.ComposeUp(ctx, cfg, target, dry)
+	if hErr := project.RunSetupHooks(ctx, cfg, target, dry, nil); hErr != nil {
+		setupErr = errors.Join(setupErr, hErr)
+	}
+	if pErr := project.RunParallelSetupHooks(ctx, cfg, target, dry); pErr != nil {
 		setupErr = errors.Join(setupErr, pErr)
 	}
 
@@ -187,8 +201,8 @@ func runSetupForeground(cmd *cobra.Command, worktreePath string, cfg *config.Con
 			Status:         project.SetupComplete,
 			StartedAt:      startedAt,
 			CompletedAt:    time.Now(),
-			HooksTotal:     len(cfg.Setup) + len(cfg.ParallelSetup),
-			HooksCompleted: len(cfg.Setup) + len(cfg.ParallelSetup),
+			HooksTotal:     cfg.SetupSteps(),
+			HooksCompleted: cfg.SetupSteps(),
 		}
 		if setupErr != nil {
 			state.Status = project.SetupFailed
@@ -209,8 +223,8 @@ func runSetupForeground(cmd *cobra.Command, worktreePath string, cfg *config.Con
 	return nil
 }
 
-func runSetupBackground(projectRoot, worktreePath string, cfg *config.Config, dry bool, msg string) error {
-	hooksTotal := len(cfg.Setup) + len(cfg.ParallelSetup)
+func runSetupBackground(projectRoot, worktreePath, branch string, cfg *config.Config, dry bool, msg string) error {
+	hooksTotal := cfg.SetupSteps()
 
 	if dry {
 		ui.DryRunNotice("would launch background setup process")
@@ -224,10 +238,18 @@ func runSetupBackground(projectRoot, worktreePath string, cfg *config.Config, dr
 		return fmt.Errorf("cannot find wt binary: %w", err)
 	}
 
-	child := exec.Command(exe, "_run-setup",
+	args := []string{
+		"_run-setup",
 		"--worktree-path", worktreePath,
 		"--project-root", projectRoot,
-	)
+		"--branch", branch,
+	}
+	// The child is detached from our terminal, so hand it the device path
+	// for the terminal notifier to write to.
+	if tty := notify.TTYName(os.Stderr); tty != "" {
+		args = append(args, "--tty", tty)
+	}
+	child := exec.Command(exe, args...)
 	detachProcess(child)
 
 	// Redirect child's stdio to /dev/null so it doesn't inherit the parent's
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 
+	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/git"
 	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
@@ -18,6 +23,15 @@ func newApplyCmd() *cobra.Command {
 		RunE:              runApply,
 	}
 	cmd.Flags().Bool("all", false, "Apply to all worktrees")
+	cmd.Flags().Bool("check", false, "Report copied files that differ from shared/copy and exit non-zero if any do")
+	cmd.Flags().Bool("diff", false, "Print a unified diff of each copied file that differs from shared/copy")
+	cmd.Flags().Bool("prune", false, "Remove files and symlinks whose source was deleted from shared/, unless changed since applied")
+	cmd.Flags().String("explain", "", "Show which shared layer supplies a file (path relative to the worktree) and change nothing")
+	cmd.Flags().String("conflict", "", "How to handle copied files changed in the worktree: overwrite, skip-modified, prompt, or backup")
+	cmd.Flags().Bool("watch", false, "Keep running and propagate changes in shared/ to every worktree (or the named one) until interrupted")
+	for _, other := range []string{"check", "diff", "prune", "explain"} {
+		cmd.MarkFlagsMutuallyExclusive("watch", other)
+	}
 	return cmd
 }
 
@@ -30,7 +44,8 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), dry)
+	gitDir := project.GitDirPath(projectRoot, cfg)
+	runner := git.NewRunner(gitDir, dry)
 
 	worktrees, err := runner.WorktreeList(ctx)
 	if err != nil {
@@ -44,21 +59,86 @@ func runApply(cmd *cobra.Command, args []string) error {
 	}
 
 	all, _ := cmd.Flags().GetBool("all")
+	check, _ := cmd.Flags().GetBool("check")
+	showDiff, _ := cmd.Flags().GetBool("diff")
+
+	if cmd.Flags().Changed("conflict") {
+		value, _ := cmd.Flags().GetString("conflict")
+		policy, err := config.ParseConflictPolicy(value)
+		if err != nil {
+			return err
+		}
+		cfg.ApplyConflict = policy
+	}
+
+	if watch, _ := cmd.Flags().GetBool("watch"); watch {
+		targets := filtered
+		if len(args) > 0 {
+			selected, err := selectWorktree(args, filtered)
+			if err != nil {
+				if ui.IsUserAbort(err) {
+					return nil
+				}
+				return err
+			}
+			targets = []git.WorktreeInfo{selected}
+		}
+		return watchShared(ctx, projectRoot, cfg, targets, dry)
+	}
+
+	explain, _ := cmd.Flags().GetString("explain")
+
+	if check || showDiff || explain != "" {
+		targets := filtered
+		if !all {
+			selected, err := selectWorktree(args, filtered)
+			if err != nil {
+				if ui.IsUserAbort(err) {
+					return nil
+				}
+				return err
+			}
+			targets = []git.WorktreeInfo{selected}
+		}
+		if explain != "" {
+			return explainFile(projectRoot, cfg, targets, explain)
+		}
+		return reportDrift(projectRoot, cfg, targets, check, showDiff)
+	}
+
+	// Keep the manifest out of git status (idempotent, self-heals existing projects).
+	if err := project.EnsureGitExclude(gitDir, dry); err != nil {
+		ui.Warning("Could not configure git excludes: " + err.Error())
+	}
+
+	prune, _ := cmd.Flags().GetBool("prune")
 
 	if all {
 		var totalResult project.ApplyResult
+		var totalPruned int
+		secrets := project.NewSecrets(projectRoot, cfg)
 		for _, wt := range filtered {
 			ui.Step("Applying to: " + wt.Branch)
 			vars := project.NewTemplateVars(projectRoot, wt.Path, wt.Branch)
+			vars.Secrets = secrets
 			result, err := project.Apply(projectRoot, wt.Path, cfg, dry, &vars)
 			if err != nil {
 				return err
 			}
-			totalResult.Copied += result.Copied
-			totalResult.Symlinked += result.Symlinked
+			if prune {
+				n, err := project.PruneStale(projectRoot, wt.Path, cfg, dry)
+				if err != nil {
+					return err
+				}
+				totalPruned += n
+			}
+			target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+			runPostApplyHooks(ctx, cfg, target, result, dry)
+			totalResult.Add(result)
 		}
-		ui.Success(fmt.Sprintf("Applied shared files to %d worktree(s) (%d copied, %d symlinked)",
-			len(filtered), totalResult.Copied, totalResult.Symlinked))
+		logCopyStrategies(totalResult)
+		ui.Success(fmt.Sprintf("Applied shared files to %d worktree(s) (%d copied, %d symlinked%s)",
+			len(filtered), totalResult.Copied, totalResult.Symlinked, prunedSuffix(prune, totalPruned)))
 		return nil
 	}
 
@@ -75,8 +155,139 @@ func runApply(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	var pruned int
+	if prune {
+		if pruned, err = project.PruneStale(projectRoot, selected.Path, cfg, dry); err != nil {
+			return err
+		}
+	}
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: selected.Path, Branch: selected.Branch}
+	runPostApplyHooks(ctx, cfg, target, result, dry)
+
+	logCopyStrategies(result)
+	ui.Success(fmt.Sprintf("Applied shared files to: %s (%d copied, %d symlinked%s)",
+		selected.Branch, result.Copied, result.Symlinked, prunedSuffix(prune, pruned)))
+	return nil
+}
+
+// logCopyStrategies reports how the copied files were copied, e.g.
+// "1204 reflink, 3 copy", so a slow filesystem is easy to spot.
+func logCopyStrategies(result project.ApplyResult) {
+	if len(result.Strategies) > 0 {
+		ui.Info("Copied with: " + project.FormatStrategies(result.Strategies))
+	}
+}
+
+// prunedSuffix adds the pruned count to the apply summary when --prune is set.
+func prunedSuffix(prune bool, n int) string {
+	if !prune {
+		return ""
+	}
+	return fmt.Sprintf(", %d pruned", n)
+}
+
+// reportDrift lists the copied files in each worktree that differ from
+// shared/copy, printing diffs to stdout when showDiff is set. With check, any
+// drift is returned as an error so the command exits non-zero.
+func reportDrift(projectRoot string, cfg *config.Config, worktrees []git.WorktreeInfo, check, showDiff bool) error {
+	var drifted int
+	secrets := project.NewSecrets(projectRoot, cfg)
+	for _, wt := range worktrees {
+		vars := project.NewTemplateVars(projectRoot, wt.Path, wt.Branch)
+		vars.Secrets = secrets
+		drifts, err := project.CheckDrift(projectRoot, wt.Path, cfg, &vars)
+		if err != nil {
+			return fmt.Errorf("%s: %w", wt.Branch, err)
+		}
+		if len(drifts) == 0 {
+			ui.Success(wt.Branch + ": shared files in sync")
+			continue
+		}
+
+		drifted++
+		ui.Warning(fmt.Sprintf("%s: %d shared file(s) differ", wt.Branch, len(drifts)))
+		for _, d := range drifts {
+			fmt.Fprintf(ui.Output, "  %-8s  %s\n", d.Status, d.Rel)
+		}
+		if showDiff {
+			for _, d := range drifts {
+				fmt.Print(d.Diff(projectRoot, wt.Path))
+			}
+		}
+	}
 
-	ui.Success(fmt.Sprintf("Applied shared files to: %s (%d copied, %d symlinked)",
-		selected.Branch, result.Copied, result.Symlinked))
+	if check && drifted > 0 {
+		return fmt.Errorf("shared files differ in %d worktree(s); run 'wt apply' to restore them", drifted)
+	}
+	return nil
+}
+
+// explainFile prints, for each worktree, the shared layers that offer rel
+// and which of them apply uses.
+func explainFile(projectRoot string, cfg *config.Config, worktrees []git.WorktreeInfo, rel string) error {
+	sharedDir := project.SharedPath(projectRoot, cfg)
+	for _, wt := range worktrees {
+		ex, err := project.Explain(projectRoot, cfg, project.NewTemplateVars(projectRoot, wt.Path, wt.Branch), rel)
+		if err != nil {
+			return fmt.Errorf("%s: %w", wt.Branch, err)
+		}
+
+		switch {
+		case len(ex.Sources) == 0:
+			ui.Info(fmt.Sprintf("%s: %s is not a shared file", wt.Branch, ex.Rel))
+			continue
+		case ex.ExcludedBy != "":
+			ui.Warning(fmt.Sprintf("%s: %s is excluded by overlay %s", wt.Branch, ex.Rel, ex.ExcludedBy))
+		default:
+			ui.Step(fmt.Sprintf("%s: %s", wt.Branch, ex.Rel))
+		}
+		for i, src := range ex.Sources {
+			status := "overridden"
+			switch {
+			case i == ex.Applied:
+				status = "applied"
+			case ex.Applied < 0:
+				status = "excluded"
+			}
+			source, err := filepath.Rel(sharedDir, src.Path)
+			if err != nil {
+				source = src.Path
+			}
+			layer := src.Layer.String()
+			if src.Rule >= 0 {
+				layer += fmt.Sprintf(", rule %d", src.Rule+1)
+			}
+			fmt.Fprintf(ui.Output, "  %-10s  %-8s  %s  [%s]\n", status, src.Mode, source, layer)
+		}
+	}
+	return nil
+}
+
+// runPostApplyHooks runs post_apply hooks when shared files were written to
+// the target worktree. Failures are reported but do not fail the command.
+func runPostApplyHooks(ctx context.Context, cfg *config.Config, target project.HookTarget, result project.ApplyResult, dry bool) {
+	if result.Copied+result.Symlinked == 0 {
+		return
+	}
+	if err := project.RunHooks(ctx, cfg, project.HookPostApply, target, dry); err != nil {
+		ui.Warning("Post-apply hooks failed: " + err.Error())
+	}
+}
+
+// watchShared propagates shared file changes to targets until interrupted.
+func watchShared(ctx context.Context, projectRoot string, cfg *config.Config, targets []git.WorktreeInfo, dry bool) error {
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+
+	hookTargets := make([]project.HookTarget, len(targets))
+	for i, wt := range targets {
+		hookTargets[i] = project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+	}
+
+	ui.Step(fmt.Sprintf("Watching %s for changes (%d worktree(s)); press Ctrl-C to stop", cfg.SharedDir, len(targets)))
+	if err := project.Watch(ctx, projectRoot, cfg, hookTargets, project.WatchDebounce, dry); err != nil {
+		return err
+	}
+	ui.Info("Stopped watching")
 	return nil
 }
@@ -168,6 +168,15 @@ func runClaudeHookWorktreeCreate(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	if err := checkDiskFloor(ctx, runner, projectRoot, cfg, branch); err != nil {
+		return err
+	}
+
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: worktreePath, Branch: branch}
+	if err := project.RunHooks(ctx, cfg, project.HookPreAdd, target, false); err != nil {
+		return fmt.Errorf("worktree not created: %w", err)
+	}
+
 	hasRemote, err := runner.HasRemoteBranch(ctx, branch)
 	if err != nil {
 		return fmt.Errorf("branch check failed: %w", err)
@@ -190,15 +199,16 @@ func runClaudeHookWorktreeCreate(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("apply shared files failed: %w", err)
 	}
+	runPostApplyHooks(ctx, cfg, target, result, false)
 
 	msg := fmt.Sprintf("Worktree created: %s/%s (%d copied, %d symlinked)",
 		cfg.WorktreeDir, branch, result.Copied, result.Symlinked)
 
 	// Launch setup hooks in background if configured.
 	// runSetupBackground prints the worktree path to stdout on its own.
-	hasHooks := len(cfg.Setup) > 0 || len(cfg.ParallelSetup) > 0
+	hasHooks := cfg.HasSetup()
 	if hasHooks {
-		if err := runSetupBackground(projectRoot, worktreePath, cfg, false, msg); err != nil {
+		if err := runSetupBackground(projectRoot, worktreePath, branch, cfg, false, msg); err != nil {
 			// Setup hook failure is non-fatal — the worktree is still usable.
 			ui.Warning("Background setup failed to start: " + err.Error())
 			fmt.Println(worktreePath)
@@ -231,16 +241,24 @@ func runClaudeHookWorktreeRemove(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("cannot determine branch from worktree path: %w", err)
 	}
 
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: worktreePath, Branch: branch}
+	if err := project.RunHooks(ctx, cfg, project.HookPreRemove, target, false); err != nil {
+		return fmt.Errorf("worktree not removed: %w", err)
+	}
+
 	// Terminate any in-progress background setup.
 	terminateBackgroundSetup(worktreePath, branch, false)
 
 	// Run teardown hooks.
-	if err := project.RunTeardownHooks(ctx, cfg, worktreePath, false); err != nil {
+	if err := project.RunTeardownHooks(ctx, cfg, target, false); err != nil {
 		ui.Warning("Teardown hooks failed: " + err.Error())
 	}
-	if err := project.RunParallelTeardownHooks(ctx, cfg, worktreePath, false); err != nil {
+	if err := project.RunParallelTeardownHooks(ctx, cfg, target, false); err != nil {
 		ui.Warning("Parallel teardown hooks failed: " + err.Error())
 	}
+	if err := project.ComposeDown(ctx, cfg, target, false); err != nil {
+		ui.Warning("Compose down failed: " + err.Error())
+	}
 
 	gitDir := project.GitDirPath(projectRoot, cfg)
 	runner := git.NewRunner(gitDir, false)
@@ -257,6 +275,11 @@ func runClaudeHookWorktreeRemove(cmd *cobra.Command, _ []string) error {
 	}
 
 	ui.Success("Removed worktree: " + branch)
+
+	if err := project.RunHooks(ctx, cfg, project.HookPostRemove, target, false); err != nil {
+		ui.Warning("Post-remove hooks failed: " + err.Error())
+	}
+	cleanWorktreeResources(ctx, projectRoot, cfg, worktreePath)
 	return nil
 }
 
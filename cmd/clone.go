@@ -77,7 +77,7 @@ func runClone(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create scaffold directories
-	cfg := config.DefaultConfig()
+	cfg := config.NewProjectConfig()
 	cfg.MainBranch = detectDefaultBranch(ctx, runner)
 	ui.Step("Creating project scaffold")
 	if err := project.CreateScaffold(projectRoot, &cfg, dry); err != nil {
@@ -23,6 +23,29 @@ func completeWorktreeNames(cmd *cobra.Command, args []string, toComplete string)
 	return names, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completeTaskArgs completes the task name for 'wt run', then the worktree.
+func completeTaskArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		projectRoot, err := project.FindRoot(cwd)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		cfg, err := config.Load(projectRoot)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return cfg.TaskNames(), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return completeWorktreeNames(cmd, nil, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
 func listWorktreeNames() ([]string, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -1,12 +1,25 @@
 package cmd
 
 import (
+	"cmp"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/disk"
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
 )
 
 // diskWarnEnvVar silences the low-disk warning for a single invocation.
@@ -26,7 +39,7 @@ func warnLowDisk(projectRoot string, cfg *config.Config) {
 
 	usage, err := disk.Stat(projectRoot)
 	if err != nil {
-		ui.Command("statfs " + projectRoot + ": " + err.Error())
+		ui.Warning("Could not check free disk space: " + err.Error())
 		return
 	}
 
@@ -55,10 +68,327 @@ func lowDiskMessages(usage disk.Usage, cfg *config.Config) []string {
 
 	msgs = append(msgs, "Run 'wt prune' to remove worktrees for merged branches.")
 
-	if len(cfg.Teardown) == 0 && len(cfg.ParallelTeardown) == 0 {
+	if len(cfg.Teardown) == 0 && len(cfg.ParallelTeardown) == 0 && len(cfg.ResourceCleanup) == 0 {
 		msgs = append(msgs, "No teardown hooks are configured, so 'wt prune' frees worktree "+
 			"directories but not docker volumes or other external resources.")
 	}
 
 	return msgs
 }
+
+// checkDiskFloor refuses a new worktree for branch when free space, less the
+// estimated size of the worktree, would drop below disk_min_free_gb or
+// disk_min_free_percent. Like warnLowDisk, a failed statfs lets it through.
+func checkDiskFloor(ctx context.Context, runner *git.Runner, projectRoot string, cfg *config.Config, branch string) error {
+	floor := cfg.DiskFloor()
+	if floor == nil {
+		return nil
+	}
+	usage, err := disk.Stat(projectRoot)
+	if err != nil {
+		ui.Warning("Could not check free disk space: " + err.Error())
+		return nil
+	}
+
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+	filtered := filterManagedWorktrees(worktrees, projectRoot)
+	sizes := measureWorktrees(filtered, func(wt git.WorktreeInfo) (*project.WorktreeUsage, error) {
+		u, err := project.WorktreeSize(wt.Path)
+		return &u, err
+	})
+	estimate := estimateWorktreeSize(sizes, project.SharedCopySize(projectRoot, cfg, branch))
+	if !floor.IsLow(usage.After(estimate)) {
+		return nil
+	}
+
+	// Only a refusal needs the git queries that find what 'wt prune' frees.
+	usages := measureWorktrees(filtered, func(wt git.WorktreeInfo) (*project.WorktreeUsage, error) {
+		return measureWorktree(ctx, runner, wt, cfg.MainBranchOrDefault())
+	})
+	return diskFloorError(usage, estimate, cfg, usages)
+}
+
+// estimateWorktreeSize guesses the size of a new worktree: the average of
+// the existing ones without what they copied from shared/, plus what apply
+// will copy into it.
+func estimateWorktreeSize(usages []project.WorktreeUsage, sharedCopy uint64) uint64 {
+	if len(usages) == 0 {
+		return sharedCopy
+	}
+	var sum uint64
+	for _, u := range usages {
+		sum += u.Total - min(u.Copied, u.Total)
+	}
+	return sum/uint64(len(usages)) + sharedCopy
+}
+
+// diskFloorError explains why the worktree wasn't created and names the
+// largest worktrees 'wt prune' would remove.
+func diskFloorError(usage disk.Usage, estimate uint64, cfg *config.Config, usages []project.WorktreeUsage) error {
+	var bounds []string
+	if cfg.DiskMinFreeGB > 0 {
+		bounds = append(bounds, fmt.Sprintf("disk_min_free_gb: %d", cfg.DiskMinFreeGB))
+	}
+	if cfg.DiskMinFreePercent > 0 {
+		bounds = append(bounds, fmt.Sprintf("disk_min_free_percent: %d", cfg.DiskMinFreePercent))
+	}
+	msg := fmt.Sprintf("not enough disk space for a new worktree: it needs about %s and %s is free of %s (%s)",
+		ui.FormatBytes(estimate), ui.FormatBytes(usage.FreeBytes), ui.FormatBytes(usage.TotalBytes), strings.Join(bounds, ", "))
+
+	var prunable []project.WorktreeUsage
+	for _, u := range usages {
+		if u.Merged && !u.Default {
+			prunable = append(prunable, u)
+		}
+	}
+	if len(prunable) == 0 {
+		return fmt.Errorf("%s\nNo worktrees are merged; run 'wt disk' to see where the space went", msg)
+	}
+	slices.SortFunc(prunable, func(a, b project.WorktreeUsage) int { return cmp.Compare(b.Reclaimable, a.Reclaimable) })
+
+	var names []string
+	for _, u := range prunable[:min(len(prunable), 3)] {
+		names = append(names, fmt.Sprintf("%s (%s)", u.Branch, ui.FormatBytes(u.Reclaimable)))
+	}
+	return fmt.Errorf("%s\nLargest prunable worktrees: %s; run 'wt prune' to remove them", msg, strings.Join(names, ", "))
+}
+
+func newDiskCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disk",
+		Short: "Show disk usage per worktree",
+		Long: `Show how much disk space each worktree takes, split into tracked files,
+ignored files (build output, dependencies), files copied from shared/, and
+the shared files it symlinks to. Blocks shared with other files through
+reflinks or hard links are reported separately, since removing the worktree
+doesn't free them.
+
+Worktrees are listed as removal candidates: merged and clean first, then by
+the space removing them would free.`,
+		Args: cobra.NoArgs,
+		RunE: runDisk,
+	}
+	cmd.Flags().Bool("json", false, "Print the report as JSON to stdout")
+	return cmd
+}
+
+// diskReport is the --json output of wt disk.
+type diskReport struct {
+	Project    string                  `json:"project"`
+	FreeBytes  uint64                  `json:"free_bytes"`
+	TotalBytes uint64                  `json:"total_bytes"`
+	Worktrees  []project.WorktreeUsage `json:"worktrees"`
+}
+
+func runDisk(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+	filtered := filterManagedWorktrees(worktrees, projectRoot)
+
+	asJSON, _ := cmd.Flags().GetBool("json")
+	if len(filtered) == 0 && !asJSON {
+		ui.Info("No worktrees found. Use 'wt add' to create one.")
+		return nil
+	}
+
+	usages := measureWorktrees(filtered, func(wt git.WorktreeInfo) (*project.WorktreeUsage, error) {
+		return measureWorktree(ctx, runner, wt, cfg.MainBranchOrDefault())
+	})
+	sortRemovalCandidates(usages)
+
+	usage, err := disk.Stat(projectRoot)
+	if err != nil {
+		ui.Warning("Could not check free disk space: " + err.Error())
+	}
+
+	if asJSON {
+		report := diskReport{
+			Project:    projectRoot,
+			FreeBytes:  usage.FreeBytes,
+			TotalBytes: usage.TotalBytes,
+			Worktrees:  usages,
+		}
+		if report.Worktrees == nil {
+			report.Worktrees = []project.WorktreeUsage{}
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	printDiskUsage(projectRoot, usages, usage)
+	return nil
+}
+
+// measureWorktrees runs measure on the worktrees concurrently, showing
+// progress on a terminal. Worktrees that can't be measured are reported and
+// left out.
+func measureWorktrees(worktrees []git.WorktreeInfo, measure func(git.WorktreeInfo) (*project.WorktreeUsage, error)) []project.WorktreeUsage {
+	results := make([]*project.WorktreeUsage, len(worktrees))
+	errs := make([]error, len(worktrees))
+
+	var (
+		wg   sync.WaitGroup
+		done atomic.Int32
+	)
+	sem := make(chan struct{}, min(runtime.NumCPU(), 8))
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i], errs[i] = measure(wt)
+			done.Add(1)
+		}()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	if ui.IsTerminal(ui.Output) {
+		showMeasureProgress(finished, &done, len(worktrees))
+	}
+	<-finished
+
+	var usages []project.WorktreeUsage
+	for i, u := range results {
+		if errs[i] != nil {
+			ui.Warning(fmt.Sprintf("%s: could not measure: %s", worktrees[i].Branch, errs[i]))
+			continue
+		}
+		usages = append(usages, *u)
+	}
+	return usages
+}
+
+// showMeasureProgress draws a spinner with the count of worktrees measured
+// until finished is closed.
+func showMeasureProgress(finished <-chan struct{}, done *atomic.Int32, total int) {
+	region := ui.NewLiveRegion(ui.Output, 1)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		spinner := ui.SpinnerFrames[frame%len(ui.SpinnerFrames)]
+		region.Set(0, ui.StyleInfo.Render(spinner+" Measuring worktrees")+"  "+
+			ui.StyleMuted.Render(fmt.Sprintf("%d/%d", done.Load(), total)))
+		region.Render()
+
+		select {
+		case <-finished:
+			region.Stop()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// measureWorktree sizes one worktree and fills in the status fields that
+// decide whether it is a removal candidate.
+func measureWorktree(ctx context.Context, runner *git.Runner, wt git.WorktreeInfo, defaultBranch string) (*project.WorktreeUsage, error) {
+	tracked, err := runner.TrackedFiles(ctx, wt.Path)
+	if err != nil {
+		return nil, err
+	}
+	u, err := project.MeasureWorktree(wt.Path, tracked)
+	if err != nil {
+		return nil, err
+	}
+	u.Branch = wt.Branch
+	u.Default = wt.Branch == defaultBranch
+	if dirty, err := runner.IsWorktreeDirty(ctx, wt.Path); err == nil {
+		u.Dirty = dirty
+	}
+	if !u.Default && wt.Branch != "" {
+		if merged, err := runner.IsBranchMerged(ctx, wt.Branch, defaultBranch); err == nil {
+			u.Merged = merged
+		}
+	}
+	return &u, nil
+}
+
+// sortRemovalCandidates orders worktrees by how safe and worthwhile they are
+// to remove: merged and clean first, the default branch last, and by
+// reclaimable space within each group.
+func sortRemovalCandidates(usages []project.WorktreeUsage) {
+	rank := func(u project.WorktreeUsage) int {
+		switch {
+		case u.Default:
+			return 2
+		case u.Merged && !u.Dirty:
+			return 0
+		}
+		return 1
+	}
+	slices.SortStableFunc(usages, func(a, b project.WorktreeUsage) int {
+		if c := cmp.Compare(rank(a), rank(b)); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Reclaimable, a.Reclaimable)
+	})
+}
+
+func printDiskUsage(projectRoot string, usages []project.WorktreeUsage, usage disk.Usage) {
+	ui.Heading("Disk usage")
+
+	t := ui.NewTable().Headers("BRANCH", "PATH", "TOTAL", "TRACKED", "IGNORED", "COPIED", "SHARED", "SYMLINKED", "STATUS")
+	var total, reclaimable uint64
+	var merged int
+	for _, u := range usages {
+		relPath, err := filepath.Rel(projectRoot, u.Path)
+		if err != nil {
+			relPath = u.Path
+		}
+		t.Row(u.Branch, relPath, ui.FormatBytes(u.Total), ui.FormatBytes(u.Tracked), ui.FormatBytes(u.Ignored),
+			ui.FormatBytes(u.Copied), ui.FormatBytes(u.Shared), ui.FormatBytes(u.Symlinked), usageStatus(u))
+		total += u.Total
+		reclaimable += u.Reclaimable
+		if u.Merged && !u.Dirty {
+			merged++
+		}
+	}
+	ui.PrintTable(t)
+
+	ui.Info(fmt.Sprintf("%d worktree(s) use %s, %s of it reclaimable", len(usages), ui.FormatBytes(total), ui.FormatBytes(reclaimable)))
+	if usage.TotalBytes > 0 {
+		ui.Info(fmt.Sprintf("%s free of %s (%.0f%% free)",
+			ui.FormatBytes(usage.FreeBytes), ui.FormatBytes(usage.TotalBytes), usage.PercentFree()))
+	}
+	if merged > 0 {
+		ui.Step(fmt.Sprintf("Run 'wt prune' to remove the %d worktree(s) for merged branches.", merged))
+	}
+}
+
+// usageStatus describes the worktree for the STATUS column.
+func usageStatus(u project.WorktreeUsage) string {
+	var parts []string
+	switch {
+	case u.Default:
+		parts = append(parts, "default")
+	case u.Merged:
+		parts = append(parts, "merged")
+	}
+	if u.Dirty {
+		parts = append(parts, "dirty")
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/disk"
+	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
 )
 
@@ -47,14 +49,14 @@ func TestLowDiskMessages(t *testing.T) {
 		{
 			name:        "low with teardown hooks omits the caveat",
 			usage:       low,
-			cfg:         config.Config{Teardown: []string{"docker compose down -v"}},
+			cfg:         config.Config{Teardown: []config.Hook{{Run: "docker compose down -v"}}},
 			wantLines:   2,
 			wantSummary: "2.0 GB free of 500 GB (0% free)",
 		},
 		{
 			name:       "low with parallel teardown hooks omits the caveat",
 			usage:      low,
-			cfg:        config.Config{ParallelTeardown: []string{"make clean"}},
+			cfg:        config.Config{ParallelTeardown: []config.Hook{{Run: "make clean"}}},
 			wantLines:  2,
 			wantCaveat: false,
 		},
@@ -121,3 +123,64 @@ func TestWarnLowDiskEnvOverride(t *testing.T) {
 		t.Errorf("expected no output with %s set, got %q", diskWarnEnvVar, buf.String())
 	}
 }
+
+func TestSortRemovalCandidates(t *testing.T) {
+	usages := []project.WorktreeUsage{
+		{Branch: "main", Default: true, Reclaimable: 900},
+		{Branch: "wip", Reclaimable: 500},
+		{Branch: "done-small", Merged: true, Reclaimable: 10},
+		{Branch: "done-dirty", Merged: true, Dirty: true, Reclaimable: 800},
+		{Branch: "done-big", Merged: true, Reclaimable: 300},
+	}
+	sortRemovalCandidates(usages)
+
+	var got []string
+	for _, u := range usages {
+		got = append(got, u.Branch)
+	}
+	want := []string{"done-big", "done-small", "done-dirty", "wip", "main"}
+	if !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+func TestEstimateWorktreeSize(t *testing.T) {
+	usages := []project.WorktreeUsage{
+		{Total: 1000, Copied: 100},
+		{Total: 500, Copied: 100},
+	}
+	// Average without copies (650) plus shared/copy (120).
+	if got := estimateWorktreeSize(usages, 120); got != 770 {
+		t.Errorf("estimateWorktreeSize = %d, want 770", got)
+	}
+	if got := estimateWorktreeSize(nil, 120); got != 120 {
+		t.Errorf("estimateWorktreeSize with no worktrees = %d, want 120", got)
+	}
+}
+
+func TestDiskFloorError(t *testing.T) {
+	const gb = disk.BytesPerGB
+	usage := disk.Usage{TotalBytes: 100 * gb, FreeBytes: 6 * gb}
+	cfg := &config.Config{DiskMinFreeGB: 5}
+
+	usages := []project.WorktreeUsage{
+		{Branch: "main", Default: true, Reclaimable: 9 * gb},
+		{Branch: "small", Merged: true, Reclaimable: 1 * gb},
+		{Branch: "wip", Reclaimable: 8 * gb},
+		{Branch: "big", Merged: true, Reclaimable: 4 * gb},
+	}
+	msg := diskFloorError(usage, 2*gb, cfg, usages).Error()
+	for _, want := range []string{"about 2.0 GB", "disk_min_free_gb: 5", "big (4.0 GB), small (1.0 GB)", "wt prune"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q missing %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "wip") || strings.Contains(msg, "main (") {
+		t.Errorf("error %q names a worktree that isn't prunable", msg)
+	}
+
+	msg = diskFloorError(usage, 2*gb, cfg, usages[:1]).Error()
+	if !strings.Contains(msg, "wt disk") {
+		t.Errorf("error %q should point at wt disk when nothing is merged", msg)
+	}
+}
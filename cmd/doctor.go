@@ -0,0 +1,536 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/claude"
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type doctorStatus int
+
+const (
+	doctorPass doctorStatus = iota
+	doctorWarn
+	doctorFail
+)
+
+// doctorResult is the outcome of one 'wt doctor' check. fix, when set,
+// repairs what the check found; --fix runs it.
+type doctorResult struct {
+	name   string
+	status doctorStatus
+	detail string
+	fix    func() error
+}
+
+// doctorEnv is what the checks share: the project, its worktrees, and the
+// local git version.
+type doctorEnv struct {
+	ctx        context.Context
+	root       string
+	cfg        *config.Config
+	gitDir     string
+	runner     *git.Runner
+	worktrees  []git.WorktreeInfo // every worktree git lists, bare entry included
+	managed    []git.WorktreeInfo // managed worktrees whose directory exists
+	version    [3]int
+	versionErr error
+	dry        bool
+}
+
+// doctorChecks run in this order, so fixing one check's problem can rely on
+// the fixes before it, as the core.bare override does on worktreeConfig.
+var doctorChecks = []func(*doctorEnv) doctorResult{
+	checkGitVersion,
+	checkWorktreeConfig,
+	checkBareOverride,
+	checkGitExclude,
+	checkMissingWorktrees,
+	checkUnknownDirs,
+	checkSharedSymlinks,
+	checkAbsolutePaths,
+	checkSetupStates,
+	checkClaudeHooks,
+}
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the project for problems, and fix them with --fix",
+		Long: `Checks the git version, extensions.worktreeConfig and the core.bare
+override, the wt entries in info/exclude, worktrees git lists but whose
+directory is gone, directories under the worktrees dir git doesn't know,
+dangling shared symlinks, absolute paths that break when the project moves,
+setup states left "running" by a process that died, and the Claude Code
+hooks. Each check passes, warns, or fails; wt doctor exits non-zero when one
+fails. --fix repairs what can be repaired safely; directories unknown to git
+are only reported.`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+	cmd.Flags().Bool("fix", false, "Fix the problems found where possible")
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dry := IsDryRun()
+
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+	gitDir := project.GitDirPath(projectRoot, cfg)
+	runner := git.NewRunner(gitDir, dry)
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+
+	env := &doctorEnv{ctx: ctx, root: projectRoot, cfg: cfg, gitDir: gitDir, runner: runner, worktrees: worktrees, dry: dry}
+	env.version, env.versionErr = runner.Version(ctx)
+	for _, wt := range filterManagedWorktrees(worktrees, projectRoot) {
+		if _, err := os.Stat(wt.Path); err == nil {
+			env.managed = append(env.managed, wt)
+		}
+	}
+
+	fix, _ := cmd.Flags().GetBool("fix")
+	var warned, failed, fixable int
+	for _, check := range doctorChecks {
+		r := check(env)
+		reportDoctorResult(r)
+		if r.status == doctorPass {
+			continue
+		}
+		if r.fix != nil && fix {
+			if err := r.fix(); err != nil {
+				ui.Error("Fix failed: " + err.Error())
+			} else if !dry {
+				ui.Info("  fixed")
+				continue
+			}
+		} else if r.fix != nil {
+			fixable++
+		}
+		if r.status == doctorFail {
+			failed++
+		} else {
+			warned++
+		}
+	}
+
+	if warned == 0 && failed == 0 {
+		ui.Success("No problems found")
+		return nil
+	}
+	ui.Info(fmt.Sprintf("%d failed, %d warning(s)", failed, warned))
+	if fixable > 0 {
+		ui.Step(fmt.Sprintf("Run 'wt doctor --fix' to fix %d of them.", fixable))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func reportDoctorResult(r doctorResult) {
+	msg := r.name + ": " + r.detail
+	switch r.status {
+	case doctorPass:
+		ui.Success(msg)
+	case doctorWarn:
+		ui.Warning(msg)
+	default:
+		ui.Error(msg)
+	}
+}
+
+// bareOverrideStatus is how serious a missing worktreeConfig or core.bare
+// override is: git 2.52+ refuses to work in such a worktree.
+func (e *doctorEnv) bareOverrideStatus() doctorStatus {
+	if e.versionErr == nil && !git.RequiresBareOverride(e.version) {
+		return doctorWarn
+	}
+	return doctorFail
+}
+
+func checkGitVersion(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "git version"}
+	if e.versionErr != nil {
+		r.status, r.detail = doctorFail, e.versionErr.Error()
+		return r
+	}
+	r.detail = fmt.Sprintf("%d.%d.%d", e.version[0], e.version[1], e.version[2])
+	switch {
+	case !git.SupportsWorktreeConfig(e.version):
+		r.status = doctorFail
+		r.detail += "; wt needs git 2.20 or later for extensions.worktreeConfig"
+	case !git.SupportsRelativePaths(e.version):
+		r.status = doctorWarn
+		r.detail += "; git 2.48 or later links worktrees by relative paths, which survive moving the project"
+	}
+	return r
+}
+
+func checkWorktreeConfig(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "extensions.worktreeConfig"}
+	enabled, err := e.runner.WorktreeConfigEnabled(e.ctx)
+	switch {
+	case err != nil:
+		r.status, r.detail = doctorFail, err.Error()
+	case enabled:
+		r.detail = "enabled"
+	default:
+		r.status = e.bareOverrideStatus()
+		r.detail = "not enabled on " + e.cfg.GitDir + "; worktrees can't override core.bare"
+		r.fix = func() error { return e.runner.EnableWorktreeConfig(e.ctx) }
+	}
+	return r
+}
+
+func checkBareOverride(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "core.bare override"}
+	var missing []string
+	var inspected int
+	for _, wt := range e.worktrees {
+		if wt.Bare {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); err != nil {
+			continue // reported by checkMissingWorktrees
+		}
+		inspected++
+		if ok, err := worktreeBareOverrideOK(wt.Path); err != nil || !ok {
+			missing = append(missing, wt.Path)
+		}
+	}
+	if len(missing) == 0 {
+		r.detail = fmt.Sprintf("set in %d worktree(s)", inspected)
+		return r
+	}
+
+	r.status = e.bareOverrideStatus()
+	r.detail = "core.bare=false missing in " + e.displayPaths(missing)
+	r.fix = func() error {
+		if err := e.runner.EnableWorktreeConfig(e.ctx); err != nil {
+			return err
+		}
+		for _, path := range missing {
+			if err := e.runner.SetWorktreeBareFalse(e.ctx, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return r
+}
+
+func checkGitExclude(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "info/exclude"}
+	missing, err := project.MissingGitExcludes(e.gitDir)
+	switch {
+	case err != nil:
+		r.status, r.detail = doctorWarn, err.Error()
+	case len(missing) > 0:
+		r.status = doctorWarn
+		r.detail = "missing " + strings.Join(missing, ", ") + "; wt's files show up as untracked"
+		r.fix = func() error { return project.EnsureGitExclude(e.gitDir, e.dry) }
+	default:
+		r.detail = "lists wt's files"
+	}
+	return r
+}
+
+func checkMissingWorktrees(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "worktree directories"}
+	var missing []string
+	for _, wt := range e.worktrees {
+		if wt.Bare {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); errors.Is(err, fs.ErrNotExist) {
+			missing = append(missing, wt.Path)
+		}
+	}
+	if len(missing) == 0 {
+		r.detail = "all present"
+		return r
+	}
+	r.status = doctorWarn
+	r.detail = "git lists worktrees whose directory is gone: " + e.displayPaths(missing)
+	r.fix = func() error { return e.runner.WorktreePrune(e.ctx) }
+	return r
+}
+
+func checkUnknownDirs(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "unknown directories"}
+	known := make([]string, 0, len(e.worktrees))
+	for _, wt := range e.worktrees {
+		known = append(known, wt.Path)
+	}
+	unknown, err := project.UnknownWorktreeDirs(e.root, e.cfg, known)
+	switch {
+	case err != nil:
+		r.status, r.detail = doctorWarn, "not checked: "+err.Error()
+	case len(unknown) > 0:
+		r.status = doctorWarn
+		r.detail = "not git worktrees: " + e.displayPaths(unknown) + "; see 'wt orphans'"
+	default:
+		r.detail = "none under " + e.cfg.WorktreeDir
+	}
+	return r
+}
+
+func checkSharedSymlinks(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "shared symlinks"}
+	var found []string
+	var broken []git.WorktreeInfo
+	for _, wt := range e.managed {
+		dangling, err := project.DanglingSymlinks(wt.Path)
+		if err != nil {
+			r.status, r.detail = doctorWarn, fmt.Sprintf("%s: %s", wt.Branch, err)
+			return r
+		}
+		for _, d := range dangling {
+			found = append(found, wt.Branch+": "+d.Path)
+		}
+		if len(dangling) > 0 {
+			broken = append(broken, wt)
+		}
+	}
+	if len(found) == 0 {
+		r.detail = "none dangling"
+		return r
+	}
+
+	r.status = doctorWarn
+	r.detail = fmt.Sprintf("%d dangling: %s", len(found), strings.Join(found, ", "))
+	r.fix = func() error {
+		for _, wt := range broken {
+			if _, err := project.FixDanglingSymlinks(e.root, wt.Path, e.cfg, wt.Branch, e.dry); err != nil {
+				return fmt.Errorf("%s: %w", wt.Branch, err)
+			}
+		}
+		return nil
+	}
+	return r
+}
+
+func checkAbsolutePaths(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "absolute paths"}
+
+	gitLinks := absoluteGitLinks(e)
+	var symlinks int
+	var linked []git.WorktreeInfo
+	for _, wt := range e.managed {
+		abs, err := project.AbsoluteSymlinks(e.root, wt.Path, e.cfg, wt.Branch)
+		if err != nil {
+			continue // reported by checkSharedSymlinks
+		}
+		symlinks += len(abs)
+		if len(abs) > 0 {
+			linked = append(linked, wt)
+		}
+	}
+	if len(gitLinks) == 0 && symlinks == 0 {
+		r.detail = "none; the project can be moved"
+		return r
+	}
+
+	r.status = doctorWarn
+	var parts []string
+	var fixes []func() error
+	if len(gitLinks) > 0 {
+		part := fmt.Sprintf("%d git worktree link(s)", len(gitLinks))
+		if e.versionErr == nil && git.SupportsRelativePaths(e.version) {
+			fixes = append(fixes, func() error { return e.runner.WorktreeRepair(e.ctx, true) })
+		} else {
+			part += " (fixing them needs git 2.48+)"
+		}
+		parts = append(parts, part)
+	}
+	if symlinks > 0 {
+		part := fmt.Sprintf("%d shared symlink(s)", symlinks)
+		if e.cfg.RelativeSymlinks() {
+			fixes = append(fixes, func() error {
+				for _, wt := range linked {
+					if _, err := project.RelinkSymlinks(e.root, wt.Path, e.cfg, wt.Branch, e.dry); err != nil {
+						return fmt.Errorf("%s: %w", wt.Branch, err)
+					}
+				}
+				return nil
+			})
+		} else {
+			part += " (set symlink_style: relative to fix them)"
+		}
+		parts = append(parts, part)
+	}
+	r.detail = strings.Join(parts, " and ") + " break if the project moves"
+	r.fix = allFixes(fixes)
+	return r
+}
+
+// absoluteGitLinks returns the links between the common dir and its linked
+// worktrees that hold absolute paths: each worktree's .git file, and the
+// gitdir file the common dir keeps for it.
+func absoluteGitLinks(e *doctorEnv) []string {
+	var links []string
+	for _, wt := range e.managed {
+		if gitdir, err := readGitLink(wt.Path); err == nil && filepath.IsAbs(gitdir) {
+			links = append(links, filepath.Join(wt.Path, ".git"))
+		}
+	}
+	files, _ := filepath.Glob(filepath.Join(e.gitDir, "worktrees", "*", "gitdir"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err == nil && filepath.IsAbs(strings.TrimSpace(string(data))) {
+			links = append(links, f)
+		}
+	}
+	return links
+}
+
+func checkSetupStates(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "setup state"}
+	var stale []git.WorktreeInfo
+	var names []string
+	for _, wt := range e.managed {
+		state, err := project.ReadSetupState(wt.Path)
+		if err != nil || state == nil {
+			continue
+		}
+		if state.Status == project.SetupRunning && !project.IsProcessAlive(state.PID) {
+			stale = append(stale, wt)
+			names = append(names, fmt.Sprintf("%s (PID %d)", wt.Branch, state.PID))
+		}
+	}
+	if len(stale) == 0 {
+		r.detail = "no stale runs"
+		return r
+	}
+
+	r.status = doctorWarn
+	r.detail = "recorded as running, but the process is gone: " + strings.Join(names, ", ")
+	r.fix = func() error {
+		for _, wt := range stale {
+			if e.dry {
+				ui.DryRunNotice("mark setup failed for " + wt.Branch)
+				continue
+			}
+			if _, err := project.ReconcileSetupState(wt.Path); err != nil {
+				return fmt.Errorf("%s: %w", wt.Branch, err)
+			}
+		}
+		return nil
+	}
+	return r
+}
+
+func checkClaudeHooks(e *doctorEnv) doctorResult {
+	r := doctorResult{name: "Claude Code hooks"}
+	sharedTarget := filepath.Join(project.SharedPath(e.root, e.cfg), "symlink")
+	commands, err := claude.HookCommands(sharedTarget)
+	if err != nil {
+		r.status, r.detail = doctorFail, "unreadable settings: "+err.Error()
+		return r
+	}
+	if len(commands) == 0 {
+		r.detail = "not configured"
+		return r
+	}
+
+	var problems []string
+	var fixes []func() error
+	var binary string
+	for _, event := range []string{claude.HookWorktreeCreate, claude.HookWorktreeRemove} {
+		if command, ok := commands[event]; ok && binary == "" {
+			binary = claude.HookBinary(command)
+		}
+	}
+	_, lookErr := exec.LookPath(binary)
+	if lookErr != nil {
+		r.status = doctorFail
+		problems = append(problems, fmt.Sprintf("%s not found; rerun 'wt claude init --binary <path>'", binary))
+	}
+	for _, event := range []string{claude.HookWorktreeCreate, claude.HookWorktreeRemove} {
+		if _, ok := commands[event]; !ok {
+			r.status = max(r.status, doctorWarn)
+			problems = append(problems, event+" hook missing")
+			// Writing the hook with a binary that isn't there fixes nothing.
+			if lookErr == nil {
+				fixes = append(fixes, func() error { return claude.ConfigureHooks(sharedTarget, binary) })
+			}
+			break
+		}
+	}
+	var unapplied []git.WorktreeInfo
+	for _, wt := range e.managed {
+		if _, err := os.Stat(filepath.Join(wt.Path, ".claude", "settings.local.json")); err != nil {
+			unapplied = append(unapplied, wt)
+		}
+	}
+	if len(unapplied) > 0 {
+		r.status = max(r.status, doctorWarn)
+		names := make([]string, len(unapplied))
+		for i, wt := range unapplied {
+			names[i] = wt.Branch
+		}
+		problems = append(problems, "not applied to "+strings.Join(names, ", "))
+		fixes = append(fixes, func() error {
+			for _, wt := range unapplied {
+				vars := project.NewTemplateVars(e.root, wt.Path, wt.Branch)
+				if _, err := project.Apply(e.root, wt.Path, e.cfg, e.dry, &vars); err != nil {
+					return fmt.Errorf("%s: %w", wt.Branch, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	if len(problems) == 0 {
+		r.detail = "configured in all worktrees"
+		return r
+	}
+	r.detail = strings.Join(problems, "; ")
+	r.fix = allFixes(fixes)
+	return r
+}
+
+// allFixes runs fixes in order, stopping at the first that fails. It
+// returns nil when there are none, so the check has no fix to offer.
+func allFixes(fixes []func() error) func() error {
+	if len(fixes) == 0 {
+		return nil
+	}
+	return func() error {
+		for _, fix := range fixes {
+			if err := fix(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// displayPaths joins paths for a check's detail, relative to the project
+// root where they are inside it.
+func (e *doctorEnv) displayPaths(paths []string) string {
+	shown := make([]string, len(paths))
+	for i, p := range paths {
+		shown[i] = displayPath(e.root, p)
+	}
+	return strings.Join(shown, ", ")
+}
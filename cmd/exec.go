@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec [--all | --filter <glob> | --dirty | --merged] -- <command> [args...]",
+		Short: "Run a command in several worktrees",
+		Long: "Runs a command in each matching worktree, prefixing its output with the branch, and summarizes the exit codes.\n" +
+			"Selectors can be combined; a worktree must match all of them. The command runs directly, not through a shell.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: runExec,
+	}
+	// Stop parsing flags at the command so its own flags pass through.
+	cmd.Flags().SetInterspersed(false)
+	cmd.Flags().Bool("all", false, "Run in every worktree")
+	cmd.Flags().String("filter", "", "Run in worktrees whose branch matches a glob, e.g. 'feature/*'")
+	cmd.Flags().Bool("dirty", false, "Run in worktrees with uncommitted changes")
+	cmd.Flags().Bool("merged", false, "Run in worktrees whose branch is merged into the main branch")
+	cmd.Flags().IntP("jobs", "j", 1, "Number of worktrees to run in at once")
+	return cmd
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	all, _ := cmd.Flags().GetBool("all")
+	filter, _ := cmd.Flags().GetString("filter")
+	dirty, _ := cmd.Flags().GetBool("dirty")
+	merged, _ := cmd.Flags().GetBool("merged")
+	jobs, _ := cmd.Flags().GetInt("jobs")
+
+	if !all && filter == "" && !dirty && !merged {
+		return fmt.Errorf("choose worktrees with --all, --filter, --dirty, or --merged")
+	}
+	if jobs < 1 {
+		return fmt.Errorf("--jobs must be at least 1")
+	}
+	if _, err := path.Match(filter, ""); err != nil {
+		return fmt.Errorf("invalid --filter pattern %q: %w", filter, err)
+	}
+
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+
+	mainBranch := cfg.MainBranchOrDefault()
+	var targets []project.HookTarget
+	for _, wt := range filterManagedWorktrees(worktrees, projectRoot) {
+		if filter != "" {
+			if ok, _ := path.Match(filter, wt.Branch); !ok {
+				continue
+			}
+		}
+		if dirty {
+			isDirty, err := runner.IsWorktreeDirty(ctx, wt.Path)
+			if err != nil {
+				ui.Warning(fmt.Sprintf("%s: could not check status: %s", wt.Branch, err))
+				continue
+			}
+			if !isDirty {
+				continue
+			}
+		}
+		if merged {
+			if wt.Branch == mainBranch {
+				continue
+			}
+			isMerged, err := runner.IsBranchMerged(ctx, wt.Branch, mainBranch)
+			if err != nil {
+				ui.Warning(fmt.Sprintf("%s: could not check merge status: %s", wt.Branch, err))
+				continue
+			}
+			if !isMerged {
+				continue
+			}
+		}
+		targets = append(targets, project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch})
+	}
+
+	if len(targets) == 0 {
+		ui.Info("No matching worktrees.")
+		return nil
+	}
+
+	results := project.ExecInWorktrees(ctx, cfg, targets, args, jobs, IsDryRun())
+	if IsDryRun() {
+		return nil
+	}
+
+	var failCount int
+	for _, r := range results {
+		summary := fmt.Sprintf("%s: %s (%s)", r.Branch, r.Describe(), ui.FormatDuration(r.Elapsed))
+		if r.Err != nil {
+			ui.Error(summary)
+			failCount++
+		} else {
+			ui.Success(summary)
+		}
+	}
+
+	if failCount > 0 {
+		return fmt.Errorf("command failed in %d of %d worktree(s)", failCount, len(results))
+	}
+	return nil
+}
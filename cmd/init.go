@@ -45,7 +45,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("already a wt project (%s exists)", config.ConfigFileName)
 	}
 
-	cfg := config.DefaultConfig()
+	cfg := config.NewProjectConfig()
 	cfg.GitDir = ".git"
 	cfg.WorktreeDir = ".worktrees"
 	cfg.SharedDir = ".worktrees/shared"
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/spf13/cobra"
+)
+
+func newOnEnterCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "_on-enter",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE:   runOnEnter,
+	}
+}
+
+// runOnEnter runs the on_enter hooks for the worktree containing the current
+// directory. The shell wrapper calls it after 'wt cd' changes directory, so
+// it stays silent outside a wt project or a managed worktree.
+func runOnEnter(cmd *cobra.Command, _ []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	projectRoot, err := project.FindRoot(cwd)
+	if err != nil {
+		return nil
+	}
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return err
+	}
+	if !project.HasHooks(cfg, project.HookOnEnter) {
+		return nil
+	}
+
+	ctx := cmd.Context()
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+	wt, ok := resolveCurrentWorktree(filterManagedWorktrees(worktrees, projectRoot))
+	if !ok {
+		return nil
+	}
+
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+	return project.RunHooks(ctx, cfg, project.HookOnEnter, target, IsDryRun())
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newAdoptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adopt <path>",
+		Short: "Move a worktree created outside the worktrees directory into it",
+		Long: `Move a worktree of this repository that was created elsewhere, with plain
+'git worktree add', to <worktree_dir>/<branch> where wt manages it, and apply
+the shared files. Files the worktree already has are kept unless
+apply_conflict or --conflict says otherwise. An existing worktree is usually
+set up already, so setup hooks only run with --setup.`,
+		Example: `  wt adopt ../myapp-hotfix
+  wt adopt ~/scratch/feature-x --setup`,
+		Args: cobra.ExactArgs(1),
+		RunE: runAdopt,
+	}
+	cmd.Flags().Bool("setup", false, "Run setup hooks after adopting")
+	cmd.Flags().Bool("background", false, "Run setup hooks in the background")
+	cmd.Flags().Bool("foreground", false, "Run setup hooks in the foreground (blocking)")
+	cmd.Flags().String("conflict", "", "How to handle copied files the worktree already has: overwrite, skip-modified, prompt, or backup (default skip-modified)")
+	return cmd
+}
+
+func newOrphansCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "orphans",
+		Short: "List worktrees outside the worktrees directory and directories in it git doesn't know",
+		Long: `List the two kinds of strays wt doesn't manage: worktrees of this repository
+outside the worktrees directory, which 'wt adopt' moves into it, and
+directories under the worktrees directory that aren't git worktrees, such as
+ones whose git metadata was pruned, which --clean deletes.`,
+		Args: cobra.NoArgs,
+		RunE: runOrphans,
+	}
+	cmd.Flags().Bool("clean", false, "Delete the directories under the worktrees directory that aren't git worktrees")
+	cmd.Flags().Bool("force", false, "Skip the confirmation prompt for --clean")
+	return cmd
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dry := IsDryRun()
+
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	gitDir := project.GitDirPath(projectRoot, cfg)
+	runner := git.NewRunner(gitDir, dry)
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+	wt, err := findExternalWorktree(worktrees, projectRoot, cfg, args[0])
+	if err != nil {
+		return err
+	}
+	if wt.Branch == "" {
+		return fmt.Errorf("%s has a detached HEAD; check out a branch before adopting it", wt.Path)
+	}
+
+	worktreePath := filepath.Join(project.WorktreesPath(projectRoot, cfg), wt.Branch)
+	if _, err := os.Stat(worktreePath); err == nil {
+		return fmt.Errorf("worktree already exists: %s/%s", cfg.WorktreeDir, wt.Branch)
+	}
+
+	if cmd.Flags().Changed("conflict") {
+		value, _ := cmd.Flags().GetString("conflict")
+		policy, err := config.ParseConflictPolicy(value)
+		if err != nil {
+			return err
+		}
+		cfg.ApplyConflict = policy
+	} else if cfg.ApplyConflict == "" {
+		// Unlike a new worktree, this one has files of its own.
+		cfg.ApplyConflict = config.ConflictSkipModified
+	}
+
+	if err := project.EnsureGitExclude(gitDir, dry); err != nil {
+		ui.Warning("Could not configure git excludes: " + err.Error())
+	}
+
+	ui.Step(fmt.Sprintf("Moving worktree: %s → %s/%s", wt.Path, cfg.WorktreeDir, wt.Branch))
+	if !dry {
+		if err := os.MkdirAll(filepath.Dir(worktreePath), 0o755); err != nil {
+			return err
+		}
+	}
+	if err := runner.WorktreeMove(ctx, wt.Path, worktreePath); err != nil {
+		return err
+	}
+	if err := project.MoveResources(projectRoot, cfg, wt.Path, worktreePath, dry); err != nil {
+		ui.Warning("Could not update resources: " + err.Error())
+	}
+
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: worktreePath, Branch: wt.Branch}
+	vars := project.NewTemplateVars(projectRoot, worktreePath, wt.Branch)
+	result, err := project.Apply(projectRoot, worktreePath, cfg, dry, &vars)
+	if err != nil {
+		return err
+	}
+	runPostApplyHooks(ctx, cfg, target, result, dry)
+
+	msg := fmt.Sprintf("Worktree adopted: %s/%s (%d copied, %d symlinked)",
+		cfg.WorktreeDir, wt.Branch, result.Copied, result.Symlinked)
+
+	if setup, _ := cmd.Flags().GetBool("setup"); !setup || !cfg.HasSetup() {
+		ui.Success(msg)
+		fmt.Println(worktreePath)
+		return nil
+	}
+
+	background, err := resolveBackgroundMode(cmd, cfg)
+	if err != nil {
+		return err
+	}
+	if background {
+		return runSetupBackground(projectRoot, worktreePath, wt.Branch, cfg, dry, msg)
+	}
+	return runSetupForeground(cmd, projectRoot, worktreePath, wt.Branch, cfg, dry, msg)
+}
+
+// findExternalWorktree returns the worktree at path, which must be one of
+// the repository's worktrees outside the worktrees directory.
+func findExternalWorktree(worktrees []git.WorktreeInfo, projectRoot string, cfg *config.Config, path string) (git.WorktreeInfo, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return git.WorktreeInfo{}, err
+	}
+	want := project.ResolvePath(abs)
+	for _, wt := range externalWorktrees(worktrees, projectRoot, cfg) {
+		if project.ResolvePath(wt.Path) == want {
+			return wt, nil
+		}
+	}
+	for _, wt := range filterManagedWorktrees(worktrees, projectRoot) {
+		if project.ResolvePath(wt.Path) == want {
+			return git.WorktreeInfo{}, fmt.Errorf("%s is already in %s", path, cfg.WorktreeDir)
+		}
+	}
+	return git.WorktreeInfo{}, fmt.Errorf("%s is not a worktree of this project's repository (see 'wt orphans')", path)
+}
+
+// externalWorktrees returns the repository's worktrees outside the
+// worktrees directory: ones created with plain 'git worktree add' rather
+// than 'wt add'. Worktrees whose directory is gone are left to 'wt doctor'.
+func externalWorktrees(worktrees []git.WorktreeInfo, projectRoot string, cfg *config.Config) []git.WorktreeInfo {
+	worktreesDir := project.ResolvePath(project.WorktreesPath(projectRoot, cfg)) + string(filepath.Separator)
+	var external []git.WorktreeInfo
+	for _, wt := range filterManagedWorktrees(worktrees, projectRoot) {
+		if strings.HasPrefix(project.ResolvePath(wt.Path), worktreesDir) {
+			continue
+		}
+		if _, err := os.Stat(wt.Path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		external = append(external, wt)
+	}
+	return external
+}
+
+func runOrphans(cmd *cobra.Command, args []string) error {
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+	worktrees, err := runner.WorktreeList(cmd.Context())
+	if err != nil {
+		return err
+	}
+	known := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		known[i] = wt.Path
+	}
+	external := externalWorktrees(worktrees, projectRoot, cfg)
+	unknown, err := project.UnknownWorktreeDirs(projectRoot, cfg, known)
+	if err != nil {
+		return err
+	}
+
+	if len(external) == 0 && len(unknown) == 0 {
+		ui.Info(fmt.Sprintf("No orphans: every worktree is in %s, and every directory there is a worktree.", cfg.WorktreeDir))
+		return nil
+	}
+
+	ui.Heading("Orphans")
+	t := ui.NewTable().Headers("PATH", "BRANCH", "KIND")
+	for _, wt := range external {
+		t.Row(displayPath(projectRoot, wt.Path), wt.Branch, "worktree outside "+cfg.WorktreeDir)
+	}
+	for _, dir := range unknown {
+		t.Row(displayPath(projectRoot, dir), "", "not a git worktree")
+	}
+	ui.PrintTable(t)
+
+	clean, _ := cmd.Flags().GetBool("clean")
+	if !clean {
+		if len(external) > 0 {
+			ui.Step("Move worktrees into " + cfg.WorktreeDir + " with 'wt adopt <path>'.")
+		}
+		if len(unknown) > 0 {
+			ui.Step(fmt.Sprintf("Delete the %d directory(ies) that aren't worktrees with 'wt orphans --clean'.", len(unknown)))
+		}
+		return nil
+	}
+	if len(unknown) == 0 {
+		ui.Info("No directories to clean.")
+		return nil
+	}
+	return cleanOrphanDirs(cmd, projectRoot, unknown)
+}
+
+// cleanOrphanDirs deletes the directories under the worktrees directory
+// that aren't worktrees, after confirming unless --force is set.
+func cleanOrphanDirs(cmd *cobra.Command, projectRoot string, dirs []string) error {
+	force, _ := cmd.Flags().GetBool("force")
+	if !force && !IsDryRun() {
+		prompter := &ui.InteractivePrompter{}
+		confirmed, err := prompter.Confirm(fmt.Sprintf("Delete %d directory(ies) and everything in them?", len(dirs)))
+		if err != nil {
+			if ui.IsUserAbort(err) {
+				return nil
+			}
+			return err
+		}
+		if !confirmed {
+			ui.Info("Cancelled.")
+			return nil
+		}
+	}
+
+	var removed int
+	for _, dir := range dirs {
+		if IsDryRun() {
+			ui.DryRunNotice("remove " + dir)
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			ui.Warning(fmt.Sprintf("Could not remove %s: %s", displayPath(projectRoot, dir), err))
+			continue
+		}
+		ui.Info("  removed " + displayPath(projectRoot, dir))
+		removed++
+	}
+	ui.Success(fmt.Sprintf("Removed %d directory(ies)", removed))
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/bkildow/wt-cli/internal/config"
@@ -53,14 +52,14 @@ func loadProject() (string, *config.Config, error) {
 // wt clone setups) and the main working tree at the project root (from
 // wt init setups).
 func filterManagedWorktrees(worktrees []git.WorktreeInfo, projectRoot string) []git.WorktreeInfo {
-	absRoot := resolvePathBest(projectRoot)
+	absRoot := project.ResolvePath(projectRoot)
 	var filtered []git.WorktreeInfo
 	for _, wt := range worktrees {
 		if wt.Bare {
 			continue
 		}
 		// Fast path: exact string match avoids syscall
-		if wt.Path == projectRoot || resolvePathBest(wt.Path) == absRoot {
+		if wt.Path == projectRoot || project.ResolvePath(wt.Path) == absRoot {
 			continue
 		}
 		filtered = append(filtered, wt)
@@ -79,14 +78,6 @@ func detectDefaultBranch(ctx context.Context, runner git.Git) string {
 	return branch
 }
 
-// resolvePathBest tries to resolve symlinks; falls back to filepath.Clean.
-func resolvePathBest(p string) string {
-	if resolved, err := filepath.EvalSymlinks(p); err == nil {
-		return resolved
-	}
-	return filepath.Clean(p)
-}
-
 // resolveCurrentWorktree finds the managed worktree that contains the current
 // working directory. Returns the matching WorktreeInfo and true, or a zero
 // value and false if the cwd is not inside any managed worktree.
@@ -95,9 +86,9 @@ func resolveCurrentWorktree(filtered []git.WorktreeInfo) (git.WorktreeInfo, bool
 	if err != nil {
 		return git.WorktreeInfo{}, false
 	}
-	currentPath := resolvePathBest(cwd)
+	currentPath := project.ResolvePath(cwd)
 	for _, wt := range filtered {
-		wtPath := resolvePathBest(wt.Path)
+		wtPath := project.ResolvePath(wt.Path)
 		if currentPath == wtPath || strings.HasPrefix(currentPath, wtPath+string(os.PathSeparator)) {
 			return wt, true
 		}
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/git"
 )
 
@@ -299,3 +300,24 @@ func TestIsInsideWorktree(t *testing.T) {
 		}
 	})
 }
+
+func TestExternalWorktrees(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	for _, dir := range []string{filepath.Join(root, "worktrees", "develop"), filepath.Join(outside, "hotfix")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	worktrees := []git.WorktreeInfo{
+		{Path: filepath.Join(root, ".bare"), Bare: true},
+		{Path: filepath.Join(root, "worktrees", "develop"), Branch: "develop"},
+		{Path: filepath.Join(outside, "hotfix"), Branch: "hotfix"},
+		{Path: filepath.Join(outside, "deleted"), Branch: "deleted"},
+	}
+
+	got := externalWorktrees(worktrees, root, &config.Config{WorktreeDir: "worktrees"})
+	if len(got) != 1 || got[0].Branch != "hotfix" {
+		t.Errorf("externalWorktrees = %+v, want only hotfix", got)
+	}
+}
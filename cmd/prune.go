@@ -20,6 +20,7 @@ func newPruneCmd() *cobra.Command {
 	}
 	cmd.Flags().Bool("force", false, "Skip confirmation prompt")
 	cmd.Flags().Bool("skip-teardown", false, "Skip running teardown hooks before removing worktrees")
+	cmd.Flags().Bool("kill", false, "Terminate processes still running inside the worktrees")
 	return cmd
 }
 
@@ -44,7 +45,7 @@ func runPrune(cmd *cobra.Command, args []string) error {
 	filtered := filterManagedWorktrees(worktrees, projectRoot)
 
 	// Resolve current worktree path for comparison
-	currentPath := resolvePathBest(cwd)
+	currentPath := project.ResolvePath(cwd)
 
 	var pruneable []git.WorktreeInfo
 	for _, wt := range filtered {
@@ -52,7 +53,7 @@ func runPrune(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		if resolvePathBest(wt.Path) == currentPath {
+		if project.ResolvePath(wt.Path) == currentPath {
 			continue
 		}
 
@@ -98,16 +99,28 @@ func runPrune(cmd *cobra.Command, args []string) error {
 	}
 
 	skipTeardown, _ := cmd.Flags().GetBool("skip-teardown")
+	kill, _ := cmd.Flags().GetBool("kill")
 
 	var removed int
 	for _, wt := range pruneable {
+		target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+		if err := project.RunHooks(ctx, cfg, project.HookPreRemove, target, IsDryRun()); err != nil {
+			ui.Warning(fmt.Sprintf("Skipping %s: %s", wt.Branch, err))
+			continue
+		}
+
+		stopWorktreeProcesses(wt.Path, wt.Branch, kill, IsDryRun())
+
 		if !skipTeardown {
-			if err := project.RunTeardownHooks(ctx, cfg, wt.Path, IsDryRun()); err != nil {
+			if err := project.RunTeardownHooks(ctx, cfg, target, IsDryRun()); err != nil {
 				ui.Warning("Teardown hooks failed for " + wt.Branch + ": " + err.Error())
 			}
-			if err := project.RunParallelTeardownHooks(ctx, cfg, wt.Path, IsDryRun()); err != nil {
+			if err := project.RunParallelTeardownHooks(ctx, cfg, target, IsDryRun()); err != nil {
 				ui.Warning("Parallel teardown hooks failed for " + wt.Branch + ": " + err.Error())
 			}
+			if err := project.ComposeDown(ctx, cfg, target, IsDryRun()); err != nil {
+				ui.Warning("Compose down failed for " + wt.Branch + ": " + err.Error())
+			}
 		}
 
 		ui.Step("Removing worktree: " + wt.Branch)
@@ -120,6 +133,13 @@ func runPrune(cmd *cobra.Command, args []string) error {
 			ui.Warning("Could not delete branch: " + err.Error())
 		}
 
+		if err := project.RunHooks(ctx, cfg, project.HookPostRemove, target, IsDryRun()); err != nil {
+			ui.Warning("Post-remove hooks failed for " + wt.Branch + ": " + err.Error())
+		}
+		if !skipTeardown {
+			cleanWorktreeResources(ctx, projectRoot, cfg, wt.Path)
+		}
+
 		removed++
 	}
 
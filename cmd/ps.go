@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// psCommandWidth caps the COMMAND column so long command lines don't wrap
+// the table.
+const psCommandWidth = 60
+
+func newPsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ps [name]",
+		Short: "List processes running inside worktrees",
+		Long: `List processes whose working directory, executable, or open files are
+inside a worktree, such as dev servers and watchers, with the TCP ports they
+listen on. 'wt remove --kill' and 'wt prune --kill' stop them.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeWorktreeNames,
+		RunE:              runPs,
+	}
+}
+
+func runPs(cmd *cobra.Command, args []string) error {
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+	worktrees, err := runner.WorktreeList(cmd.Context())
+	if err != nil {
+		return err
+	}
+	filtered := filterManagedWorktrees(worktrees, projectRoot)
+	if len(args) > 0 {
+		wt, err := selectWorktree(args, filtered)
+		if err != nil {
+			return err
+		}
+		filtered = []git.WorktreeInfo{wt}
+	}
+
+	paths := make([]string, len(filtered))
+	for i, wt := range filtered {
+		paths[i] = wt.Path
+	}
+	found, err := project.FindProcesses(paths)
+	if err != nil {
+		return err
+	}
+
+	t := ui.NewTable().Headers("BRANCH", "PID", "COMMAND", "PORTS")
+	var count int
+	for _, wt := range filtered {
+		for _, p := range found[wt.Path] {
+			t.Row(wt.Branch, strconv.Itoa(p.PID), truncateCommand(p.Command), joinInts(p.Ports))
+			count++
+		}
+	}
+	if count == 0 {
+		ui.Info("No processes running inside worktrees.")
+		return nil
+	}
+	ui.Heading("Processes")
+	ui.PrintTable(t)
+	return nil
+}
+
+func truncateCommand(command string) string {
+	if len(command) <= psCommandWidth {
+		return command
+	}
+	return command[:psCommandWidth-3] + "..."
+}
+
+func joinInts(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
+
+// stopWorktreeProcesses deals with processes still running inside a
+// worktree about to be removed: with kill it terminates them, otherwise it
+// warns that they may keep the directory busy. Under dry-run it only
+// reports.
+func stopWorktreeProcesses(worktreePath, branch string, kill, dryRun bool) {
+	found, err := project.FindProcesses([]string{worktreePath})
+	if err != nil {
+		if kill {
+			ui.Warning("Could not list worktree processes: " + err.Error())
+		}
+		return
+	}
+	procs := found[worktreePath]
+	if len(procs) == 0 {
+		return
+	}
+
+	pids := make([]int, len(procs))
+	for i, p := range procs {
+		pids[i] = p.PID
+	}
+	list := joinInts(pids)
+
+	switch {
+	case !kill:
+		ui.Warning(fmt.Sprintf("%d process(es) still running in %s (PID %s); use --kill to stop them", len(procs), branch, list))
+	case dryRun:
+		ui.DryRunNotice(fmt.Sprintf("would terminate %d process(es) in %s (PID %s)", len(procs), branch, list))
+	default:
+		ui.Warning(fmt.Sprintf("Terminating %d process(es) in %s (PID %s)", len(procs), branch, list))
+		terminateAndWait(pids)
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/bkildow/wt-cli/internal/git"
@@ -21,6 +22,7 @@ func newRemoveCmd() *cobra.Command {
 	}
 	cmd.Flags().Bool("force", false, "Remove even if worktree has uncommitted changes")
 	cmd.Flags().Bool("skip-teardown", false, "Skip running teardown hooks before removing the worktree")
+	cmd.Flags().Bool("kill", false, "Terminate processes still running inside the worktree")
 	return cmd
 }
 
@@ -77,17 +79,27 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: selected.Path, Branch: selected.Branch}
+	if err := project.RunHooks(ctx, cfg, project.HookPreRemove, target, IsDryRun()); err != nil {
+		return fmt.Errorf("worktree not removed: %w", err)
+	}
+
 	// Terminate any in-progress background setup before teardown.
 	terminateBackgroundSetup(selected.Path, selected.Branch, IsDryRun())
+	kill, _ := cmd.Flags().GetBool("kill")
+	stopWorktreeProcesses(selected.Path, selected.Branch, kill, IsDryRun())
 
 	skipTeardown, _ := cmd.Flags().GetBool("skip-teardown")
 	if !skipTeardown {
-		if err := project.RunTeardownHooks(ctx, cfg, selected.Path, IsDryRun()); err != nil {
+		if err := project.RunTeardownHooks(ctx, cfg, target, IsDryRun()); err != nil {
 			ui.Warning("Teardown hooks failed: " + err.Error())
 		}
-		if err := project.RunParallelTeardownHooks(ctx, cfg, selected.Path, IsDryRun()); err != nil {
+		if err := project.RunParallelTeardownHooks(ctx, cfg, target, IsDryRun()); err != nil {
 			ui.Warning("Parallel teardown hooks failed: " + err.Error())
 		}
+		if err := project.ComposeDown(ctx, cfg, target, IsDryRun()); err != nil {
+			ui.Warning("Compose down failed: " + err.Error())
+		}
 	}
 
 	isMainBranch := selected.Branch == mainBranch
@@ -110,6 +122,13 @@ func runRemove(cmd *cobra.Command, args []string) error {
 
 	ui.Success("Removed worktree: " + selected.Branch)
 
+	if err := project.RunHooks(ctx, cfg, project.HookPostRemove, target, IsDryRun()); err != nil {
+		ui.Warning("Post-remove hooks failed: " + err.Error())
+	}
+	if !skipTeardown {
+		cleanWorktreeResources(ctx, projectRoot, cfg, selected.Path)
+	}
+
 	// Print project root to stdout so the shell wrapper can cd the user there.
 	if relocating {
 		fmt.Println(projectRoot)
@@ -135,21 +154,31 @@ func terminateBackgroundSetup(worktreePath, branch string, dryRun bool) {
 	}
 
 	ui.Warning(fmt.Sprintf("Terminating in-progress setup for %s (PID %d)", branch, state.PID))
-	_ = terminateProcess(state.PID)
+	terminateAndWait([]int{state.PID})
+}
+
+// terminateAndWait sends SIGTERM to each process and waits for them to
+// exit, force-killing any still running after two seconds.
+func terminateAndWait(pids []int) {
+	for _, pid := range pids {
+		_ = terminateProcess(pid)
+	}
 
-	// Poll for exit, then force-kill if the process doesn't terminate.
+	// Poll for exit, then force-kill the processes that don't terminate.
 	deadline := time.After(2 * time.Second)
 	tick := time.NewTicker(100 * time.Millisecond)
 	defer tick.Stop()
 	for {
 		select {
 		case <-tick.C:
-			if !project.IsProcessAlive(state.PID) {
+			if !slices.ContainsFunc(pids, project.IsProcessAlive) {
 				return
 			}
 		case <-deadline:
-			if project.IsProcessAlive(state.PID) {
-				_ = killProcess(state.PID)
+			for _, pid := range pids {
+				if project.IsProcessAlive(pid) {
+					_ = killProcess(pid)
+				}
 			}
 			return
 		}
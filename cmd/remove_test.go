@@ -3,6 +3,7 @@ package cmd
 import (
 	"io"
 	"os/exec"
+	"runtime"
 	"testing"
 	"time"
 
@@ -62,3 +63,42 @@ func TestTerminateBackgroundSetup(t *testing.T) {
 		t.Error("terminateBackgroundSetup left the setup process running")
 	}
 }
+
+// TestStopWorktreeProcesses checks that --kill terminates what runs inside
+// the worktree, and that neither dry-run nor a plain remove does.
+func TestStopWorktreeProcesses(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process scanning needs /proc")
+	}
+	origOutput := ui.Output
+	ui.Output = io.Discard
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	worktree := t.TempDir()
+	cmd := exec.Command("sleep", "30")
+	cmd.Dir = worktree
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("could not start sleep: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		<-done
+	})
+	pid := cmd.Process.Pid
+
+	stopWorktreeProcesses(worktree, "feature-x", false, false)
+	stopWorktreeProcesses(worktree, "feature-x", true, true)
+	if !project.IsProcessAlive(pid) {
+		t.Fatal("stopWorktreeProcesses killed a process without --kill, or under dry-run")
+	}
+
+	stopWorktreeProcesses(worktree, "feature-x", true, false)
+	if project.IsProcessAlive(pid) {
+		t.Error("stopWorktreeProcesses --kill left the process running")
+	}
+}
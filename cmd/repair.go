@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/git"
 	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
@@ -15,12 +16,14 @@ import (
 func newRepairCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "repair",
-		Short: "Repair worktree git config for compatibility with git 2.52+",
+		Short: "Repair worktree git config for git 2.52+ and absolute shared symlinks",
 		Long: "Enables extensions.worktreeConfig on the common dir and writes core.bare=false " +
 			"into each linked worktree's config.worktree. Idempotent — safe to re-run.\n\n" +
 			"Use this on existing projects after upgrading wt or git: git 2.52+ refuses " +
 			"auto-discovery from a worktree attached to a bare common dir unless each " +
-			"worktree explicitly overrides core.bare.",
+			"worktree explicitly overrides core.bare.\n\n" +
+			"With symlink_style: relative, also rewrites shared symlinks that apply created " +
+			"with absolute targets, including ones left pointing at where the project used to be.",
 		Args: cobra.NoArgs,
 		RunE: runRepair,
 	}
@@ -69,10 +72,30 @@ func runRepair(cmd *cobra.Command, args []string) error {
 		repaired++
 	}
 
-	ui.Success(fmt.Sprintf("Repair complete: %d worktree(s) inspected, %d repaired", inspected, repaired))
+	relinked := 0
+	if cfg.RelativeSymlinks() {
+		for _, wt := range filterManagedWorktrees(worktrees, projectRoot) {
+			n, err := project.RelinkSymlinks(projectRoot, wt.Path, cfg, wt.Branch, dry)
+			if err != nil {
+				return fmt.Errorf("%s: %w", wt.Branch, err)
+			}
+			relinked += n
+		}
+	}
+
+	ui.Success(fmt.Sprintf("Repair complete: %d worktree(s) inspected, %d repaired%s", inspected, repaired, relinkedSuffix(cfg, relinked)))
 	return nil
 }
 
+// relinkedSuffix adds the rewritten symlinks to the repair summary when
+// symlink_style is relative.
+func relinkedSuffix(cfg *config.Config, n int) string {
+	if !cfg.RelativeSymlinks() {
+		return ""
+	}
+	return fmt.Sprintf(", %d symlink(s) made relative", n)
+}
+
 // worktreeBareOverrideOK reports whether the worktree's config.worktree
 // already contains core.bare = false. Returns true when the override is in
 // place, false when missing or when the file doesn't exist.
@@ -95,14 +118,30 @@ func worktreeBareOverrideOK(worktreePath string) (bool, error) {
 // (worktrees have a .git file: "gitdir: <relative-or-absolute-path>") and
 // returns the path to its config.worktree file.
 func worktreeConfigPath(worktreePath string) (string, error) {
+	gitdir, err := readGitLink(worktreePath)
+	if err != nil {
+		return "", err
+	}
+	// In init setups the main worktree's .git is a real directory.
+	if gitdir == "" {
+		return filepath.Join(worktreePath, ".git", "config.worktree"), nil
+	}
+	if !filepath.IsAbs(gitdir) {
+		gitdir = filepath.Join(worktreePath, gitdir)
+	}
+	return filepath.Join(gitdir, "config.worktree"), nil
+}
+
+// readGitLink returns the gitdir a worktree's .git file points to, as
+// written, or "" when .git is a directory.
+func readGitLink(worktreePath string) (string, error) {
 	gitFile := filepath.Join(worktreePath, ".git")
 	info, err := os.Stat(gitFile)
 	if err != nil {
 		return "", err
 	}
-	// In init setups the main worktree's .git is a real directory.
 	if info.IsDir() {
-		return filepath.Join(gitFile, "config.worktree"), nil
+		return "", nil
 	}
 	data, err := os.ReadFile(gitFile)
 	if err != nil {
@@ -112,10 +151,7 @@ func worktreeConfigPath(worktreePath string) (string, error) {
 	if gitdir == "" {
 		return "", fmt.Errorf("malformed .git file at %s", gitFile)
 	}
-	if !filepath.IsAbs(gitdir) {
-		gitdir = filepath.Join(worktreePath, gitdir)
-	}
-	return filepath.Join(gitdir, "config.worktree"), nil
+	return gitdir, nil
 }
 
 // hasBareFalse parses a git config blob and reports whether [core] bare = false
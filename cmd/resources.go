@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newResourceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resource",
+		Short: "Register external resources created for a worktree",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "register <kind> <id>",
+		Short: "Record a resource, such as a docker volume, as belonging to this worktree",
+		Long: `Record an external resource as belonging to a worktree, so 'wt remove',
+'wt prune', and 'wt resources clean' can remove it with the resource_cleanup
+command configured for its kind. Meant to be called from setup hooks, where
+WT_WORKTREE_PATH names the worktree; otherwise the current worktree is used.`,
+		Example: `  wt resource register docker-volume "myapp_${WT_BRANCH//\//-}_pgdata"`,
+		Args:    cobra.ExactArgs(2),
+		RunE:    runResourceRegister,
+	})
+	return cmd
+}
+
+func newResourcesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List external resources registered for worktrees",
+		Args:  cobra.NoArgs,
+		RunE:  runResources,
+	}
+	cmd.Flags().Bool("orphans", false, "Only list resources whose worktree no longer exists")
+	cmd.AddCommand(&cobra.Command{
+		Use:   "clean",
+		Short: "Remove resources whose worktree no longer exists",
+		Args:  cobra.NoArgs,
+		RunE:  runResourcesClean,
+	})
+	return cmd
+}
+
+func runResourceRegister(cmd *cobra.Command, args []string) error {
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+	worktrees, err := runner.WorktreeList(cmd.Context())
+	if err != nil {
+		return err
+	}
+	wt, err := resourceWorktree(filterManagedWorktrees(worktrees, projectRoot))
+	if err != nil {
+		return err
+	}
+
+	r := project.Resource{Kind: args[0], ID: args[1], Branch: wt.Branch, WorktreePath: wt.Path}
+	if err := project.RegisterResource(projectRoot, cfg, r, IsDryRun()); err != nil {
+		return err
+	}
+	if _, ok := cfg.ResourceCleanup[r.Kind]; !ok {
+		ui.Warning(fmt.Sprintf("No resource_cleanup command for %s; it won't be removed with the worktree", r.Kind))
+	}
+	ui.Success(fmt.Sprintf("Registered %s %s for %s", r.Kind, r.ID, wt.Branch))
+	return nil
+}
+
+// resourceWorktree returns the worktree a resource is registered for: the
+// one in WT_WORKTREE_PATH when run from a hook, else the current one.
+func resourceWorktree(filtered []git.WorktreeInfo) (git.WorktreeInfo, error) {
+	if path := os.Getenv("WT_WORKTREE_PATH"); path != "" {
+		for _, wt := range filtered {
+			if project.ResolvePath(wt.Path) == project.ResolvePath(path) {
+				return wt, nil
+			}
+		}
+		return git.WorktreeInfo{}, fmt.Errorf("WT_WORKTREE_PATH %s is not a worktree of this project", path)
+	}
+	wt, ok := resolveCurrentWorktree(filtered)
+	if !ok {
+		return git.WorktreeInfo{}, fmt.Errorf("not inside a managed worktree; run from the worktree the resource belongs to")
+	}
+	return wt, nil
+}
+
+func runResources(cmd *cobra.Command, args []string) error {
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	resources, err := project.ReadResources(projectRoot, cfg)
+	if err != nil {
+		return err
+	}
+	if orphans, _ := cmd.Flags().GetBool("orphans"); orphans {
+		resources = slices.DeleteFunc(resources, func(r project.Resource) bool { return !r.Orphaned() })
+	}
+	if len(resources) == 0 {
+		ui.Info("No resources registered. Hooks can add them with 'wt resource register <kind> <id>'.")
+		return nil
+	}
+
+	ui.Heading("Resources")
+	t := ui.NewTable().Headers("KIND", "ID", "BRANCH", "PATH", "STATUS")
+	var orphaned int
+	for _, r := range resources {
+		relPath, err := filepath.Rel(projectRoot, r.WorktreePath)
+		if err != nil {
+			relPath = r.WorktreePath
+		}
+		status := "active"
+		if r.Orphaned() {
+			status = "orphaned"
+			orphaned++
+		}
+		t.Row(r.Kind, r.ID, r.Branch, relPath, status)
+	}
+	ui.PrintTable(t)
+
+	if orphaned > 0 {
+		ui.Step(fmt.Sprintf("Run 'wt resources clean' to remove the %d orphaned resource(s).", orphaned))
+	}
+	return nil
+}
+
+func runResourcesClean(cmd *cobra.Command, args []string) error {
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	resources, err := project.ReadResources(projectRoot, cfg)
+	if err != nil {
+		return err
+	}
+	resources = slices.DeleteFunc(resources, func(r project.Resource) bool { return !r.Orphaned() })
+	if len(resources) == 0 {
+		ui.Info("No orphaned resources.")
+		return nil
+	}
+
+	ui.Step(fmt.Sprintf("Cleaning %d orphaned resource(s)", len(resources)))
+	cleaned, err := project.CleanResources(cmd.Context(), projectRoot, cfg, resources, IsDryRun())
+	if err != nil {
+		return err
+	}
+	ui.Success(fmt.Sprintf("Cleaned %d of %d orphaned resource(s)", cleaned, len(resources)))
+	return nil
+}
+
+// cleanWorktreeResources removes the resources registered for a worktree
+// that was just removed. Failures are reported, and the resources left for
+// 'wt resources clean'.
+func cleanWorktreeResources(ctx context.Context, projectRoot string, cfg *config.Config, worktreePath string) {
+	resources, err := project.ReadResources(projectRoot, cfg)
+	if err != nil {
+		ui.Warning("Could not read resources: " + err.Error())
+		return
+	}
+	resources = project.WorktreeResources(resources, worktreePath)
+	if len(resources) == 0 {
+		return
+	}
+	ui.Step(fmt.Sprintf("Cleaning %d registered resource(s)", len(resources)))
+	if _, err := project.CleanResources(ctx, projectRoot, cfg, resources, IsDryRun()); err != nil {
+		ui.Warning("Could not update resources: " + err.Error())
+	}
+}
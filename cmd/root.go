@@ -53,6 +53,8 @@ func init() {
 	rootCmd.AddCommand(newSetupCmd())
 	rootCmd.AddCommand(newCdCmd())
 	rootCmd.AddCommand(newApplyCmd())
+	rootCmd.AddCommand(newShareCmd())
+	rootCmd.AddCommand(newUnshareCmd())
 	rootCmd.AddCommand(newConfigCmd())
 	rootCmd.AddCommand(newCompletionCmd())
 	rootCmd.AddCommand(newShellInitCmd())
@@ -60,9 +62,19 @@ func init() {
 	rootCmd.AddCommand(newStatusCmd())
 	rootCmd.AddCommand(newSyncCmd())
 	rootCmd.AddCommand(newPruneCmd())
+	rootCmd.AddCommand(newDiskCmd())
+	rootCmd.AddCommand(newPsCmd())
+	rootCmd.AddCommand(newResourceCmd())
+	rootCmd.AddCommand(newResourcesCmd())
 	rootCmd.AddCommand(newRepairCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newAdoptCmd())
+	rootCmd.AddCommand(newOrphansCmd())
 	rootCmd.AddCommand(newRootCmd())
+	rootCmd.AddCommand(newRunCmd())
+	rootCmd.AddCommand(newExecCmd())
 	rootCmd.AddCommand(newRunSetupCmd())
+	rootCmd.AddCommand(newOnEnterCmd())
 	rootCmd.AddCommand(newClaudeCmd())
 }
 
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "run <task> [name]",
+		Short:             "Run a named task from .worktree.yml in a worktree",
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeTaskArgs,
+		RunE:              runRun,
+	}
+	cmd.Flags().Bool("all", false, "Run the task in every worktree, one after another")
+	cmd.Flags().Bool("parallel", false, "Run the task in every worktree concurrently (implies --all)")
+	return cmd
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dry := IsDryRun()
+	task := args[0]
+
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	if _, err := project.LookupTask(cfg, task); err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), dry)
+
+	worktrees, err := runner.WorktreeList(ctx)
+	if err != nil {
+		return err
+	}
+
+	filtered := filterManagedWorktrees(worktrees, projectRoot)
+
+	if len(filtered) == 0 {
+		return fmt.Errorf("no worktrees found")
+	}
+
+	all, _ := cmd.Flags().GetBool("all")
+	parallel, _ := cmd.Flags().GetBool("parallel")
+	if (all || parallel) && len(args) > 1 {
+		return fmt.Errorf("cannot name a worktree with --all or --parallel")
+	}
+
+	if parallel {
+		targets := make([]project.HookTarget, len(filtered))
+		for i, wt := range filtered {
+			targets[i] = project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+		}
+		ui.Step(fmt.Sprintf("Running %s in %d worktree(s) in parallel", task, len(targets)))
+		if err := project.RunTaskParallel(ctx, cfg, task, targets, dry); err != nil {
+			return err
+		}
+		ui.Success(fmt.Sprintf("Ran %s in %d worktree(s)", task, len(targets)))
+		return nil
+	}
+
+	if all {
+		var failCount int
+		for _, wt := range filtered {
+			ui.Step("Running " + task + " in: " + wt.Branch)
+			target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+			if err := project.RunTask(ctx, cfg, task, target, dry); err != nil {
+				ui.Error("Failed: " + wt.Branch + ": " + err.Error())
+				failCount++
+			}
+		}
+		if failCount > 0 {
+			return fmt.Errorf("task %s failed in %d of %d worktree(s)", task, failCount, len(filtered))
+		}
+		ui.Success(fmt.Sprintf("Ran %s in %d worktree(s)", task, len(filtered)))
+		return nil
+	}
+
+	selected, err := selectWorktree(args[1:], filtered)
+	if err != nil {
+		if ui.IsUserAbort(err) {
+			return nil
+		}
+		return err
+	}
+
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: selected.Path, Branch: selected.Branch}
+	if err := project.RunTask(ctx, cfg, task, target, dry); err != nil {
+		return fmt.Errorf("task %s failed in %s: %w", task, selected.Branch, err)
+	}
+	return nil
+}
@@ -1,15 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	lipgloss "charm.land/lipgloss/v2"
 	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/notify"
 	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
 	"github.com/charmbracelet/colorprofile"
@@ -25,6 +28,8 @@ func newRunSetupCmd() *cobra.Command {
 	}
 	cmd.Flags().String("worktree-path", "", "Path to the worktree")
 	cmd.Flags().String("project-root", "", "Path to the project root")
+	cmd.Flags().String("branch", "", "Branch name, for notifications")
+	cmd.Flags().String("tty", "", "Terminal device of the launching shell, for the terminal notifier")
 	return cmd
 }
 
@@ -57,7 +62,7 @@ func runRunSetup(cmd *cobra.Command, _ []string) error {
 	ui.Output = cpw
 	lipgloss.Writer = cpw
 
-	hooksTotal := len(cfg.Setup) + len(cfg.ParallelSetup)
+	hooksTotal := cfg.SetupSteps()
 
 	state := &project.SetupState{
 		Status:     project.SetupRunning,
@@ -80,17 +85,26 @@ func runRunSetup(cmd *cobra.Command, _ []string) error {
 		}
 	}()
 
-	var setupErr error
+	branch, _ := cmd.Flags().GetString("branch")
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: worktreePath, Branch: branch}
+	setupErr := project.ComposeUp(ctx, cfg, target, false)
+	if cfg.Compose.UpEnabled() {
+		state.HooksCompleted = 1
+		_ = project.WriteSetupState(worktreePath, state)
+	}
 
-	// Run serial hooks with progress tracking.
+	// Run serial hooks with progress tracking, after the compose step.
+	done := state.HooksCompleted
 	onProgress := func(index int, cmdStr string, hookErr error) {
-		state.HooksCompleted = index + 1
+		state.HooksCompleted = done + index + 1
 		_ = project.WriteSetupState(worktreePath, state)
 	}
-	setupErr = project.RunSetupHooks(ctx, cfg, worktreePath, false, onProgress)
+	if hErr := project.RunSetupHooks(ctx, cfg, target, false, onProgress); hErr != nil {
+		setupErr = errors.Join(setupErr, hErr)
+	}
 
 	// Run parallel hooks as a batch.
-	if pErr := project.RunParallelSetupHooks(ctx, cfg, worktreePath, false); pErr != nil {
+	if pErr := project.RunParallelSetupHooks(ctx, cfg, target, false); pErr != nil {
 		setupErr = errors.Join(setupErr, pErr)
 	}
 	state.HooksCompleted = hooksTotal
@@ -110,5 +124,41 @@ func runRunSetup(cmd *cobra.Command, _ []string) error {
 		ui.Success("Worktree setup completed in " + elapsed)
 	}
 
-	return project.WriteSetupState(worktreePath, state)
+	if err := project.WriteSetupState(worktreePath, state); err != nil {
+		return err
+	}
+
+	// A cancelled context means we were signalled (e.g. by 'wt remove'),
+	// which is not a completion anyone needs to hear about.
+	if ctx.Err() == nil {
+		sendSetupNotifications(ctx, cmd, cfg, worktreePath, state)
+	}
+	return nil
+}
+
+// sendSetupNotifications delivers the final setup state to the configured
+// notification hooks and built-in notifiers. Failures are logged only: the
+// setup itself has already finished and its state is on disk.
+func sendSetupNotifications(ctx context.Context, cmd *cobra.Command, cfg *config.Config, worktreePath string, state *project.SetupState) {
+	branch, _ := cmd.Flags().GetString("branch")
+	tty, _ := cmd.Flags().GetString("tty")
+	if branch == "" {
+		branch = filepath.Base(worktreePath)
+	}
+
+	notifiers := notify.FromConfig(cfg, tty)
+	if len(notifiers) == 0 {
+		return
+	}
+
+	ev := notify.Event{
+		Status:       string(state.Status),
+		Branch:       branch,
+		WorktreePath: worktreePath,
+		Elapsed:      state.CompletedAt.Sub(state.StartedAt),
+		Error:        state.Error,
+	}
+	if err := notify.Send(ctx, notifiers, ev); err != nil {
+		ui.Warning("Notification failed: " + err.Error())
+	}
 }
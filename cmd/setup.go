@@ -31,7 +31,7 @@ func runSetup(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if len(cfg.Setup) == 0 && len(cfg.ParallelSetup) == 0 {
+	if !cfg.HasSetup() {
 		ui.Info("No setup hooks configured in .worktree.yml")
 		return nil
 	}
@@ -70,7 +70,7 @@ func runSetup(cmd *cobra.Command, args []string) error {
 
 	msg := "Running setup for: " + selected.Branch
 	if background {
-		return runSetupBackground(projectRoot, selected.Path, cfg, dry, msg)
+		return runSetupBackground(projectRoot, selected.Path, selected.Branch, cfg, dry, msg)
 	}
-	return runSetupForeground(cmd, selected.Path, cfg, dry, msg)
+	return runSetupForeground(cmd, projectRoot, selected.Path, selected.Branch, cfg, dry, msg)
 }
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/git"
+	"github.com/bkildow/wt-cli/internal/project"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newShareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "share <path> [--copy | --symlink | --template]",
+		Short: "Move a file from this worktree into shared/ and apply it everywhere",
+		Long: "Moves an untracked file or directory from the current worktree into shared/copy (the default), shared/symlink,\n" +
+			"or shared/copy as a .template with the worktree's paths and branch name replaced by ${...} variables,\n" +
+			"then applies shared files to every worktree. Unless apply_conflict is set, other worktrees keep their\n" +
+			"own differing copies.",
+		Args: cobra.ExactArgs(1),
+		RunE: runShare,
+	}
+	cmd.Flags().Bool("copy", false, "Copy the file into each worktree (default)")
+	cmd.Flags().Bool("symlink", false, "Symlink the file into each worktree")
+	cmd.Flags().Bool("template", false, "Copy the file as a template, replacing worktree paths and the branch name with variables")
+	cmd.MarkFlagsMutuallyExclusive("copy", "symlink", "template")
+	return cmd
+}
+
+func newUnshareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unshare <path>",
+		Short: "Move a shared file back into this worktree",
+		Long: "Stops sharing a file: the current worktree keeps it, and it is removed from shared/.\n" +
+			"Other worktrees lose their symlinks and unchanged copies unless --keep is set.",
+		Args: cobra.ExactArgs(1),
+		RunE: runUnshare,
+	}
+	cmd.Flags().Bool("keep", false, "Leave a copy of the file in every other worktree")
+	return cmd
+}
+
+func runShare(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	dry := IsDryRun()
+
+	mode := project.ShareCopy
+	if symlink, _ := cmd.Flags().GetBool("symlink"); symlink {
+		mode = project.ShareSymlink
+	}
+	if template, _ := cmd.Flags().GetBool("template"); template {
+		mode = project.ShareTemplate
+	}
+
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Shared.HasRules() {
+		return fmt.Errorf("wt share uses the shared/copy and shared/symlink layout; with shared.rules set, move the file into %s and add a rule", cfg.SharedDir)
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), dry)
+	filtered, current, rel, err := resolveSharePath(cmd, runner, projectRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	src := filepath.Join(current.Path, rel)
+	if _, err := os.Lstat(src); err != nil {
+		return err
+	}
+	tracked, err := runner.IsTracked(ctx, current.Path, rel)
+	if err != nil {
+		return err
+	}
+	if tracked {
+		return fmt.Errorf("%s is tracked by git; shared files should be untracked (e.g. .env, local config)", rel)
+	}
+
+	ui.Step(fmt.Sprintf("Sharing %s (%s)", rel, mode))
+	vars := project.NewTemplateVars(projectRoot, current.Path, current.Branch)
+	if _, err := project.ShareFile(projectRoot, cfg, src, rel, mode, vars, dry); err != nil {
+		return err
+	}
+
+	// Don't clobber files other worktrees have changed unless asked to.
+	if cfg.ApplyConflict == "" {
+		cfg.ApplyConflict = config.ConflictSkipModified
+	}
+	secrets := project.NewSecrets(projectRoot, cfg)
+	for _, wt := range filtered {
+		ui.Step("Applying to: " + wt.Branch)
+		vars := project.NewTemplateVars(projectRoot, wt.Path, wt.Branch)
+		vars.Secrets = secrets
+		result, err := project.Apply(projectRoot, wt.Path, cfg, dry, &vars)
+		if err != nil {
+			return fmt.Errorf("%s: %w", wt.Branch, err)
+		}
+		target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+		runPostApplyHooks(ctx, cfg, target, result, dry)
+	}
+
+	ui.Success(fmt.Sprintf("Shared %s with %d worktree(s)", rel, len(filtered)))
+	return nil
+}
+
+func runUnshare(cmd *cobra.Command, args []string) error {
+	projectRoot, cfg, err := loadProject()
+	if err != nil {
+		return err
+	}
+
+	runner := git.NewRunner(project.GitDirPath(projectRoot, cfg), IsDryRun())
+	filtered, current, rel, err := resolveSharePath(cmd, runner, projectRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	keep, _ := cmd.Flags().GetBool("keep")
+	var others []project.HookTarget
+	for _, wt := range filtered {
+		if wt.Path != current.Path {
+			others = append(others, project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch})
+		}
+	}
+	target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: current.Path, Branch: current.Branch}
+
+	ui.Step("Unsharing " + rel)
+	if err := project.UnshareFile(projectRoot, cfg, rel, target, others, keep, IsDryRun()); err != nil {
+		return err
+	}
+
+	ui.Success(fmt.Sprintf("%s is no longer shared", rel))
+	return nil
+}
+
+// resolveSharePath finds the worktree the command runs in and returns the
+// managed worktrees along with arg as a path relative to the current one.
+func resolveSharePath(cmd *cobra.Command, runner *git.Runner, projectRoot, arg string) ([]git.WorktreeInfo, git.WorktreeInfo, string, error) {
+	worktrees, err := runner.WorktreeList(cmd.Context())
+	if err != nil {
+		return nil, git.WorktreeInfo{}, "", err
+	}
+	filtered := filterManagedWorktrees(worktrees, projectRoot)
+
+	current, ok := resolveCurrentWorktree(filtered)
+	if !ok {
+		return nil, git.WorktreeInfo{}, "", fmt.Errorf("not inside a managed worktree; run %s from the worktree that has the file", cmd.Name())
+	}
+
+	path := arg
+	if !filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, git.WorktreeInfo{}, "", err
+		}
+		path = filepath.Join(cwd, path)
+	}
+	// Resolve the parent only, so a symlinked file is not followed out of the worktree.
+	path = filepath.Join(project.ResolvePath(filepath.Dir(path)), filepath.Base(path))
+
+	rel, err := filepath.Rel(project.ResolvePath(current.Path), path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, git.WorktreeInfo{}, "", fmt.Errorf("%s is not a file inside the %s worktree", arg, current.Branch)
+	}
+	return filtered, current, rel, nil
+}
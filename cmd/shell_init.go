@@ -8,11 +8,14 @@ import (
 )
 
 const bashFunction = `wt() {
-  if [ "$1" = "cd" ] || [ "$1" = "add" ] || [ "$1" = "root" ] || [ "$1" = "remove" ]; then
+  if [ "$1" = "cd" ] || [ "$1" = "add" ] || [ "$1" = "root" ] || [ "$1" = "remove" ] || [ "$1" = "adopt" ]; then
     local dir
     dir="$(command wt "$@")"
     if [ -n "$dir" ]; then
       cd "$dir" || return
+      if [ "$1" = "cd" ]; then
+        command wt _on-enter
+      fi
     fi
   else
     command wt "$@"
@@ -22,11 +25,14 @@ const bashFunction = `wt() {
 
 const zshFunction = `unalias wt 2>/dev/null
 eval 'wt() {
-  if [ "$1" = "cd" ] || [ "$1" = "add" ] || [ "$1" = "root" ] || [ "$1" = "remove" ]; then
+  if [ "$1" = "cd" ] || [ "$1" = "add" ] || [ "$1" = "root" ] || [ "$1" = "remove" ] || [ "$1" = "adopt" ]; then
     local dir
     dir="$(command wt "$@")"
     if [ -n "$dir" ]; then
       cd "$dir" || return
+      if [ "$1" = "cd" ]; then
+        command wt _on-enter
+      fi
     fi
   else
     command wt "$@"
@@ -35,10 +41,13 @@ eval 'wt() {
 `
 
 const fishFunction = `function wt
-  if test "$argv[1]" = "cd" -o "$argv[1]" = "add" -o "$argv[1]" = "root" -o "$argv[1]" = "remove"
+  if test "$argv[1]" = "cd" -o "$argv[1]" = "add" -o "$argv[1]" = "root" -o "$argv[1]" = "remove" -o "$argv[1]" = "adopt"
     set -l dir (command wt $argv)
     if test -n "$dir"
       cd "$dir"
+      if test "$argv[1]" = "cd"
+        command wt _on-enter
+      end
     end
   else
     command wt $argv
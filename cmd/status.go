@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/bkildow/wt-cli/internal/config"
 	"github.com/bkildow/wt-cli/internal/git"
 	"github.com/bkildow/wt-cli/internal/project"
 	"github.com/bkildow/wt-cli/internal/ui"
@@ -42,8 +48,17 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 	ui.Heading("Worktree Status")
 
-	t := ui.NewTable().Headers("BRANCH", "PATH", "COMMIT", "STATUS", "SETUP", "LAST COMMIT")
-	for _, wt := range filtered {
+	headers := []string{"BRANCH", "PATH", "COMMIT", "STATUS", "SETUP", "SHARED", "LAST COMMIT"}
+	if cfg.Compose != nil {
+		headers = append(headers, "CONTAINERS")
+	}
+	var containers []string
+	if cfg.Compose != nil {
+		containers = composeStatuses(ctx, projectRoot, cfg, filtered)
+	}
+
+	t := ui.NewTable().Headers(headers...)
+	for i, wt := range filtered {
 		relPath, err := filepath.Rel(projectRoot, wt.Path)
 		if err != nil {
 			relPath = wt.Path
@@ -70,8 +85,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		}
 
 		styledSetup := renderSetupStatus(wt.Path)
+		styledShared := renderSharedStatus(projectRoot, wt.Path, cfg)
 
-		t.Row(wt.Branch, relPath, shortHead, styledStatus, styledSetup, age)
+		row := []string{wt.Branch, relPath, shortHead, styledStatus, styledSetup, styledShared, age}
+		if containers != nil {
+			row = append(row, containers[i])
+		}
+		t.Row(row...)
 	}
 	ui.PrintTable(t)
 	warnLowDisk(projectRoot, cfg)
@@ -97,3 +117,75 @@ func renderSetupStatus(worktreePath string) string {
 		return ui.StyleMuted.Render("-")
 	}
 }
+
+// composeStatuses renders the CONTAINERS column for each worktree, asking
+// docker about all of them at once.
+func composeStatuses(ctx context.Context, projectRoot string, cfg *config.Config, worktrees []git.WorktreeInfo) []string {
+	states := make([]project.ComposeState, len(worktrees))
+	errs := make([]error, len(worktrees))
+	var wg sync.WaitGroup
+	for i, wt := range worktrees {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+			states[i], errs[i] = project.ComposeStatus(ctx, cfg, target)
+		}()
+	}
+	wg.Wait()
+
+	cells := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		if errs[i] != nil {
+			ui.Warning("Could not get compose status for " + wt.Branch + ": " + strings.TrimSpace(errs[i].Error()))
+		}
+		cells[i] = renderComposeStatus(states[i], errs[i])
+	}
+	return cells
+}
+
+// renderComposeStatus shows how many of the worktree's compose containers
+// are running.
+func renderComposeStatus(state project.ComposeState, err error) string {
+	switch {
+	case err != nil:
+		return ui.StyleMuted.Render("unknown")
+	case state.Total == 0:
+		return ui.StyleMuted.Render("down")
+	case state.Running == state.Total:
+		return ui.StyleSuccess.Render(fmt.Sprintf("%d running", state.Running))
+	}
+	return ui.StyleWarning.Render(fmt.Sprintf("%d/%d running", state.Running, state.Total))
+}
+
+// renderSharedStatus summarizes the worktree's applied shared files: stale
+// copies and dangling symlinks whose source was removed from shared/.
+func renderSharedStatus(projectRoot, worktreePath string, cfg *config.Config) string {
+	if _, err := os.Stat(project.ManifestPath(worktreePath)); err != nil {
+		return ui.StyleMuted.Render("-")
+	}
+	stale, err := project.StaleEntries(projectRoot, worktreePath, cfg)
+	if err != nil {
+		return ui.StyleError.Render("unreadable")
+	}
+	if len(stale) == 0 {
+		return ui.StyleSuccess.Render("ok")
+	}
+
+	var copies, links int
+	for _, e := range stale {
+		if e.Kind == project.ManifestSymlink {
+			links++
+		} else {
+			copies++
+		}
+	}
+	var parts []string
+	if copies > 0 {
+		parts = append(parts, fmt.Sprintf("%d stale", copies))
+	}
+	if links > 0 {
+		parts = append(parts, fmt.Sprintf("%d dangling", links))
+	}
+	return ui.StyleWarning.Render(strings.Join(parts, ", "))
+}
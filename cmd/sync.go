@@ -91,6 +91,11 @@ func runSync(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		target := project.HookTarget{ProjectRoot: projectRoot, WorktreePath: wt.Path, Branch: wt.Branch}
+		if err := project.RunHooks(ctx, cfg, project.HookPostSync, target, IsDryRun()); err != nil {
+			ui.Warning(fmt.Sprintf("%s: post-sync hooks failed: %s", wt.Branch, err))
+		}
+
 		updated++
 	}
 
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const settingsFile = ".claude/settings.local.json"
 
+// hookSubcommand follows the wt binary in the commands of wt's own hooks.
+const hookSubcommand = " claude hook-worktree-"
+
 // Hook event names used by Claude Code.
 const (
 	HookWorktreeCreate = "WorktreeCreate"
@@ -56,6 +60,40 @@ func IsHooksConfigured(projectRoot string) bool {
 	return hasCreate && hasRemove
 }
 
+// HookCommands returns the commands of wt's WorktreeCreate and
+// WorktreeRemove hooks configured in .claude/settings.local.json, keyed by
+// event. Events without one of wt's hooks are left out, as are other hooks
+// on those events.
+func HookCommands(projectRoot string) (map[string]string, error) {
+	settings, err := readSettings(filepath.Join(projectRoot, settingsFile))
+	if err != nil {
+		return nil, err
+	}
+	hooksMap, _ := settings["hooks"].(map[string]any)
+
+	commands := make(map[string]string)
+	for _, event := range []string{HookWorktreeCreate, HookWorktreeRemove} {
+		matchers, _ := hooksMap[event].([]any)
+		for _, m := range matchers {
+			matcher, _ := m.(map[string]any)
+			hooks, _ := matcher["hooks"].([]any)
+			for _, h := range hooks {
+				hook, _ := h.(map[string]any)
+				if command, ok := hook["command"].(string); ok && strings.Contains(command, hookSubcommand) {
+					commands[event] = command
+				}
+			}
+		}
+	}
+	return commands, nil
+}
+
+// HookBinary returns the wt binary a command from HookCommands invokes.
+func HookBinary(command string) string {
+	binary, _, _ := strings.Cut(command, hookSubcommand)
+	return binary
+}
+
 // RemoveHooks removes the WorktreeCreate and WorktreeRemove hooks from
 // .claude/settings.local.json, preserving other settings.
 func RemoveHooks(projectRoot string) error {
@@ -218,3 +218,36 @@ func readSettingsFile(t *testing.T, projectRoot string) map[string]any {
 	}
 	return settings
 }
+
+func TestHookCommands(t *testing.T) {
+	dir := t.TempDir()
+
+	commands, err := HookCommands(dir)
+	if err != nil || len(commands) != 0 {
+		t.Fatalf("without settings = %v, %v; want none", commands, err)
+	}
+
+	if err := ConfigureHooks(dir, "/opt/bin/wt"); err != nil {
+		t.Fatal(err)
+	}
+	commands, err = HookCommands(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commands[HookWorktreeCreate] != "/opt/bin/wt claude hook-worktree-create" ||
+		commands[HookWorktreeRemove] != "/opt/bin/wt claude hook-worktree-remove" {
+		t.Errorf("HookCommands = %v", commands)
+	}
+	if got := HookBinary(commands[HookWorktreeCreate]); got != "/opt/bin/wt" {
+		t.Errorf("HookBinary = %q, want /opt/bin/wt", got)
+	}
+
+	// Other hooks on the same events aren't wt's.
+	settings := `{"hooks": {"WorktreeCreate": [{"hooks": [{"type": "command", "command": "notify-send created"}]}]}}`
+	if err := os.WriteFile(filepath.Join(dir, settingsFile), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if commands, err = HookCommands(dir); err != nil || len(commands) != 0 {
+		t.Errorf("HookCommands with another tool's hook = %v, %v; want none", commands, err)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Compose runs a docker compose project per worktree, named so worktrees
+// don't share containers, networks, or volumes.
+type Compose struct {
+	// ProjectName is the compose project name; template variables such as
+	// ${WORKTREE_ID} are expanded. Defaults to <project dir>-${WORKTREE_ID}.
+	ProjectName string `yaml:"project_name,omitempty"`
+	// Files are passed with -f, relative to the worktree. Unset lets compose
+	// find its default file.
+	Files    []string `yaml:"files,omitempty"`
+	Profiles []string `yaml:"profiles,omitempty"`
+	// Up and Down start the project before setup hooks and stop it after
+	// teardown hooks. Both default to on.
+	Up   *bool `yaml:"up,omitempty"`
+	Down *bool `yaml:"down,omitempty"`
+	// Volumes removes the project's named volumes on down.
+	Volumes bool `yaml:"volumes,omitempty"`
+}
+
+// UpEnabled reports whether setup starts the compose project.
+func (c *Compose) UpEnabled() bool {
+	return c != nil && (c.Up == nil || *c.Up)
+}
+
+// DownEnabled reports whether teardown stops the compose project.
+func (c *Compose) DownEnabled() bool {
+	return c != nil && (c.Down == nil || *c.Down)
+}
+
+// HasSetup reports whether anything runs during setup: setup hooks or a
+// compose project to start.
+func (c *Config) HasSetup() bool {
+	return c.SetupSteps() > 0
+}
+
+// SetupSteps counts what setup runs: each setup hook, plus one for starting
+// the compose project.
+func (c *Config) SetupSteps() int {
+	n := len(c.Setup) + len(c.ParallelSetup)
+	if c.Compose.UpEnabled() {
+		n++
+	}
+	return n
+}
+
+func (c *Compose) validate() error {
+	if c == nil {
+		return nil
+	}
+	for _, f := range c.Files {
+		if !filepath.IsLocal(f) {
+			return fmt.Errorf("compose.files: %s must be a relative path inside the worktree", f)
+		}
+	}
+	return nil
+}
+
+// writeCompose renders the compose block, as {} when every field has its
+// default so the block still enables compose.
+func writeCompose(b *strings.Builder, c *Compose) {
+	if c.ProjectName == "" && len(c.Files) == 0 && len(c.Profiles) == 0 && c.Up == nil && c.Down == nil && !c.Volumes {
+		b.WriteString("compose: {}\n")
+		return
+	}
+	b.WriteString("compose:\n")
+	if c.ProjectName != "" {
+		fmt.Fprintf(b, "  project_name: %s\n", yamlQuote(c.ProjectName))
+	}
+	if len(c.Files) > 0 {
+		fmt.Fprintf(b, "  files: %s\n", flowList(c.Files))
+	}
+	if len(c.Profiles) > 0 {
+		fmt.Fprintf(b, "  profiles: %s\n", flowList(c.Profiles))
+	}
+	if c.Up != nil {
+		fmt.Fprintf(b, "  up: %t\n", *c.Up)
+	}
+	if c.Down != nil {
+		fmt.Fprintf(b, "  down: %t\n", *c.Down)
+	}
+	if c.Volumes {
+		b.WriteString("  volumes: true\n")
+	}
+}
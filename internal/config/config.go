@@ -4,8 +4,10 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/bkildow/wt-cli/internal/disk"
@@ -26,23 +28,203 @@ var (
 )
 
 type Config struct {
-	Version          int      `yaml:"version"`
-	GitDir           string   `yaml:"git_dir"`
-	WorktreeDir      string   `yaml:"worktree_dir"`
-	SharedDir        string   `yaml:"shared_dir"`
-	MainBranch       string   `yaml:"main_branch,omitempty"`
-	Setup            []string `yaml:"setup,omitempty"`
-	ParallelSetup    []string `yaml:"parallel_setup,omitempty"`
-	Teardown         []string `yaml:"teardown,omitempty"`
-	ParallelTeardown []string `yaml:"parallel_teardown,omitempty"`
-	BackgroundSetup  bool     `yaml:"background_setup,omitempty"`
-	Editor           string   `yaml:"editor,omitempty"`
+	Version          int    `yaml:"version"`
+	GitDir           string `yaml:"git_dir"`
+	WorktreeDir      string `yaml:"worktree_dir"`
+	SharedDir        string `yaml:"shared_dir"`
+	MainBranch       string `yaml:"main_branch,omitempty"`
+	Setup            []Hook `yaml:"setup,omitempty"`
+	ParallelSetup    []Hook `yaml:"parallel_setup,omitempty"`
+	Teardown         []Hook `yaml:"teardown,omitempty"`
+	ParallelTeardown []Hook `yaml:"parallel_teardown,omitempty"`
+
+	// Lifecycle hooks around the core commands. PreAdd and PreRemove can
+	// veto the operation by failing; PreAdd and PostRemove run in the
+	// project root since the worktree directory does not exist then.
+	PreAdd     []Hook `yaml:"pre_add,omitempty"`
+	PostApply  []Hook `yaml:"post_apply,omitempty"`
+	PostSync   []Hook `yaml:"post_sync,omitempty"`
+	PreRemove  []Hook `yaml:"pre_remove,omitempty"`
+	PostRemove []Hook `yaml:"post_remove,omitempty"`
+	OnEnter    []Hook `yaml:"on_enter,omitempty"`
+
+	// Tasks are named commands run on demand with 'wt run'.
+	Tasks map[string]Hook `yaml:"tasks,omitempty"`
+
+	// Compose, when set, gives each worktree its own docker compose project.
+	Compose *Compose `yaml:"compose,omitempty"`
+
+	// ResourceCleanup maps a resource kind registered with 'wt resource
+	// register' to the command that removes one, e.g. a docker volume.
+	ResourceCleanup map[string]Hook `yaml:"resource_cleanup,omitempty"`
+
+	// ApplyConflict decides what apply does with a copied file the worktree
+	// has changed. Defaults to ConflictOverwrite.
+	ApplyConflict ConflictPolicy `yaml:"apply_conflict,omitempty"`
+
+	// CopyPreserve lists what copied files keep from shared/copy besides
+	// content and mode bits (see CopyPreserveItems). Unset keeps all of
+	// them; [none] keeps none.
+	CopyPreserve []string `yaml:"copy_preserve,omitempty"`
+
+	// CopyMode is how copied files get their content (see CopyModes).
+	// CopyModePaths overrides it for worktree paths matching a glob, as in
+	// shared rules; the longest matching pattern wins. Unset means CopyAuto.
+	CopyMode      CopyMode            `yaml:"copy_mode,omitempty"`
+	CopyModePaths map[string]CopyMode `yaml:"copy_mode_paths,omitempty"`
+
+	// SymlinkStyle is whether shared symlinks point at the shared dir by a
+	// relative or an absolute path. Unset means SymlinkAbsolute, which
+	// projects created before the option was added rely on; new projects
+	// are written with SymlinkRelative.
+	SymlinkStyle SymlinkStyle `yaml:"symlink_style,omitempty"`
+
+	// Overlays layer extra shared files over the base for matching
+	// branches, in order.
+	Overlays []Overlay `yaml:"overlays,omitempty"`
+
+	// Shared holds glob rules that replace the shared/copy and
+	// shared/symlink directory convention.
+	Shared Shared `yaml:"shared,omitempty"`
+
+	// Secret sources for ${secret:NAME} in templates, consulted in this
+	// order. SecretsFile is an age (.age) or GPG (.gpg, .asc) encrypted
+	// dotenv file relative to the project root; SecretsCommand prints a JSON
+	// object; SecretsDotenv is a plain dotenv file kept outside the repo.
+	SecretsCommand  string `yaml:"secrets_command,omitempty"`
+	SecretsFile     string `yaml:"secrets_file,omitempty"`
+	SecretsIdentity string `yaml:"secrets_identity,omitempty"` // age identity; defaults to ~/.config/age/keys.txt
+	SecretsDotenv   string `yaml:"secrets_dotenv,omitempty"`   // relative to the project root unless absolute or ~/
+
+	BackgroundSetup bool   `yaml:"background_setup,omitempty"`
+	Editor          string `yaml:"editor,omitempty"`
+
+	// PTY runs hooks under a pseudo-terminal so tools
+	// keep their progress bars and colors. Hooks can override it with pty.
+	PTY bool `yaml:"pty,omitempty"`
+
+	// Shell is the interpreter hook commands run under, e.g.
+	// "bash -euo pipefail -c". Defaults to "sh -c".
+	Shell string `yaml:"shell,omitempty"`
+
+	// OnSetupComplete and OnSetupFailed run when background setup finishes,
+	// alongside any built-in notifiers enabled in Notify.
+	OnSetupComplete []string `yaml:"on_setup_complete,omitempty"`
+	OnSetupFailed   []string `yaml:"on_setup_failed,omitempty"`
+	Notify          Notify   `yaml:"notify,omitempty"`
 
 	// DiskWarn gates the low-disk-space warning. It is a pointer because the
 	// warning defaults to on, so the zero value cannot mean "disabled".
 	DiskWarn        *bool `yaml:"disk_warn,omitempty"`
 	DiskWarnPercent int   `yaml:"disk_warn_percent,omitempty"`
 	DiskWarnGB      int   `yaml:"disk_warn_gb,omitempty"`
+
+	// DiskMinFreeGB and DiskMinFreePercent are a hard floor: wt add refuses
+	// to create a worktree that would leave less free space. Zero disables
+	// a bound, and both are off by default.
+	DiskMinFreeGB      int `yaml:"disk_min_free_gb,omitempty"`
+	DiskMinFreePercent int `yaml:"disk_min_free_percent,omitempty"`
+}
+
+// ConflictPolicy decides what apply does when a copied file already exists
+// in the worktree with different content.
+type ConflictPolicy string
+
+const (
+	ConflictOverwrite    ConflictPolicy = "overwrite"     // replace the file
+	ConflictSkipModified ConflictPolicy = "skip-modified" // leave the file alone
+	ConflictPrompt       ConflictPolicy = "prompt"        // ask, skipping when not interactive
+	ConflictBackup       ConflictPolicy = "backup"        // move the file aside, then replace it
+)
+
+// ConflictPolicies lists the valid policies, for help text and errors.
+var ConflictPolicies = []ConflictPolicy{ConflictOverwrite, ConflictSkipModified, ConflictPrompt, ConflictBackup}
+
+// ParseConflictPolicy validates s as a policy name. The empty string means
+// the default.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	if s == "" {
+		return ConflictOverwrite, nil
+	}
+	p := ConflictPolicy(s)
+	if !slices.Contains(ConflictPolicies, p) {
+		return "", fmt.Errorf("unknown apply conflict policy %q (want overwrite, skip-modified, prompt, or backup)", s)
+	}
+	return p, nil
+}
+
+// ApplyConflictOrDefault returns the configured conflict policy, falling
+// back to ConflictOverwrite.
+func (c *Config) ApplyConflictOrDefault() ConflictPolicy {
+	if c.ApplyConflict == "" {
+		return ConflictOverwrite
+	}
+	return c.ApplyConflict
+}
+
+// SymlinkStyle is how shared symlinks refer to their target.
+type SymlinkStyle string
+
+const (
+	SymlinkRelative SymlinkStyle = "relative" // survives moving or mounting the project elsewhere
+	SymlinkAbsolute SymlinkStyle = "absolute"
+)
+
+// ParseSymlinkStyle validates a symlink_style value; empty means
+// SymlinkAbsolute.
+func ParseSymlinkStyle(s string) (SymlinkStyle, error) {
+	switch SymlinkStyle(s) {
+	case "", SymlinkAbsolute:
+		return SymlinkAbsolute, nil
+	case SymlinkRelative:
+		return SymlinkRelative, nil
+	}
+	return "", fmt.Errorf("unknown symlink_style %q (want relative or absolute)", s)
+}
+
+// RelativeSymlinks reports whether shared symlinks use relative targets.
+func (c *Config) RelativeSymlinks() bool {
+	return c.SymlinkStyle == SymlinkRelative
+}
+
+// CopyPreserveItems are the values copy_preserve accepts, besides "none".
+var CopyPreserveItems = []string{"times", "xattrs", "owner", "symlinks"}
+
+// Preserves reports whether copied files keep item, one of
+// CopyPreserveItems.
+func (c *Config) Preserves(item string) bool {
+	if c.CopyPreserve == nil {
+		return true
+	}
+	return slices.Contains(c.CopyPreserve, item)
+}
+
+func validateCopyPreserve(items []string) error {
+	for _, item := range items {
+		if item == "none" {
+			if len(items) > 1 {
+				return fmt.Errorf("copy_preserve: none cannot be combined with other values")
+			}
+			continue
+		}
+		if !slices.Contains(CopyPreserveItems, item) {
+			return fmt.Errorf("copy_preserve: unknown value %q (want %s, or none)", item, strings.Join(CopyPreserveItems, ", "))
+		}
+	}
+	return nil
+}
+
+// Notify enables the built-in notifiers for background setup completion.
+type Notify struct {
+	Terminal bool   `yaml:"terminal,omitempty"` // bell + OSC 9 on the terminal that started setup
+	Desktop  bool   `yaml:"desktop,omitempty"`  // notify-send (osascript on macOS)
+	FIFO     string `yaml:"fifo,omitempty"`     // named pipe that receives a JSON line per event
+	Socket   string `yaml:"socket,omitempty"`   // Unix socket that receives a JSON line per event
+}
+
+// IsZero reports whether no built-in notifier is enabled.
+func (n Notify) IsZero() bool {
+	return !n.Terminal && !n.Desktop && n.FIFO == "" && n.Socket == ""
 }
 
 // DiskThreshold returns the configured low-disk thresholds, or nil when disk
@@ -70,6 +252,34 @@ func (c *Config) DiskThreshold() *disk.Threshold {
 	return &t
 }
 
+// DiskFloor returns the free space wt add must leave, or nil when neither
+// disk_min_free bound is set.
+func (c *Config) DiskFloor() *disk.Threshold {
+	if c.DiskMinFreeGB <= 0 && c.DiskMinFreePercent <= 0 {
+		return nil
+	}
+	t := disk.Threshold{Percent: float64(max(c.DiskMinFreePercent, 0))}
+	if c.DiskMinFreeGB > 0 {
+		t.Bytes = uint64(c.DiskMinFreeGB) * disk.BytesPerGB
+	}
+	return &t
+}
+
+func validateDiskFloor(gb, percent int) error {
+	if gb < 0 {
+		return fmt.Errorf("disk_min_free_gb: %d is negative (use 0 to disable)", gb)
+	}
+	if percent < 0 || percent >= 100 {
+		return fmt.Errorf("disk_min_free_percent: %d is not between 0 and 99", percent)
+	}
+	return nil
+}
+
+// TaskNames returns the configured task names in sorted order.
+func (c *Config) TaskNames() []string {
+	return slices.Sorted(maps.Keys(c.Tasks))
+}
+
 // MainBranchOrDefault returns the configured main branch, falling back to DefaultMainBranch.
 func (c *Config) MainBranchOrDefault() string {
 	if c.MainBranch != "" {
@@ -87,6 +297,15 @@ func DefaultConfig() Config {
 	}
 }
 
+// NewProjectConfig returns the config wt init and wt clone write: the
+// defaults, plus the settings new projects get that existing ones only
+// have when they opt in.
+func NewProjectConfig() Config {
+	cfg := DefaultConfig()
+	cfg.SymlinkStyle = SymlinkRelative
+	return cfg
+}
+
 func Load(projectRoot string) (*Config, error) {
 	path := filepath.Join(projectRoot, ConfigFileName)
 	data, err := os.ReadFile(path)
@@ -101,6 +320,33 @@ func Load(projectRoot string) (*Config, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, errors.Join(ErrInvalidConfig, err)
 	}
+	if _, err := ParseConflictPolicy(string(cfg.ApplyConflict)); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := validateCopyPreserve(cfg.CopyPreserve); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := validateCopyModes(cfg.CopyMode, cfg.CopyModePaths); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if _, err := ParseSymlinkStyle(string(cfg.SymlinkStyle)); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := validateDiskFloor(cfg.DiskMinFreeGB, cfg.DiskMinFreePercent); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := cfg.Compose.validate(); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := validateOverlays(cfg.Overlays); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := validateSharedRules(cfg.Shared.Rules); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
+	if err := cfg.validateSecrets(); err != nil {
+		return nil, errors.Join(ErrInvalidConfig, err)
+	}
 
 	return &cfg, nil
 }
@@ -173,6 +419,108 @@ func renderAnnotatedConfig(cfg *Config) string {
 		b.WriteString("# editor: cursor\n")
 	}
 
+	b.WriteString("\n# What 'wt apply' does with a copied file that differs from shared/copy:\n")
+	b.WriteString("# overwrite (default), skip-modified, prompt, or backup (moves it to <file>.bak)\n")
+	if cfg != nil && cfg.ApplyConflict != "" {
+		fmt.Fprintf(&b, "apply_conflict: %s\n", cfg.ApplyConflict)
+	} else {
+		b.WriteString("# apply_conflict: overwrite\n")
+	}
+
+	b.WriteString("\n# What copied files keep from shared/copy besides content and mode (default: all):\n")
+	b.WriteString("# times (mtime/atime, so build tools don't rebuild), xattrs, owner (when permitted),\n")
+	b.WriteString("# symlinks (copied as links instead of followed). Use [none] for plain copies.\n")
+	if cfg != nil && cfg.CopyPreserve != nil {
+		fmt.Fprintf(&b, "copy_preserve: %s\n", flowList(cfg.CopyPreserve))
+	} else {
+		b.WriteString("# copy_preserve: [times, xattrs, owner, symlinks]\n")
+	}
+
+	b.WriteString("\n# Shared symlinks point at the shared dir by a relative path, so the project still\n")
+	b.WriteString("# works when moved or mounted elsewhere (e.g. in a container), or by an absolute one.\n")
+	b.WriteString("# Unset means absolute, as in projects created before this option; 'wt repair'\n")
+	b.WriteString("# rewrites existing absolute links after switching to relative.\n")
+	switch {
+	case cfg == nil:
+		b.WriteString("symlink_style: relative\n")
+	case cfg.SymlinkStyle != "":
+		fmt.Fprintf(&b, "symlink_style: %s\n", cfg.SymlinkStyle)
+	default:
+		b.WriteString("# symlink_style: relative\n")
+	}
+	b.WriteString("\n# How copied files get their content: auto (reflink where the filesystem supports it,\n")
+	b.WriteString("# else copy), reflink (fail rather than copy), hardlink (share the shared file itself;\n")
+	b.WriteString("# for read-only trees such as toolchains), or copy (always independent). copy_mode_paths\n")
+	b.WriteString("# sets the mode for worktree paths matching a glob; the longest match wins.\n")
+	if cfg != nil && cfg.CopyMode != "" {
+		fmt.Fprintf(&b, "copy_mode: %s\n", cfg.CopyMode)
+	} else {
+		b.WriteString("# copy_mode: auto\n")
+	}
+	if cfg != nil && len(cfg.CopyModePaths) > 0 {
+		writeCopyModePaths(&b, cfg.CopyModePaths)
+	} else {
+		b.WriteString("# copy_mode_paths:\n#   .toolchains: hardlink\n")
+	}
+
+	b.WriteString("\n# Overlays: extra shared files for matching branches, layered over shared/ in order.\n")
+	b.WriteString("# Files live in <shared_dir>/overlays/<name>/copy and .../symlink; a file in a later\n")
+	b.WriteString("# layer replaces the same path from earlier ones. exclude drops paths supplied below.\n")
+	if cfg != nil && len(cfg.Overlays) > 0 {
+		writeOverlays(&b, cfg.Overlays)
+	} else {
+		b.WriteString("# overlays:\n")
+		b.WriteString("#   - name: release\n")
+		b.WriteString("#     branches: [\"release/*\"]\n")
+		b.WriteString("#   - name: hotfix\n")
+		b.WriteString("#     branches: [\"hotfix/*\"]\n")
+		b.WriteString("#     exclude: [node_modules, .cache]\n")
+	}
+
+	b.WriteString("\n# Rules for placing shared files, replacing the copy/ and symlink/ convention.\n")
+	b.WriteString("# Globs are relative to the shared dir (** matches any depth); the first matching\n")
+	b.WriteString("# rule wins. mode: copy, symlink, template, or hardlink. dest: worktree path.\n")
+	b.WriteString("# The default rules are [copy/**] mode copy and [symlink/*] mode symlink.\n")
+	if cfg != nil && cfg.Shared.HasRules() {
+		writeSharedRules(&b, cfg.Shared.Rules)
+	} else {
+		b.WriteString("# shared:\n")
+		b.WriteString("#   rules:\n")
+		b.WriteString("#     - include: [env/web.env]\n")
+		b.WriteString("#       mode: template\n")
+		b.WriteString("#       dest: apps/web/.env\n")
+		b.WriteString("#     - include: [\"copy/**\"]\n")
+		b.WriteString("#       mode: copy\n")
+		b.WriteString("#     - include: [\"symlink/*\"]\n")
+		b.WriteString("#       exclude: [symlink/node_modules]\n")
+		b.WriteString("#       mode: symlink\n")
+	}
+
+	b.WriteString("\n# Sources for ${secret:NAME} in templates, consulted in this order. Secret values\n")
+	b.WriteString("# are written to worktrees but never shown in logs, dry runs, or diffs.\n")
+	b.WriteString("# secrets_command prints a JSON object; secrets_file is an age- or GPG-encrypted\n")
+	b.WriteString("# dotenv file (.age, .gpg, .asc); secrets_dotenv is a plain dotenv file outside the repo.\n")
+	if cfg != nil && cfg.SecretsCommand != "" {
+		fmt.Fprintf(&b, "secrets_command: %s\n", yamlQuote(cfg.SecretsCommand))
+	} else {
+		b.WriteString("# secrets_command: vault kv get -format=json -field=data secret/myapp\n")
+	}
+	if cfg != nil && cfg.SecretsFile != "" {
+		fmt.Fprintf(&b, "secrets_file: %s\n", yamlQuote(cfg.SecretsFile))
+	} else {
+		b.WriteString("# secrets_file: secrets.env.age\n")
+	}
+	if cfg != nil && cfg.SecretsIdentity != "" {
+		fmt.Fprintf(&b, "secrets_identity: %s\n", yamlQuote(cfg.SecretsIdentity))
+	} else {
+		b.WriteString("# secrets_identity: ~/.config/age/keys.txt\n")
+	}
+	if cfg != nil && cfg.SecretsDotenv != "" {
+		fmt.Fprintf(&b, "secrets_dotenv: %s\n", yamlQuote(cfg.SecretsDotenv))
+	} else {
+		b.WriteString("# secrets_dotenv: ~/.config/myapp/secrets.env\n")
+	}
+
 	b.WriteString("\n# Run setup hooks in the background (default: false)\n")
 	b.WriteString("# Override per-command with --background or --foreground\n")
 	if cfg != nil && cfg.BackgroundSetup {
@@ -181,6 +529,51 @@ func renderAnnotatedConfig(cfg *Config) string {
 		b.WriteString("# background_setup: false\n")
 	}
 
+	b.WriteString("\n# Commands to run when background setup finishes successfully / with errors\n")
+	b.WriteString("# Run in the worktree with WT_SETUP_STATUS, WT_BRANCH, WT_WORKTREE_PATH,\n")
+	b.WriteString("# WT_SETUP_ELAPSED, WT_SETUP_ERROR, and WT_NOTIFY_MESSAGE set\n")
+	if cfg != nil && len(cfg.OnSetupComplete) > 0 {
+		b.WriteString("on_setup_complete:\n")
+		for _, s := range cfg.OnSetupComplete {
+			fmt.Fprintf(&b, "  - %s\n", yamlQuote(s))
+		}
+	} else {
+		b.WriteString("# on_setup_complete:\n")
+		b.WriteString("#   - say \"$WT_NOTIFY_MESSAGE\"\n")
+	}
+	if cfg != nil && len(cfg.OnSetupFailed) > 0 {
+		b.WriteString("on_setup_failed:\n")
+		for _, s := range cfg.OnSetupFailed {
+			fmt.Fprintf(&b, "  - %s\n", yamlQuote(s))
+		}
+	} else {
+		b.WriteString("# on_setup_failed:\n")
+		b.WriteString("#   - tail -n 50 .wt-setup.log\n")
+	}
+
+	b.WriteString("\n# Built-in notifiers for background setup completion\n")
+	if cfg != nil && !cfg.Notify.IsZero() {
+		b.WriteString("notify:\n")
+		if cfg.Notify.Terminal {
+			b.WriteString("  terminal: true\n")
+		}
+		if cfg.Notify.Desktop {
+			b.WriteString("  desktop: true\n")
+		}
+		if cfg.Notify.FIFO != "" {
+			fmt.Fprintf(&b, "  fifo: %s\n", yamlQuote(cfg.Notify.FIFO))
+		}
+		if cfg.Notify.Socket != "" {
+			fmt.Fprintf(&b, "  socket: %s\n", yamlQuote(cfg.Notify.Socket))
+		}
+	} else {
+		b.WriteString("# notify:\n")
+		b.WriteString("#   terminal: true          # bell + OSC 9 on the terminal that ran 'wt add'\n")
+		b.WriteString("#   desktop: true           # notify-send (osascript on macOS)\n")
+		b.WriteString("#   fifo: /tmp/wt-notify    # write a JSON line to a named pipe\n")
+		b.WriteString("#   socket: /tmp/wt.sock    # write a JSON line to a Unix socket\n")
+	}
+
 	b.WriteString("\n# Warn when free disk space runs low and suggest 'wt prune' (default: on)\n")
 	b.WriteString("# Checked on 'wt add' and 'wt status'. Set WT_NO_DISK_WARN=1 to silence per-command.\n")
 	if cfg != nil && cfg.DiskWarn != nil && !*cfg.DiskWarn {
@@ -203,12 +596,41 @@ func renderAnnotatedConfig(cfg *Config) string {
 		fmt.Fprintf(&b, "# disk_warn_gb: %d\n", disk.DefaultWarnGB)
 	}
 
+	b.WriteString("\n# Refuse 'wt add' when the new worktree would leave less free space than\n")
+	b.WriteString("# this (default: off). The new worktree is estimated from the existing ones.\n")
+	if cfg != nil && cfg.DiskMinFreeGB != 0 {
+		fmt.Fprintf(&b, "disk_min_free_gb: %d\n", cfg.DiskMinFreeGB)
+	} else {
+		b.WriteString("# disk_min_free_gb: 5\n")
+	}
+	if cfg != nil && cfg.DiskMinFreePercent != 0 {
+		fmt.Fprintf(&b, "disk_min_free_percent: %d\n", cfg.DiskMinFreePercent)
+	} else {
+		b.WriteString("# disk_min_free_percent: 5\n")
+	}
+
+	b.WriteString("\n# Interpreter for hook commands; the command is passed as the last argument\n")
+	b.WriteString("# (default: sh -c). Override per hook with the mapping form: - run: ... / shell: zsh -c\n")
+	b.WriteString("# Hooks can also be executables in <shared_dir>/hooks/: - script: seed-db.sh\n")
+	b.WriteString("# Every hook gets WT_HOOK, WT_PROJECT_ROOT, WT_WORKTREE_PATH, and WT_BRANCH set\n")
+	if cfg != nil && cfg.Shell != "" {
+		fmt.Fprintf(&b, "shell: %s\n", yamlQuote(cfg.Shell))
+	} else {
+		b.WriteString("# shell: bash -euo pipefail -c\n")
+	}
+
+	b.WriteString("\n# Run hooks under a pseudo-terminal (default: false)\n")
+	b.WriteString("# Keeps progress bars and colors from tools like npm, composer, and docker.\n")
+	b.WriteString("# Override per hook with the mapping form: - run: npm install / pty: false\n")
+	if cfg != nil && cfg.PTY {
+		b.WriteString("pty: true\n")
+	} else {
+		b.WriteString("# pty: true\n")
+	}
+
 	b.WriteString("\n# Commands to run after creating a new worktree\n")
 	if cfg != nil && len(cfg.Setup) > 0 {
-		b.WriteString("setup:\n")
-		for _, s := range cfg.Setup {
-			fmt.Fprintf(&b, "  - %s\n", yamlQuote(s))
-		}
+		writeHooks(&b, "setup", cfg.Setup)
 	} else {
 		b.WriteString("# setup:\n")
 		b.WriteString("#   - npm install\n")
@@ -218,10 +640,7 @@ func renderAnnotatedConfig(cfg *Config) string {
 	b.WriteString("\n# Commands to run in parallel after creating a new worktree\n")
 	b.WriteString("# These run concurrently after serial setup hooks complete\n")
 	if cfg != nil && len(cfg.ParallelSetup) > 0 {
-		b.WriteString("parallel_setup:\n")
-		for _, s := range cfg.ParallelSetup {
-			fmt.Fprintf(&b, "  - %s\n", yamlQuote(s))
-		}
+		writeHooks(&b, "parallel_setup", cfg.ParallelSetup)
 	} else {
 		b.WriteString("# parallel_setup:\n")
 		b.WriteString("#   - npm install\n")
@@ -230,10 +649,7 @@ func renderAnnotatedConfig(cfg *Config) string {
 
 	b.WriteString("\n# Commands to run before removing a worktree\n")
 	if cfg != nil && len(cfg.Teardown) > 0 {
-		b.WriteString("teardown:\n")
-		for _, t := range cfg.Teardown {
-			fmt.Fprintf(&b, "  - %s\n", yamlQuote(t))
-		}
+		writeHooks(&b, "teardown", cfg.Teardown)
 	} else {
 		b.WriteString("# teardown:\n")
 		b.WriteString("#   - docker compose down\n")
@@ -242,19 +658,124 @@ func renderAnnotatedConfig(cfg *Config) string {
 	b.WriteString("\n# Commands to run in parallel before removing a worktree\n")
 	b.WriteString("# These run concurrently after serial teardown hooks complete\n")
 	if cfg != nil && len(cfg.ParallelTeardown) > 0 {
-		b.WriteString("parallel_teardown:\n")
-		for _, t := range cfg.ParallelTeardown {
-			fmt.Fprintf(&b, "  - %s\n", yamlQuote(t))
-		}
+		writeHooks(&b, "parallel_teardown", cfg.ParallelTeardown)
 	} else {
 		b.WriteString("# parallel_teardown:\n")
 		b.WriteString("#   - docker compose down\n")
 		b.WriteString("#   - make clean\n")
 	}
 
+	// Lifecycle hooks share one layout; lc is empty when rendering defaults.
+	var lc Config
+	if cfg != nil {
+		lc = *cfg
+	}
+	for _, lh := range []struct {
+		key, doc, example string
+		hooks             []Hook
+	}{
+		{"pre_add", "Commands to run before creating a worktree (in the project root; a failure aborts 'wt add')", "./scripts/check-vpn.sh", lc.PreAdd},
+		{"post_apply", "Commands to run after shared files are applied to a worktree", "direnv allow", lc.PostApply},
+		{"post_sync", "Commands to run after 'wt sync' pulls new commits into a worktree", "make migrate", lc.PostSync},
+		{"pre_remove", "Commands to run before removing a worktree (a failure aborts the removal)", "./scripts/check-unpushed.sh", lc.PreRemove},
+		{"post_remove", "Commands to run after a worktree is removed (in the project root)", "docker volume prune -f", lc.PostRemove},
+		{"on_enter", "Commands to run when the shell wrapper enters a worktree with 'wt cd'", "git status --short", lc.OnEnter},
+	} {
+		fmt.Fprintf(&b, "\n# %s\n", lh.doc)
+		if len(lh.hooks) > 0 {
+			writeHooks(&b, lh.key, lh.hooks)
+		} else {
+			fmt.Fprintf(&b, "# %s:\n#   - %s\n", lh.key, lh.example)
+		}
+	}
+
+	b.WriteString("\n# Named commands to run in a worktree with 'wt run <task> [name]'\n")
+	if len(lc.Tasks) > 0 {
+		writeHookMap(&b, "tasks", lc.Tasks)
+	} else {
+		b.WriteString("# tasks:\n")
+		b.WriteString("#   test: go test ./...\n")
+		b.WriteString("#   dev:\n")
+		b.WriteString("#     run: npm run dev\n")
+		b.WriteString("#     pty: true\n")
+	}
+
+	b.WriteString("\n# Give each worktree its own docker compose project: 'docker compose up -d' runs\n")
+	b.WriteString("# before setup hooks and 'down' after teardown hooks. The project name is exported\n")
+	b.WriteString("# to hooks as COMPOSE_PROJECT_NAME and to templates as ${COMPOSE_PROJECT_NAME}\n")
+	if lc.Compose != nil {
+		writeCompose(&b, lc.Compose)
+	} else {
+		b.WriteString("# compose:\n")
+		b.WriteString("#   project_name: myapp-${WORKTREE_ID}   # default: <project dir>-${WORKTREE_ID}\n")
+		b.WriteString("#   files: [compose.yml, compose.dev.yml]\n")
+		b.WriteString("#   profiles: [db]\n")
+		b.WriteString("#   up: true                             # start on setup (default: true)\n")
+		b.WriteString("#   down: true                           # stop on teardown (default: true)\n")
+		b.WriteString("#   volumes: false                       # also remove named volumes on down\n")
+	}
+	b.WriteString("\n# Commands that remove resources hooks registered with 'wt resource register <kind> <id>',\n")
+	b.WriteString("# by kind. Run by 'wt remove', 'wt prune', and 'wt resources clean' in the project root\n")
+	b.WriteString("# with WT_RESOURCE_KIND and WT_RESOURCE_ID set\n")
+	if len(lc.ResourceCleanup) > 0 {
+		writeHookMap(&b, "resource_cleanup", lc.ResourceCleanup)
+	} else {
+		b.WriteString("# resource_cleanup:\n")
+		b.WriteString("#   docker-volume: docker volume rm \"$WT_RESOURCE_ID\"\n")
+		b.WriteString("#   database: dropdb --if-exists \"$WT_RESOURCE_ID\"\n")
+	}
+
 	return b.String()
 }
 
+// writeHooks renders a hook list, using the plain string form for hooks
+// without options.
+func writeHooks(b *strings.Builder, key string, hooks []Hook) {
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, h := range hooks {
+		if h.isPlain() {
+			fmt.Fprintf(b, "  - %s\n", yamlQuote(h.Run))
+			continue
+		}
+		if h.Script != "" {
+			fmt.Fprintf(b, "  - script: %s\n", yamlQuote(h.Script))
+		} else {
+			fmt.Fprintf(b, "  - run: %s\n", yamlQuote(h.Run))
+		}
+		writeHookOptions(b, h)
+	}
+}
+
+// writeHookMap renders a map of named hooks, such as tasks, in name order,
+// using the plain string form for hooks without options.
+func writeHookMap(b *strings.Builder, key string, hooks map[string]Hook) {
+	fmt.Fprintf(b, "%s:\n", key)
+	for _, name := range slices.Sorted(maps.Keys(hooks)) {
+		h := hooks[name]
+		if h.isPlain() {
+			fmt.Fprintf(b, "  %s: %s\n", yamlQuote(name), yamlQuote(h.Run))
+			continue
+		}
+		fmt.Fprintf(b, "  %s:\n", yamlQuote(name))
+		if h.Script != "" {
+			fmt.Fprintf(b, "    script: %s\n", yamlQuote(h.Script))
+		} else {
+			fmt.Fprintf(b, "    run: %s\n", yamlQuote(h.Run))
+		}
+		writeHookOptions(b, h)
+	}
+}
+
+// writeHookOptions renders the per-hook options of a mapping-form hook.
+func writeHookOptions(b *strings.Builder, h Hook) {
+	if h.Shell != "" {
+		fmt.Fprintf(b, "    shell: %s\n", yamlQuote(h.Shell))
+	}
+	if h.PTY != nil {
+		fmt.Fprintf(b, "    pty: %t\n", *h.PTY)
+	}
+}
+
 // yamlQuote wraps a string in double quotes if it contains characters
 // that need quoting in YAML, otherwise returns it bare.
 func yamlQuote(s string) string {
@@ -2,8 +2,11 @@ package config
 
 import (
 	"errors"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -44,16 +47,16 @@ editor: cursor
 	if cfg.WorktreeDir != "trees" {
 		t.Errorf("worktree_dir = %q, want %q", cfg.WorktreeDir, "trees")
 	}
-	if len(cfg.Setup) != 1 || cfg.Setup[0] != "npm install" {
+	if len(cfg.Setup) != 1 || cfg.Setup[0].Run != "npm install" {
 		t.Errorf("setup = %v, want [npm install]", cfg.Setup)
 	}
-	if len(cfg.ParallelSetup) != 2 || cfg.ParallelSetup[0] != "bundle install" {
+	if len(cfg.ParallelSetup) != 2 || cfg.ParallelSetup[0].Run != "bundle install" {
 		t.Errorf("parallel_setup = %v, want [bundle install, pip install -r requirements.txt]", cfg.ParallelSetup)
 	}
-	if len(cfg.Teardown) != 1 || cfg.Teardown[0] != "docker compose down" {
+	if len(cfg.Teardown) != 1 || cfg.Teardown[0].Run != "docker compose down" {
 		t.Errorf("teardown = %v, want [docker compose down]", cfg.Teardown)
 	}
-	if len(cfg.ParallelTeardown) != 1 || cfg.ParallelTeardown[0] != "make clean" {
+	if len(cfg.ParallelTeardown) != 1 || cfg.ParallelTeardown[0].Run != "make clean" {
 		t.Errorf("parallel_teardown = %v, want [make clean]", cfg.ParallelTeardown)
 	}
 	if cfg.Editor != "cursor" {
@@ -113,8 +116,8 @@ func TestSaveAndLoadRoundTrip(t *testing.T) {
 		Version:     1,
 		GitDir:      ".bare",
 		WorktreeDir: "trees",
-		Setup:       []string{"make build", "make test"},
-		Teardown:    []string{"make clean"},
+		Setup:       []Hook{{Run: "make build"}, {Run: "make test"}},
+		Teardown:    []Hook{{Run: "make clean"}},
 		Editor:      "nvim",
 	}
 
@@ -142,8 +145,8 @@ func TestSaveAndLoadRoundTrip(t *testing.T) {
 	if len(loaded.Teardown) != len(original.Teardown) {
 		t.Errorf("teardown len = %d, want %d", len(loaded.Teardown), len(original.Teardown))
 	}
-	if loaded.Teardown[0] != "make clean" {
-		t.Errorf("teardown[0] = %q, want %q", loaded.Teardown[0], "make clean")
+	if loaded.Teardown[0].Run != "make clean" {
+		t.Errorf("teardown[0] = %q, want %q", loaded.Teardown[0].Run, "make clean")
 	}
 	if loaded.Editor != original.Editor {
 		t.Errorf("editor = %q, want %q", loaded.Editor, original.Editor)
@@ -250,8 +253,8 @@ func TestWriteAnnotatedWithValues(t *testing.T) {
 		GitDir:      ".bare",
 		WorktreeDir: "trees",
 		MainBranch:  "develop",
-		Setup:       []string{"npm install", "cp .env.example .env"},
-		Teardown:    []string{"docker compose down"},
+		Setup:       []Hook{{Run: "npm install"}, {Run: "cp .env.example .env"}},
+		Teardown:    []Hook{{Run: "docker compose down"}},
 		Editor:      "cursor",
 	}
 
@@ -310,10 +313,10 @@ func TestWriteAnnotatedWithValues(t *testing.T) {
 	if len(cfg.Setup) != 2 {
 		t.Errorf("setup len = %d, want 2", len(cfg.Setup))
 	}
-	if cfg.Setup[0] != "npm install" {
-		t.Errorf("setup[0] = %q, want %q", cfg.Setup[0], "npm install")
+	if cfg.Setup[0].Run != "npm install" {
+		t.Errorf("setup[0] = %q, want %q", cfg.Setup[0].Run, "npm install")
 	}
-	if len(cfg.Teardown) != 1 || cfg.Teardown[0] != "docker compose down" {
+	if len(cfg.Teardown) != 1 || cfg.Teardown[0].Run != "docker compose down" {
 		t.Errorf("teardown = %v, want [docker compose down]", cfg.Teardown)
 	}
 }
@@ -540,6 +543,30 @@ func TestDiskThresholdOverrides(t *testing.T) {
 	}
 }
 
+func TestDiskFloor(t *testing.T) {
+	if got := (&Config{}).DiskFloor(); got != nil {
+		t.Errorf("DiskFloor() = %+v with no bounds, want nil", *got)
+	}
+	got := (&Config{DiskMinFreeGB: 5}).DiskFloor()
+	if got == nil || got.Bytes != 5*disk.BytesPerGB || got.Percent != 0 {
+		t.Errorf("DiskFloor() = %+v, want 5 GB only", got)
+	}
+	got = (&Config{DiskMinFreePercent: 3}).DiskFloor()
+	if got == nil || got.Bytes != 0 || got.Percent != 3 {
+		t.Errorf("DiskFloor() = %+v, want 3%% only", got)
+	}
+
+	for _, content := range []string{"disk_min_free_gb: -1\n", "disk_min_free_percent: 100\n"} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\n"+content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("Load(%q) error = %v, want ErrInvalidConfig", content, err)
+		}
+	}
+}
+
 func TestLoadConfigWithDiskSettings(t *testing.T) {
 	dir := t.TempDir()
 	content := `version: 1
@@ -578,6 +605,7 @@ func TestWriteAnnotatedWithDiskValues(t *testing.T) {
 	existing.DiskWarn = &disabled
 	existing.DiskWarnPercent = 25
 	existing.DiskWarnGB = 30
+	existing.DiskMinFreeGB = 8
 
 	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
 		t.Fatalf("WriteAnnotatedWithValues error: %v", err)
@@ -589,7 +617,7 @@ func TestWriteAnnotatedWithDiskValues(t *testing.T) {
 	}
 	content := string(data)
 
-	for _, want := range []string{"disk_warn: false", "disk_warn_percent: 25", "disk_warn_gb: 30"} {
+	for _, want := range []string{"disk_warn: false", "disk_warn_percent: 25", "disk_warn_gb: 30", "disk_min_free_gb: 8"} {
 		if !strings.Contains(content, want) {
 			t.Errorf("missing %q in annotated config", want)
 		}
@@ -610,3 +638,556 @@ func TestWriteAnnotatedWithDiskValues(t *testing.T) {
 		t.Errorf("thresholds = %d%%/%dGB, want 25%%/30GB", reloaded.DiskWarnPercent, reloaded.DiskWarnGB)
 	}
 }
+
+func TestLoadConfigWithNotifications(t *testing.T) {
+	dir := t.TempDir()
+	content := `version: 1
+on_setup_complete:
+  - say done
+on_setup_failed:
+  - "notify-send 'wt' \"$WT_NOTIFY_MESSAGE\""
+notify:
+  terminal: true
+  fifo: /tmp/wt-notify
+`
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.OnSetupComplete) != 1 || cfg.OnSetupComplete[0] != "say done" {
+		t.Errorf("on_setup_complete = %v, want [say done]", cfg.OnSetupComplete)
+	}
+	if len(cfg.OnSetupFailed) != 1 {
+		t.Errorf("on_setup_failed = %v, want one entry", cfg.OnSetupFailed)
+	}
+	if !cfg.Notify.Terminal || cfg.Notify.Desktop || cfg.Notify.FIFO != "/tmp/wt-notify" || cfg.Notify.Socket != "" {
+		t.Errorf("notify = %+v, want terminal + fifo", cfg.Notify)
+	}
+}
+
+func TestWriteAnnotatedWithNotifyValues(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := DefaultConfig()
+	existing.OnSetupFailed = []string{"echo failed"}
+	existing.Notify = Notify{Desktop: true, Socket: "/run/user/1000/wt.sock"}
+
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatalf("WriteAnnotatedWithValues error: %v", err)
+	}
+
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if len(reloaded.OnSetupFailed) != 1 || reloaded.OnSetupFailed[0] != "echo failed" {
+		t.Errorf("on_setup_failed = %v, want [echo failed]", reloaded.OnSetupFailed)
+	}
+	if reloaded.Notify != existing.Notify {
+		t.Errorf("notify = %+v, want %+v", reloaded.Notify, existing.Notify)
+	}
+
+	// A fresh template documents the options without enabling them.
+	if err := WriteAnnotated(dir); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fresh.Notify.IsZero() || len(fresh.OnSetupComplete) != 0 {
+		t.Errorf("fresh template enabled notifications: %+v", fresh)
+	}
+}
+
+func TestLoadConfigWithHookOptions(t *testing.T) {
+	dir := t.TempDir()
+	content := `version: 1
+pty: true
+setup:
+  - npm install
+  - run: composer install
+    pty: false
+`
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Setup) != 2 || cfg.Setup[0].Run != "npm install" || cfg.Setup[1].Run != "composer install" {
+		t.Fatalf("setup = %+v, want npm install and composer install", cfg.Setup)
+	}
+	if !cfg.UsePTY(cfg.Setup[0]) {
+		t.Error("plain hook should inherit pty: true")
+	}
+	if cfg.UsePTY(cfg.Setup[1]) {
+		t.Error("hook with pty: false should override the project setting")
+	}
+}
+
+func TestLoadConfigHookMissingRun(t *testing.T) {
+	dir := t.TempDir()
+	content := "version: 1\nsetup:\n  - pty: true\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestHookOptionsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	on := true
+
+	existing := DefaultConfig()
+	existing.ParallelSetup = []Hook{{Run: "npm install"}, {Run: "docker compose pull", PTY: &on}}
+
+	for name, write := range map[string]func() error{
+		"Save":                     func() error { return existing.Save(dir) },
+		"WriteAnnotatedWithValues": func() error { return WriteAnnotatedWithValues(dir, &existing) },
+	} {
+		if err := write(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		reloaded, err := Load(dir)
+		if err != nil {
+			t.Fatalf("%s: reload: %v", name, err)
+		}
+		got := reloaded.ParallelSetup
+		if len(got) != 2 || got[0].PTY != nil || got[1].PTY == nil || !*got[1].PTY {
+			t.Errorf("%s: parallel_setup = %+v, want options preserved", name, got)
+		}
+	}
+}
+
+func TestShellFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		hook    Hook
+		want    string
+	}{
+		{"default", "", Hook{Run: "x"}, "sh -c"},
+		{"project shell", "bash -euo pipefail -c", Hook{Run: "x"}, "bash -euo pipefail -c"},
+		{"bare interpreter gets -c", "zsh", Hook{Run: "x"}, "zsh -c"},
+		{"options without -c get it", "bash -euo pipefail", Hook{Run: "x"}, "bash -euo pipefail -c"},
+		{"hook override", "bash -c", Hook{Run: "x", Shell: "dash -c"}, "dash -c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Shell: tt.project}
+			if got := strings.Join(cfg.ShellFor(tt.hook), " "); got != tt.want {
+				t.Errorf("ShellFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigHookValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		hook string
+	}{
+		{"run and script", "  - run: make\n    script: build.sh\n"},
+		{"script with shell", "  - script: build.sh\n    shell: bash -c\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			content := "version: 1\nsetup:\n" + tt.hook
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("err = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestScriptHookRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.Shell = "bash -euo pipefail -c"
+	existing.Teardown = []Hook{{Script: "stop.sh"}, {Run: "make clean", Shell: "zsh -c"}}
+
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if reloaded.Shell != existing.Shell {
+		t.Errorf("shell = %q, want %q", reloaded.Shell, existing.Shell)
+	}
+	if len(reloaded.Teardown) != 2 || reloaded.Teardown[0] != existing.Teardown[0] || reloaded.Teardown[1] != existing.Teardown[1] {
+		t.Errorf("teardown = %+v, want %+v", reloaded.Teardown, existing.Teardown)
+	}
+}
+
+func TestTasksRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	on := true
+	existing := DefaultConfig()
+	existing.Tasks = map[string]Hook{
+		"test":    {Run: "go test ./..."},
+		"dev":     {Run: "npm run dev", PTY: &on},
+		"db:seed": {Script: "seed.sh"},
+	}
+
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if got := reloaded.TaskNames(); !slices.Equal(got, []string{"db:seed", "dev", "test"}) {
+		t.Fatalf("TaskNames() = %v, want sorted names", got)
+	}
+	if got := reloaded.Tasks["test"]; got != existing.Tasks["test"] {
+		t.Errorf("test = %+v, want %+v", got, existing.Tasks["test"])
+	}
+	if got := reloaded.Tasks["db:seed"]; got.Script != "seed.sh" {
+		t.Errorf("db:seed = %+v, want script seed.sh", got)
+	}
+	if got := reloaded.Tasks["dev"]; got.Run != "npm run dev" || got.PTY == nil || !*got.PTY {
+		t.Errorf("dev = %+v, want pty preserved", got)
+	}
+}
+
+func TestResourceCleanupRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.ResourceCleanup = map[string]Hook{
+		"docker-volume": {Run: `docker volume rm "$WT_RESOURCE_ID"`},
+		"database":      {Script: "drop-db.sh"},
+	}
+
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	for kind, want := range existing.ResourceCleanup {
+		if got := reloaded.ResourceCleanup[kind]; got != want {
+			t.Errorf("%s = %+v, want %+v", kind, got, want)
+		}
+	}
+}
+
+func TestComposeRoundTrip(t *testing.T) {
+	off := false
+	for _, compose := range []*Compose{
+		{},
+		{ProjectName: "shop-${WORKTREE_ID}", Files: []string{"compose.yml"}, Profiles: []string{"db"}, Down: &off, Volumes: true},
+	} {
+		dir := t.TempDir()
+		existing := DefaultConfig()
+		existing.Compose = compose
+		if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+			t.Fatal(err)
+		}
+		reloaded, err := Load(dir)
+		if err != nil {
+			t.Fatalf("annotated config should be loadable: %v", err)
+		}
+		if !reflect.DeepEqual(reloaded.Compose, compose) {
+			t.Errorf("compose = %+v, want %+v", reloaded.Compose, compose)
+		}
+		if !reloaded.Compose.UpEnabled() || reloaded.Compose.DownEnabled() != (compose.Down == nil) {
+			t.Errorf("up/down = %t/%t for %+v", reloaded.Compose.UpEnabled(), reloaded.Compose.DownEnabled(), compose)
+		}
+	}
+
+	if (&Config{}).HasSetup() {
+		t.Error("HasSetup() with no hooks or compose should be false")
+	}
+	if !(&Config{Compose: &Compose{}}).HasSetup() {
+		t.Error("HasSetup() should be true when compose starts a project")
+	}
+	if n := (&Config{Compose: &Compose{}}).SetupSteps(); n != 1 {
+		t.Errorf("SetupSteps() with compose only = %d, want 1", n)
+	}
+	if n := (&Config{Setup: []Hook{{Run: "a"}}, ParallelSetup: []Hook{{Run: "b"}}}).SetupSteps(); n != 2 {
+		t.Errorf("SetupSteps() with two hooks = %d, want 2", n)
+	}
+}
+
+func TestApplyConflictPolicy(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.ApplyConflict = ConflictSkipModified
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.ApplyConflictOrDefault(); got != ConflictSkipModified {
+		t.Errorf("ApplyConflictOrDefault() = %q, want %q", got, ConflictSkipModified)
+	}
+
+	if got := (&Config{}).ApplyConflictOrDefault(); got != ConflictOverwrite {
+		t.Errorf("default = %q, want %q", got, ConflictOverwrite)
+	}
+
+	content := "version: 1\napply_conflict: merge\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err = %v, want ErrInvalidConfig for unknown policy", err)
+	}
+}
+
+func TestOverlaysRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.Overlays = []Overlay{
+		{Name: "release", Branches: []string{"release/*"}},
+		{Name: "hotfix", Branches: []string{"hotfix/*", "hf-*"}, Exclude: []string{"node_modules", ".cache/*"}},
+	}
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if len(reloaded.Overlays) != 2 {
+		t.Fatalf("overlays = %+v", reloaded.Overlays)
+	}
+	for i, want := range existing.Overlays {
+		got := reloaded.Overlays[i]
+		if got.Name != want.Name || !slices.Equal(got.Branches, want.Branches) || !slices.Equal(got.Exclude, want.Exclude) {
+			t.Errorf("overlay %d = %+v, want %+v", i, got, want)
+		}
+	}
+	if got := reloaded.Overlays[1].Match("hf-42"); got != "hf-*" {
+		t.Errorf("Match(hf-42) = %q, want hf-*", got)
+	}
+	if got := reloaded.Overlays[0].Match("main"); got != "" {
+		t.Errorf("Match(main) = %q, want no match", got)
+	}
+}
+
+func TestOverlaysInvalid(t *testing.T) {
+	for name, yml := range map[string]string{
+		"no name":     "overlays:\n  - branches: [main]\n",
+		"path name":   "overlays:\n  - name: a/b\n    branches: [main]\n",
+		"no branches": "overlays:\n  - name: a\n",
+		"bad glob":    "overlays:\n  - name: a\n    branches: [\"[\"]\n",
+		"duplicate":   "overlays:\n  - name: a\n    branches: [x]\n  - name: a\n    branches: [y]\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\n"+yml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestSharedRulesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.Shared.Rules = []SharedRule{
+		{Include: []string{"env/web.env"}, Mode: RuleTemplate, Dest: "apps/web/.env"},
+		{Include: []string{"copy/**"}, Exclude: []string{"copy/big/*"}, Mode: RuleCopy},
+		{Include: []string{"symlink/*"}, Mode: RuleSymlink},
+	}
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if len(reloaded.Shared.Rules) != len(existing.Shared.Rules) {
+		t.Fatalf("rules = %+v", reloaded.Shared.Rules)
+	}
+	for i, want := range existing.Shared.Rules {
+		got := reloaded.Shared.Rules[i]
+		if !slices.Equal(got.Include, want.Include) || !slices.Equal(got.Exclude, want.Exclude) || got.Mode != want.Mode || got.Dest != want.Dest {
+			t.Errorf("rule %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestSharedRulesInvalid(t *testing.T) {
+	for name, yml := range map[string]string{
+		"no include": "shared:\n  rules:\n    - mode: copy\n",
+		"bad mode":   "shared:\n  rules:\n    - include: [a]\n      mode: move\n",
+		"bad glob":   "shared:\n  rules:\n    - include: [\"a/[\"]\n      mode: copy\n",
+		"escaping":   "shared:\n  rules:\n    - include: [\"../x\"]\n      mode: copy\n",
+		"dest out":   "shared:\n  rules:\n    - include: [a]\n      mode: copy\n      dest: ../a\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\n"+yml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestSecretsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.SecretsCommand = `vault kv get -format=json -field=data "secret/my app"`
+	existing.SecretsFile = "secrets.env.age"
+	existing.SecretsIdentity = "~/.config/age/keys.txt"
+	existing.SecretsDotenv = "~/.config/myapp/secrets.env"
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if reloaded.SecretsCommand != existing.SecretsCommand || reloaded.SecretsFile != existing.SecretsFile ||
+		reloaded.SecretsIdentity != existing.SecretsIdentity || reloaded.SecretsDotenv != existing.SecretsDotenv {
+		t.Errorf("secrets = %q %q %q %q", reloaded.SecretsCommand, reloaded.SecretsFile, reloaded.SecretsIdentity, reloaded.SecretsDotenv)
+	}
+	if enc := reloaded.SecretsFileEncryption(); enc != "age" {
+		t.Errorf("SecretsFileEncryption() = %q, want age", enc)
+	}
+}
+
+func TestSecretsInvalid(t *testing.T) {
+	for name, yml := range map[string]string{
+		"plain file":        "secrets_file: secrets.env\n",
+		"identity with gpg": "secrets_file: secrets.env.gpg\nsecrets_identity: key.txt\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\n"+yml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestCopyPreserve(t *testing.T) {
+	var cfg Config
+	if !cfg.Preserves("times") || !cfg.Preserves("symlinks") {
+		t.Error("unset copy_preserve should keep everything")
+	}
+	cfg.CopyPreserve = []string{"times"}
+	if !cfg.Preserves("times") || cfg.Preserves("owner") {
+		t.Errorf("Preserves() with %v is wrong", cfg.CopyPreserve)
+	}
+
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.CopyPreserve = []string{"times", "symlinks"}
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if !slices.Equal(reloaded.CopyPreserve, existing.CopyPreserve) {
+		t.Errorf("copy_preserve = %v, want %v", reloaded.CopyPreserve, existing.CopyPreserve)
+	}
+
+	for name, yml := range map[string]string{
+		"unknown":       "copy_preserve: [acls]\n",
+		"none and more": "copy_preserve: [none, times]\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\n"+yml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestCopyModeRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	existing := DefaultConfig()
+	existing.CopyMode = CopyReflink
+	existing.CopyModePaths = map[string]CopyMode{".toolchains": CopyHardlink, "vendor/**": CopyBytes}
+	if err := WriteAnnotatedWithValues(dir, &existing); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("annotated config should be loadable: %v", err)
+	}
+	if reloaded.CopyMode != CopyReflink || !maps.Equal(reloaded.CopyModePaths, existing.CopyModePaths) {
+		t.Errorf("copy_mode = %q, copy_mode_paths = %v", reloaded.CopyMode, reloaded.CopyModePaths)
+	}
+
+	for name, yml := range map[string]string{
+		"unknown mode":      "copy_mode: clone\n",
+		"unknown path mode": "copy_mode_paths:\n  vendor: symlink\n",
+		"bad pattern":       "copy_mode_paths:\n  /abs: copy\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\n"+yml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
+func TestSymlinkStyle(t *testing.T) {
+	if existing := DefaultConfig(); existing.RelativeSymlinks() {
+		t.Error("an existing config without symlink_style should keep absolute links")
+	}
+
+	dir := t.TempDir()
+	cfg := NewProjectConfig()
+	if err := WriteAnnotatedWithValues(dir, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.RelativeSymlinks() {
+		t.Errorf("new project symlink_style = %q, want relative", reloaded.SymlinkStyle)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("version: 1\nsymlink_style: hard\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+	}
+}
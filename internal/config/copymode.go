@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// CopyMode is how apply gets the content of a copied shared file into a
+// worktree.
+type CopyMode string
+
+const (
+	CopyAuto     CopyMode = "auto"     // reflink where the filesystem can, else copy
+	CopyReflink  CopyMode = "reflink"  // reflink, failing where the filesystem can't
+	CopyHardlink CopyMode = "hardlink" // hard link to the shared file, copying across filesystems
+	CopyBytes    CopyMode = "copy"     // an independent copy, never sharing blocks
+)
+
+// CopyModes lists the valid modes, for help text and errors.
+var CopyModes = []CopyMode{CopyAuto, CopyReflink, CopyHardlink, CopyBytes}
+
+func validateCopyModes(mode CopyMode, paths map[string]CopyMode) error {
+	if mode != "" && !slices.Contains(CopyModes, mode) {
+		return fmt.Errorf("copy_mode: unknown mode %q (want auto, reflink, hardlink, or copy)", mode)
+	}
+	for _, pattern := range slices.Sorted(maps.Keys(paths)) {
+		if err := validateGlob(pattern); err != nil {
+			return fmt.Errorf("copy_mode_paths: %w", err)
+		}
+		if !slices.Contains(CopyModes, paths[pattern]) {
+			return fmt.Errorf("copy_mode_paths: %s: unknown mode %q (want auto, reflink, hardlink, or copy)", pattern, paths[pattern])
+		}
+	}
+	return nil
+}
+
+// writeCopyModePaths renders copy_mode_paths with its patterns sorted.
+func writeCopyModePaths(b *strings.Builder, paths map[string]CopyMode) {
+	b.WriteString("copy_mode_paths:\n")
+	for _, pattern := range slices.Sorted(maps.Keys(paths)) {
+		fmt.Fprintf(b, "  %s: %s\n", yamlQuote(pattern), paths[pattern])
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook is a setup or teardown command. In YAML it is either a plain command
+// string or a mapping that sets per-hook options:
+//
+//	setup:
+//	  - npm install
+//	  - run: composer install
+//	    pty: true
+//	  - run: source ./env.zsh && make
+//	    shell: zsh -c
+//	  - script: seed-db.sh
+type Hook struct {
+	Run string `yaml:"run,omitempty"`
+
+	// Script names an executable under <shared_dir>/hooks/ that runs
+	// directly, so its shebang picks the interpreter. Exclusive with Run.
+	Script string `yaml:"script,omitempty"`
+
+	// Shell overrides the project-level shell for this hook.
+	Shell string `yaml:"shell,omitempty"`
+
+	// PTY overrides the project-level pty setting for this hook.
+	PTY *bool `yaml:"pty,omitempty"`
+}
+
+// Name identifies the hook in output: its command, or its script path.
+func (h Hook) Name() string {
+	if h.Script != "" {
+		return h.Script
+	}
+	return h.Run
+}
+
+// hookFields has Hook's fields without its YAML methods, so decoding and
+// encoding the mapping form does not recurse.
+type hookFields Hook
+
+// UnmarshalYAML accepts both the plain string and the mapping form.
+func (h *Hook) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*h = Hook{}
+		return value.Decode(&h.Run)
+	}
+
+	var f hookFields
+	if err := value.Decode(&f); err != nil {
+		return err
+	}
+	switch {
+	case f.Run == "" && f.Script == "":
+		return fmt.Errorf("line %d: hook needs a run command or a script", value.Line)
+	case f.Run != "" && f.Script != "":
+		return fmt.Errorf("line %d: hook cannot set both run and script", value.Line)
+	case f.Script != "" && f.Shell != "":
+		return fmt.Errorf("line %d: script hooks run with their shebang and cannot set shell", value.Line)
+	}
+	*h = Hook(f)
+	return nil
+}
+
+// MarshalYAML writes hooks without options back as plain strings.
+func (h Hook) MarshalYAML() (any, error) {
+	if h.isPlain() {
+		return h.Run, nil
+	}
+	return hookFields(h), nil
+}
+
+// isPlain reports whether h can be written as a plain command string.
+func (h Hook) isPlain() bool {
+	return h.Script == "" && h.Shell == "" && h.PTY == nil
+}
+
+// UsePTY reports whether h should run under a pseudo-terminal, applying the
+// hook's own setting over the project-level default.
+func (c *Config) UsePTY(h Hook) bool {
+	if h.PTY != nil {
+		return *h.PTY
+	}
+	return c.PTY
+}
+
+// ShellFor returns the interpreter argv that h's command is appended to,
+// applying the hook's shell over the project-level one. A shell that doesn't
+// end in -c, such as "bash" or "bash -euo pipefail", gets it appended, or
+// the command would be taken for a script path.
+func (c *Config) ShellFor(h Hook) []string {
+	shell := h.Shell
+	if shell == "" {
+		shell = c.Shell
+	}
+	args := strings.Fields(shell)
+	if len(args) == 0 {
+		return []string{"sh", "-c"}
+	}
+	if args[len(args)-1] != "-c" {
+		args = append(args, "-c")
+	}
+	return args
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Overlay layers extra shared files over the base shared dir for worktrees
+// whose branch matches one of Branches. Its files live in
+// <shared_dir>/overlays/<name>/copy and .../symlink.
+type Overlay struct {
+	Name     string   `yaml:"name"`
+	Branches []string `yaml:"branches"`
+
+	// Exclude lists worktree paths (globs) that lower layers would supply
+	// but this overlay drops, e.g. a heavy cache symlink.
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// Match returns the first branch glob that matches branch, or "" if none do.
+func (o Overlay) Match(branch string) string {
+	for _, pattern := range o.Branches {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// validate checks the overlay's name and globs.
+func (o Overlay) validate() error {
+	if o.Name == "" || o.Name == "." || o.Name == ".." || strings.ContainsAny(o.Name, `/\`) {
+		return fmt.Errorf("overlay name %q must be a plain directory name", o.Name)
+	}
+	if len(o.Branches) == 0 {
+		return fmt.Errorf("overlay %q has no branches", o.Name)
+	}
+	for _, pattern := range slices.Concat(o.Branches, o.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("overlay %q: invalid pattern %q", o.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// validateOverlays checks every overlay and rejects duplicate names.
+func validateOverlays(overlays []Overlay) error {
+	seen := make(map[string]bool, len(overlays))
+	for _, o := range overlays {
+		if err := o.validate(); err != nil {
+			return err
+		}
+		if seen[o.Name] {
+			return fmt.Errorf("overlay %q is defined twice", o.Name)
+		}
+		seen[o.Name] = true
+	}
+	return nil
+}
+
+// writeOverlays renders the overlays list.
+func writeOverlays(b *strings.Builder, overlays []Overlay) {
+	b.WriteString("overlays:\n")
+	for _, o := range overlays {
+		fmt.Fprintf(b, "  - name: %s\n", yamlQuote(o.Name))
+		fmt.Fprintf(b, "    branches: %s\n", flowList(o.Branches))
+		if len(o.Exclude) > 0 {
+			fmt.Fprintf(b, "    exclude: %s\n", flowList(o.Exclude))
+		}
+	}
+}
+
+// flowList renders values as a YAML flow sequence.
+func flowList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = yamlQuote(v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
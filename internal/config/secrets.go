@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// HasSecrets reports whether any source for ${secret:NAME} is configured.
+func (c *Config) HasSecrets() bool {
+	return c.SecretsCommand != "" || c.SecretsFile != "" || c.SecretsDotenv != ""
+}
+
+// SecretsFileEncryption returns "age" or "gpg" for the configured
+// secrets_file, from its extension.
+func (c *Config) SecretsFileEncryption() string {
+	switch strings.ToLower(filepath.Ext(c.SecretsFile)) {
+	case ".age":
+		return "age"
+	case ".gpg", ".asc":
+		return "gpg"
+	}
+	return ""
+}
+
+func (c *Config) validateSecrets() error {
+	if c.SecretsFile != "" && c.SecretsFileEncryption() == "" {
+		return fmt.Errorf("secrets_file %q must be encrypted with age (.age) or GPG (.gpg, .asc)", c.SecretsFile)
+	}
+	if c.SecretsIdentity != "" && c.SecretsFileEncryption() != "age" {
+		return fmt.Errorf("secrets_identity only applies to an age-encrypted secrets_file")
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RuleMode is how a shared rule places matched files in a worktree.
+type RuleMode string
+
+const (
+	RuleCopy     RuleMode = "copy"     // copy, rendering .template files
+	RuleSymlink  RuleMode = "symlink"  // symlink each matched file or directory
+	RuleTemplate RuleMode = "template" // copy, rendering every file
+	RuleHardlink RuleMode = "hardlink" // hard link, copying across filesystems
+)
+
+// RuleModes lists the valid modes, for help text and errors.
+var RuleModes = []RuleMode{RuleCopy, RuleSymlink, RuleTemplate, RuleHardlink}
+
+// Shared configures how files in the shared dir reach the worktrees.
+type Shared struct {
+	// Rules replace the default directory convention (shared/copy is
+	// copied, shared/symlink is symlinked) when set. The first rule that
+	// matches a file decides how it is placed.
+	Rules []SharedRule `yaml:"rules,omitempty"`
+}
+
+// SharedRule places the files in the shared dir matching Include, and not
+// Exclude, into each worktree. Globs are slash-separated paths relative to
+// the shared dir (or an overlay's dir); ** matches any number of
+// directories, and a pattern matching a directory covers what is inside it.
+type SharedRule struct {
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude,omitempty"`
+	Mode    RuleMode `yaml:"mode"`
+
+	// Dest is where matches go in the worktree. For a pattern without
+	// wildcards it is the destination path itself, e.g. apps/web/.env;
+	// otherwise matches keep their path below the pattern's fixed leading
+	// directories, placed under Dest. Empty places them at the worktree root.
+	Dest string `yaml:"dest,omitempty"`
+}
+
+// HasRules reports whether shared rules replace the directory convention.
+func (s Shared) HasRules() bool {
+	return len(s.Rules) > 0
+}
+
+// validate checks the rule's globs, mode, and destination.
+func (r SharedRule) validate(i int) error {
+	if len(r.Include) == 0 {
+		return fmt.Errorf("shared rule %d has no include patterns", i+1)
+	}
+	if !slices.Contains(RuleModes, r.Mode) {
+		return fmt.Errorf("shared rule %d: unknown mode %q (want copy, symlink, template, or hardlink)", i+1, r.Mode)
+	}
+	for _, pattern := range slices.Concat(r.Include, r.Exclude) {
+		if err := validateGlob(pattern); err != nil {
+			return fmt.Errorf("shared rule %d: %w", i+1, err)
+		}
+	}
+	if r.Dest != "" {
+		clean := filepath.Clean(r.Dest)
+		if filepath.IsAbs(r.Dest) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("shared rule %d: dest %q must be a path inside the worktree", i+1, r.Dest)
+		}
+	}
+	return nil
+}
+
+// validateGlob checks that pattern is a relative path whose segments are
+// valid path.Match patterns or **.
+func validateGlob(pattern string) error {
+	if pattern == "" || strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("invalid pattern %q: must be a relative path", pattern)
+	}
+	for seg := range strings.SplitSeq(pattern, "/") {
+		if seg == ".." {
+			return fmt.Errorf("invalid pattern %q: must stay inside the shared dir", pattern)
+		}
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+func validateSharedRules(rules []SharedRule) error {
+	for i, r := range rules {
+		if err := r.validate(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSharedRules renders the shared rules section.
+func writeSharedRules(b *strings.Builder, rules []SharedRule) {
+	b.WriteString("shared:\n  rules:\n")
+	for _, r := range rules {
+		fmt.Fprintf(b, "    - include: %s\n", flowList(r.Include))
+		if len(r.Exclude) > 0 {
+			fmt.Fprintf(b, "      exclude: %s\n", flowList(r.Exclude))
+		}
+		fmt.Fprintf(b, "      mode: %s\n", r.Mode)
+		if r.Dest != "" {
+			fmt.Fprintf(b, "      dest: %s\n", yamlQuote(r.Dest))
+		}
+	}
+}
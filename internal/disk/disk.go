@@ -17,6 +17,13 @@ func (u Usage) PercentFree() float64 {
 	return float64(u.FreeBytes) / float64(u.TotalBytes) * 100
 }
 
+// After returns the usage once n more bytes are taken, as when estimating
+// whether something will fit.
+func (u Usage) After(n uint64) Usage {
+	u.FreeBytes -= min(n, u.FreeBytes)
+	return u
+}
+
 // Default thresholds: warn when the filesystem is below either bound.
 const (
 	DefaultWarnPercent = 10
@@ -115,3 +115,16 @@ func TestStatMissingPath(t *testing.T) {
 		t.Error("expected an error for a nonexistent path")
 	}
 }
+
+func TestUsageAfter(t *testing.T) {
+	u := Usage{TotalBytes: 100, FreeBytes: 30}
+	if got := u.After(10); got.FreeBytes != 20 || got.TotalBytes != 100 {
+		t.Errorf("After(10) = %+v, want 20 free of 100", got)
+	}
+	if got := u.After(50); got.FreeBytes != 0 {
+		t.Errorf("After(50) = %+v, want free clamped at 0", got)
+	}
+	if !(Threshold{Percent: 25}).IsLow(u.After(10)) {
+		t.Error("20% free should be below a 25% floor")
+	}
+}
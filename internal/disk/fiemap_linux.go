@@ -0,0 +1,70 @@
+package disk
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The FIEMAP ioctl and its flags, from linux/fiemap.h; x/sys/unix doesn't
+// define them.
+const (
+	fsIocFiemap        = 0xC020660B // _IOWR('f', 11, struct fiemap)
+	fiemapFlagSync     = 0x1
+	fiemapExtentLast   = 0x1
+	fiemapExtentShared = 0x2000
+	fiemapBatch        = 64
+)
+
+type fiemapExtent struct {
+	Logical  uint64
+	Physical uint64
+	Length   uint64
+	_        [2]uint64
+	Flags    uint32
+	_        [3]uint32
+}
+
+type fiemap struct {
+	Start         uint64
+	Length        uint64
+	Flags         uint32
+	MappedExtents uint32
+	ExtentCount   uint32
+	_             uint32
+	Extents       [fiemapBatch]fiemapExtent
+}
+
+// sharedBytes sums the file's extents flagged shared, which on btrfs and
+// XFS are the blocks a reflink copy has in common with its source. It
+// returns 0 where FIEMAP isn't supported, as on tmpfs and overlayfs.
+func sharedBytes(path string) uint64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = f.Close() }()
+
+	var (
+		m      fiemap
+		shared uint64
+		start  uint64
+	)
+	for {
+		m = fiemap{Start: start, Length: ^uint64(0) - start, Flags: fiemapFlagSync, ExtentCount: fiemapBatch}
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, f.Fd(), fsIocFiemap, uintptr(unsafe.Pointer(&m))) //nolint:gosec // G103: the ioctl takes a pointer to m
+		if errno != 0 || m.MappedExtents == 0 {
+			return shared
+		}
+		for _, e := range m.Extents[:m.MappedExtents] {
+			if e.Flags&fiemapExtentShared != 0 {
+				shared += e.Length
+			}
+			if e.Flags&fiemapExtentLast != 0 {
+				return shared
+			}
+			start = e.Logical + e.Length
+		}
+	}
+}
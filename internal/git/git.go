@@ -28,6 +28,7 @@ type Git interface {
 	CloneBare(ctx context.Context, url, dest string) error
 	ConfigureRemoteFetch(ctx context.Context) error
 	EnableWorktreeConfig(ctx context.Context) error
+	WorktreeConfigEnabled(ctx context.Context) (bool, error)
 	SetWorktreeBareFalse(ctx context.Context, worktreePath string) error
 	Fetch(ctx context.Context, remote string) error
 	ListRemoteBranches(ctx context.Context) ([]string, error)
@@ -35,11 +36,15 @@ type Git interface {
 	HasLocalBranch(ctx context.Context, branch string) (bool, error)
 	WorktreeAdd(ctx context.Context, path, branch string) error
 	WorktreeAddNew(ctx context.Context, path, branch, baseBranch string) error
+	WorktreeMove(ctx context.Context, path, dest string) error
 	WorktreeRemove(ctx context.Context, path string, force bool) error
 	WorktreeList(ctx context.Context) ([]WorktreeInfo, error)
 	WorktreePrune(ctx context.Context) error
+	WorktreeRepair(ctx context.Context, relative bool) error
 	BranchDelete(ctx context.Context, branch string, force bool) error
 	IsWorktreeDirty(ctx context.Context, worktreePath string) (bool, error)
+	IsTracked(ctx context.Context, worktreePath, path string) (bool, error)
+	TrackedFiles(ctx context.Context, worktreePath string) ([]string, error)
 	IsBranchMerged(ctx context.Context, branch, target string) (bool, error)
 	FetchAll(ctx context.Context) error
 	GetDefaultBranch(ctx context.Context) (string, error)
@@ -150,6 +155,16 @@ func (r *Runner) EnableWorktreeConfig(ctx context.Context) error {
 	return nil
 }
 
+// WorktreeConfigEnabled reports whether extensions.worktreeConfig is set on
+// the common dir.
+func (r *Runner) WorktreeConfigEnabled(ctx context.Context) (bool, error) {
+	out, err := r.Query(ctx, "config", "--bool", "--default", "false", "--get", "extensions.worktreeConfig")
+	if err != nil {
+		return false, err
+	}
+	return out == "true", nil
+}
+
 // SetWorktreeBareFalse writes core.bare=false into the worktree's
 // config.worktree. Required on git 2.52+ for worktrees attached to a bare
 // common dir. Caller must have enabled extensions.worktreeConfig first.
@@ -281,10 +296,27 @@ func parseGitVersion(output string) ([3]int, error) {
 	return v, nil
 }
 
-// supportsRelativePaths reports whether the given git version accepts
+// SupportsRelativePaths reports whether the given git version accepts
 // `git worktree add --relative-paths` (added in git 2.48).
-func supportsRelativePaths(v [3]int) bool {
-	return v[0] > 2 || (v[0] == 2 && v[1] >= 48)
+func SupportsRelativePaths(v [3]int) bool {
+	return atLeast(v, 2, 48)
+}
+
+// SupportsWorktreeConfig reports whether the given git version knows
+// extensions.worktreeConfig (added in git 2.20).
+func SupportsWorktreeConfig(v [3]int) bool {
+	return atLeast(v, 2, 20)
+}
+
+// RequiresBareOverride reports whether the given git version refuses to
+// discover a worktree attached to a bare common dir unless the worktree
+// sets core.bare=false (git 2.52+).
+func RequiresBareOverride(v [3]int) bool {
+	return atLeast(v, 2, 52)
+}
+
+func atLeast(v [3]int, major, minor int) bool {
+	return v[0] > major || (v[0] == major && v[1] >= minor)
 }
 
 // worktreeAddArgs builds the args slice for `git worktree add`, including
@@ -292,7 +324,7 @@ func supportsRelativePaths(v [3]int) bool {
 // error we omit the flag — falling back to absolute paths is always safe.
 func (r *Runner) worktreeAddArgs(ctx context.Context, tail ...string) []string {
 	args := []string{"worktree", "add"}
-	if v, err := r.Version(ctx); err == nil && supportsRelativePaths(v) {
+	if v, err := r.Version(ctx); err == nil && SupportsRelativePaths(v) {
 		args = append(args, "--relative-paths")
 	}
 	return append(args, tail...)
@@ -338,6 +370,18 @@ func (r *Runner) WorktreeAddNew(ctx context.Context, path, branch, baseBranch st
 	return r.SetWorktreeBareFalse(ctx, path)
 }
 
+// WorktreeMove moves the worktree at path to dest and, as WorktreeAdd
+// does, makes sure it overrides core.bare.
+func (r *Runner) WorktreeMove(ctx context.Context, path, dest string) error {
+	if _, err := r.Run(ctx, "worktree", "move", path, dest); err != nil {
+		return err
+	}
+	if err := r.EnableWorktreeConfig(ctx); err != nil {
+		return err
+	}
+	return r.SetWorktreeBareFalse(ctx, dest)
+}
+
 func (r *Runner) WorktreeRemove(ctx context.Context, path string, force bool) error {
 	args := []string{"worktree", "remove", path}
 	if force {
@@ -422,6 +466,57 @@ func parseDirtyStatus(output string) bool {
 	return strings.TrimSpace(output) != ""
 }
 
+// IsTracked reports whether path (relative to the worktree) is, or for a
+// directory contains, a file tracked by git.
+func (r *Runner) IsTracked(ctx context.Context, worktreePath, path string) (bool, error) {
+	args := []string{"-C", worktreePath, "ls-files", "--error-unmatch", "--", path}
+	cmdStr := "git " + strings.Join(args, " ")
+
+	ui.Command(cmdStr)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, fmt.Errorf("%s: %w\n%s", cmdStr, err, stderr.String())
+	}
+
+	return true, nil
+}
+
+// TrackedFiles lists the files git tracks in the worktree, relative to it
+// and slash-separated.
+func (r *Runner) TrackedFiles(ctx context.Context, worktreePath string) ([]string, error) {
+	args := []string{"-C", worktreePath, "ls-files", "-z"}
+	cmdStr := "git " + strings.Join(args, " ")
+
+	ui.Command(cmdStr)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w\n%s", cmdStr, err, stderr.String())
+	}
+
+	return parseNullList(stdout.String()), nil
+}
+
+func parseNullList(output string) []string {
+	var files []string
+	for f := range strings.SplitSeq(output, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
 func (r *Runner) IsBranchMerged(ctx context.Context, branch, target string) (bool, error) {
 	args := []string{"--git-dir", r.GitDir, "merge-base", "--is-ancestor", branch, target}
 	cmdStr := "git " + strings.Join(args, " ")
@@ -479,6 +574,17 @@ func (r *Runner) ResolveStartPoint(ctx context.Context, branch string) string {
 	return "HEAD"
 }
 
+// WorktreeRepair rewrites the links between the common dir and every
+// worktree, with relative paths when relative is set (git 2.48+).
+func (r *Runner) WorktreeRepair(ctx context.Context, relative bool) error {
+	args := []string{"worktree", "repair"}
+	if relative {
+		args = append(args, "--relative-paths")
+	}
+	_, err := r.Run(ctx, args...)
+	return err
+}
+
 func (r *Runner) WorktreePrune(ctx context.Context) error {
 	_, err := r.Run(ctx, "worktree", "prune")
 	return err
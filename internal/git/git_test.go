@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -299,6 +300,32 @@ func TestDryRunExecutesQueries(t *testing.T) {
 		t.Error("dry-run IsWorktreeDirty = false on a dirty tree")
 	}
 
+	tracked, err := runner.IsTracked(ctx, repo, "f.txt")
+	if err != nil {
+		t.Fatalf("dry-run IsTracked returned error: %v", err)
+	}
+	if !tracked {
+		t.Error("dry-run IsTracked(f.txt) = false, want true")
+	}
+	if err := os.WriteFile(filepath.Join(repo, "local.env"), []byte("x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tracked, err = runner.IsTracked(ctx, repo, "local.env")
+	if err != nil {
+		t.Fatalf("dry-run IsTracked returned error: %v", err)
+	}
+	if tracked {
+		t.Error("dry-run IsTracked(local.env) = true for an untracked file")
+	}
+
+	files, err := runner.TrackedFiles(ctx, repo)
+	if err != nil {
+		t.Fatalf("dry-run TrackedFiles returned error: %v", err)
+	}
+	if !slices.Equal(files, []string{"f.txt"}) {
+		t.Errorf("dry-run TrackedFiles = %v, want [f.txt]", files)
+	}
+
 	age, err := runner.GetLastCommitAge(ctx, repo)
 	if err != nil {
 		t.Fatalf("dry-run GetLastCommitAge returned error: %v", err)
@@ -534,13 +561,36 @@ func TestSupportsRelativePaths(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := supportsRelativePaths(tt.v); got != tt.expect {
-				t.Errorf("supportsRelativePaths(%v) = %v, want %v", tt.v, got, tt.expect)
+			if got := SupportsRelativePaths(tt.v); got != tt.expect {
+				t.Errorf("SupportsRelativePaths(%v) = %v, want %v", tt.v, got, tt.expect)
 			}
 		})
 	}
 }
 
+func TestVersionGates(t *testing.T) {
+	tests := []struct {
+		v              [3]int
+		worktreeConfig bool
+		bareOverride   bool
+	}{
+		{[3]int{2, 19, 9}, false, false},
+		{[3]int{2, 20, 0}, true, false},
+		{[3]int{2, 51, 2}, true, false},
+		{[3]int{2, 52, 0}, true, true},
+		{[3]int{3, 0, 0}, true, true},
+	}
+
+	for _, tt := range tests {
+		if got := SupportsWorktreeConfig(tt.v); got != tt.worktreeConfig {
+			t.Errorf("SupportsWorktreeConfig(%v) = %v, want %v", tt.v, got, tt.worktreeConfig)
+		}
+		if got := RequiresBareOverride(tt.v); got != tt.bareOverride {
+			t.Errorf("RequiresBareOverride(%v) = %v, want %v", tt.v, got, tt.bareOverride)
+		}
+	}
+}
+
 func TestIntegrationCloneAndWorktree(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -0,0 +1,150 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"syscall"
+
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// commandNotifier runs the on_setup_complete / on_setup_failed hooks in the
+// worktree with the event exported as WT_* environment variables.
+type commandNotifier struct {
+	onComplete []string
+	onFailed   []string
+	shell      []string // interpreter argv the commands are appended to
+}
+
+func (n *commandNotifier) Name() string { return "notification hooks" }
+
+func (n *commandNotifier) Notify(ctx context.Context, ev Event) error {
+	cmds := n.onComplete
+	if ev.Status == StatusFailed {
+		cmds = n.onFailed
+	}
+
+	env := append(os.Environ(),
+		"WT_SETUP_STATUS="+ev.Status,
+		"WT_BRANCH="+ev.Branch,
+		"WT_WORKTREE_PATH="+ev.WorktreePath,
+		"WT_SETUP_ELAPSED="+strconv.Itoa(int(ev.Elapsed.Seconds())),
+		"WT_SETUP_ERROR="+ev.Error,
+		"WT_NOTIFY_MESSAGE="+ev.Message(),
+	)
+
+	var errs []error
+	for _, cmdStr := range cmds {
+		argv := append(slices.Clone(n.shell), cmdStr)
+		cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+		cmd.Dir = ev.WorktreePath
+		cmd.Env = env
+		cmd.Stdout = ui.Output
+		cmd.Stderr = ui.Output
+		if err := cmd.Run(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cmdStr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// terminalNotifier rings the bell and emits an OSC 9 notification on the
+// terminal that launched setup. Terminals without OSC 9 support ignore the
+// escape sequence and still ring the bell.
+type terminalNotifier struct {
+	path string
+}
+
+func (n *terminalNotifier) Name() string { return "terminal" }
+
+func (n *terminalNotifier) Notify(_ context.Context, ev Event) error {
+	// O_NOCTTY: the setup process runs in its own session and must not
+	// acquire the user's terminal as its controlling TTY by opening it.
+	f, err := os.OpenFile(n.path, os.O_WRONLY|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	_, err = fmt.Fprintf(f, "\a\x1b]9;wt: %s\x07", ev.Message())
+	return err
+}
+
+// desktopNotifier shows a desktop notification via notify-send, or via
+// osascript on macOS where notify-send is not normally installed.
+type desktopNotifier struct{}
+
+func (n *desktopNotifier) Name() string { return "desktop" }
+
+func (n *desktopNotifier) Notify(ctx context.Context, ev Event) error {
+	if path, err := exec.LookPath("notify-send"); err == nil {
+		urgency := "normal"
+		if ev.Status == StatusFailed {
+			urgency = "critical"
+		}
+		return exec.CommandContext(ctx, path, "--app-name=wt", "--urgency="+urgency, "wt", ev.Message()).Run()
+	}
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf("display notification %q with title %q", ev.Message(), "wt")
+		return exec.CommandContext(ctx, "osascript", "-e", script).Run()
+	}
+	return errors.New("notify-send not found in PATH")
+}
+
+// fifoNotifier writes the event as a JSON line to a named pipe. The pipe is
+// opened non-blocking so a missing reader fails fast instead of hanging.
+type fifoNotifier struct {
+	path string
+}
+
+func (n *fifoNotifier) Name() string { return "fifo " + n.path }
+
+func (n *fifoNotifier) Notify(_ context.Context, ev Event) error {
+	f, err := os.OpenFile(n.path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		if errors.Is(err, syscall.ENXIO) {
+			return errors.New("no process is reading the FIFO")
+		}
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return writeJSONLine(f, ev)
+}
+
+// socketNotifier writes the event as a JSON line to a Unix stream socket.
+type socketNotifier struct {
+	path string
+}
+
+func (n *socketNotifier) Name() string { return "socket " + n.path }
+
+func (n *socketNotifier) Notify(ctx context.Context, ev Event) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", n.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetWriteDeadline(deadline)
+	}
+	return writeJSONLine(conn, ev)
+}
+
+func writeJSONLine(w io.Writer, ev Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
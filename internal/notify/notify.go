@@ -0,0 +1,99 @@
+// Package notify delivers completion notifications for background setup:
+// user-configured hook commands plus built-in notifiers for the originating
+// terminal, the desktop, a named FIFO, and a local Unix socket.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// notifyTimeout bounds each notifier so a stuck reader or slow desktop
+// daemon cannot keep the background setup process alive indefinitely.
+const notifyTimeout = 5 * time.Second
+
+// Event statuses, matching project.SetupStatus values.
+const (
+	StatusComplete = "complete"
+	StatusFailed   = "failed"
+)
+
+// Event describes a finished background setup.
+type Event struct {
+	Status       string        `json:"status"`
+	Branch       string        `json:"branch"`
+	WorktreePath string        `json:"worktree_path"`
+	Elapsed      time.Duration `json:"-"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Message returns a one-line human-readable summary of the event.
+func (e Event) Message() string {
+	if e.Status == StatusFailed {
+		return fmt.Sprintf("Setup failed for %s after %s", e.Branch, ui.FormatDuration(e.Elapsed))
+	}
+	return fmt.Sprintf("Setup complete for %s in %s", e.Branch, ui.FormatDuration(e.Elapsed))
+}
+
+// MarshalJSON adds elapsed_seconds so FIFO and socket consumers don't have
+// to parse Go duration strings.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plain Event
+	return json.Marshal(struct {
+		plain
+		ElapsedSeconds float64 `json:"elapsed_seconds"`
+	}{plain(e), e.Elapsed.Seconds()})
+}
+
+// Notifier delivers an Event somewhere.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, ev Event) error
+}
+
+// FromConfig builds the notifiers enabled in cfg. tty is the terminal device
+// that started setup (empty when unknown); the terminal notifier is skipped
+// without one.
+func FromConfig(cfg *config.Config, tty string) []Notifier {
+	var ns []Notifier
+	if len(cfg.OnSetupComplete) > 0 || len(cfg.OnSetupFailed) > 0 {
+		ns = append(ns, &commandNotifier{
+			onComplete: cfg.OnSetupComplete,
+			onFailed:   cfg.OnSetupFailed,
+			shell:      cfg.ShellFor(config.Hook{}),
+		})
+	}
+	if cfg.Notify.Terminal && tty != "" {
+		ns = append(ns, &terminalNotifier{path: tty})
+	}
+	if cfg.Notify.Desktop {
+		ns = append(ns, &desktopNotifier{})
+	}
+	if cfg.Notify.FIFO != "" {
+		ns = append(ns, &fifoNotifier{path: cfg.Notify.FIFO})
+	}
+	if cfg.Notify.Socket != "" {
+		ns = append(ns, &socketNotifier{path: cfg.Notify.Socket})
+	}
+	return ns
+}
+
+// Send delivers ev to every notifier, each under its own timeout. All
+// notifiers run even if earlier ones fail; the failures are joined.
+func Send(ctx context.Context, notifiers []Notifier, ev Event) error {
+	var errs []error
+	for _, n := range notifiers {
+		nctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		if err := n.Notify(nctx, ev); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,281 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"golang.org/x/sys/unix"
+)
+
+func testEvent(t *testing.T) Event {
+	t.Helper()
+	return Event{
+		Status:       StatusComplete,
+		Branch:       "feature/x",
+		WorktreePath: t.TempDir(),
+		Elapsed:      90 * time.Second,
+	}
+}
+
+func TestEventMessage(t *testing.T) {
+	ev := Event{Status: StatusComplete, Branch: "feature/x", Elapsed: 90 * time.Second}
+	if got, want := ev.Message(), "Setup complete for feature/x in 1 minute 30 seconds"; got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+
+	ev.Status = StatusFailed
+	if got := ev.Message(); !strings.HasPrefix(got, "Setup failed for feature/x") {
+		t.Errorf("Message() = %q, want a failure message", got)
+	}
+}
+
+func TestEventMarshalJSON(t *testing.T) {
+	ev := Event{Status: StatusFailed, Branch: "b", WorktreePath: "/p", Elapsed: 2 * time.Second, Error: "boom"}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["status"] != "failed" || got["branch"] != "b" || got["error"] != "boom" {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	if got["elapsed_seconds"] != 2.0 {
+		t.Errorf("elapsed_seconds = %v, want 2", got["elapsed_seconds"])
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		tty  string
+		want []string
+	}{
+		{"nothing configured", config.Config{}, "/dev/pts/1", nil},
+		{"terminal without tty is skipped", config.Config{Notify: config.Notify{Terminal: true}}, "", nil},
+		{
+			name: "everything",
+			cfg: config.Config{
+				OnSetupFailed: []string{"echo failed"},
+				Notify:        config.Notify{Terminal: true, Desktop: true, FIFO: "/f", Socket: "/s"},
+			},
+			tty:  "/dev/pts/1",
+			want: []string{"notification hooks", "terminal", "desktop", "fifo /f", "socket /s"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			ns := FromConfig(&cfg, tt.tty)
+			var names []string
+			for _, n := range ns {
+				names = append(names, n.Name())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("notifiers = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandNotifierExportsEvent(t *testing.T) {
+	origOutput := ui.Output
+	ui.Output = io.Discard
+	t.Cleanup(func() { ui.Output = origOutput })
+	ev := testEvent(t)
+
+	n := &commandNotifier{
+		onComplete: []string{`printf '%s|%s|%s' "$WT_SETUP_STATUS" "$WT_BRANCH" "$WT_SETUP_ELAPSED" > out.txt`},
+		onFailed:   []string{"touch failed.txt"},
+		shell:      []string{"sh", "-c"},
+	}
+	if err := n.Notify(context.Background(), ev); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(ev.WorktreePath, "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "complete|feature/x|90" {
+		t.Errorf("hook saw %q, want %q", got, "complete|feature/x|90")
+	}
+	if _, err := os.Stat(filepath.Join(ev.WorktreePath, "failed.txt")); err == nil {
+		t.Error("on_setup_failed hook ran for a successful setup")
+	}
+}
+
+func TestCommandNotifierFailure(t *testing.T) {
+	origOutput := ui.Output
+	ui.Output = io.Discard
+	t.Cleanup(func() { ui.Output = origOutput })
+	ev := testEvent(t)
+	ev.Status = StatusFailed
+
+	n := &commandNotifier{onFailed: []string{"exit 3"}, shell: []string{"sh", "-c"}}
+	if err := n.Notify(context.Background(), ev); err == nil {
+		t.Fatal("expected error from failing notification hook")
+	}
+}
+
+func TestCommandNotifierUsesConfiguredShell(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+	origOutput := ui.Output
+	ui.Output = io.Discard
+	t.Cleanup(func() { ui.Output = origOutput })
+	ev := testEvent(t)
+
+	cfg := config.Config{Shell: "bash", OnSetupComplete: []string{`[[ -n $BASH_VERSION ]] && touch bash.txt`}}
+	ns := FromConfig(&cfg, "")
+	if len(ns) != 1 {
+		t.Fatalf("got %d notifiers, want 1", len(ns))
+	}
+	if err := ns[0].Notify(context.Background(), ev); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ev.WorktreePath, "bash.txt")); err != nil {
+		t.Error("notification hook did not run under the configured shell")
+	}
+}
+
+func TestTerminalNotifier(t *testing.T) {
+	// Any writable path stands in for the TTY device.
+	path := filepath.Join(t.TempDir(), "tty")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	n := &terminalNotifier{path: path}
+	if err := n.Notify(context.Background(), testEvent(t)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "\a\x1b]9;wt: Setup complete for feature/x") || !strings.HasSuffix(string(got), "\x07") {
+		t.Errorf("terminal output = %q, want bell + OSC 9 sequence", got)
+	}
+}
+
+func TestFIFONotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.fifo")
+	if err := unix.Mkfifo(path, 0o600); err != nil {
+		t.Skipf("mkfifo unsupported: %v", err)
+	}
+
+	n := &fifoNotifier{path: path}
+	if err := n.Notify(context.Background(), testEvent(t)); err == nil {
+		t.Error("expected an error when nothing reads the FIFO")
+	}
+
+	lines := make(chan string, 1)
+	go func() {
+		f, err := os.Open(path)
+		if err != nil {
+			lines <- ""
+			return
+		}
+		defer func() { _ = f.Close() }()
+		line, _ := bufio.NewReader(f).ReadString('\n')
+		lines <- line
+	}()
+
+	// The reader's open blocks until a writer appears and vice versa, so
+	// retry until the reader goroutine has the FIFO open.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		err := n.Notify(context.Background(), testEvent(t))
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Notify: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if line := <-lines; !strings.Contains(line, `"status":"complete"`) {
+		t.Errorf("FIFO line = %q, want JSON event", line)
+	}
+}
+
+func TestSocketNotifier(t *testing.T) {
+	// Unix socket paths are length-limited, so avoid the long t.TempDir path.
+	dir, err := os.MkdirTemp("", "wtn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "s.sock")
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("unix sockets unsupported: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			lines <- ""
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		lines <- line
+	}()
+
+	n := &socketNotifier{path: path}
+	if err := Send(context.Background(), []Notifier{n}, testEvent(t)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if line := <-lines; !strings.Contains(line, `"branch":"feature/x"`) {
+		t.Errorf("socket line = %q, want JSON event", line)
+	}
+}
+
+func TestSendJoinsErrors(t *testing.T) {
+	ns := []Notifier{
+		&socketNotifier{path: filepath.Join(t.TempDir(), "missing.sock")},
+		&fifoNotifier{path: filepath.Join(t.TempDir(), "missing.fifo")},
+	}
+	err := Send(context.Background(), ns, testEvent(t))
+	if err == nil {
+		t.Fatal("expected joined error")
+	}
+	if !strings.Contains(err.Error(), "socket") || !strings.Contains(err.Error(), "fifo") {
+		t.Errorf("error = %v, want both notifier names", err)
+	}
+}
+
+func TestTTYNameNonTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "notatty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	if got := TTYName(f); got != "" {
+		t.Errorf("TTYName(regular file) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	isatty "github.com/mattn/go-isatty"
+	"golang.org/x/sys/unix"
+)
+
+// TTYName returns the device path of the terminal f is attached to, or ""
+// when f is not a terminal. The background setup process is detached from
+// the terminal, so the parent resolves this and passes it down.
+func TTYName(f *os.File) string {
+	if !isatty.IsTerminal(f.Fd()) {
+		return ""
+	}
+
+	// Linux exposes the answer directly.
+	if p, err := os.Readlink("/proc/self/fd/" + strconv.FormatUint(uint64(f.Fd()), 10)); err == nil && filepath.IsAbs(p) {
+		return p
+	}
+
+	// Elsewhere, match the device number against the terminal nodes in /dev.
+	var st unix.Stat_t
+	fd := int(f.Fd()) //nolint:gosec // G115: file descriptors always fit in an int
+	if err := unix.Fstat(fd, &st); err != nil {
+		return ""
+	}
+	for _, pattern := range []string{"/dev/pts/*", "/dev/ttys*", "/dev/tty*"} {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			var cand unix.Stat_t
+			if unix.Stat(m, &cand) == nil && cand.Rdev == st.Rdev {
+				return m
+			}
+		}
+	}
+	return ""
+}
@@ -3,8 +3,11 @@ package project
 import (
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/bkildow/wt-cli/internal/config"
@@ -16,22 +19,73 @@ import (
 type ApplyResult struct {
 	Copied    int
 	Symlinked int
+
+	// Strategies counts the copied files by how their content was copied.
+	Strategies map[fscopy.Strategy]int
 }
 
+// Add accumulates other into r, for totals across worktrees.
+func (r *ApplyResult) Add(other ApplyResult) {
+	r.Copied += other.Copied
+	r.Symlinked += other.Symlinked
+	for strategy, n := range other.Strategies {
+		if r.Strategies == nil {
+			r.Strategies = make(map[fscopy.Strategy]int)
+		}
+		r.Strategies[strategy] += n
+	}
+}
+
+// ApplyCopy copies the files in shared/copy, and in the copy/ of each
+// overlay matching vars.BranchName, into the worktree. With nil vars,
+// templates are copied verbatim and only the base layer applies.
 func ApplyCopy(projectRoot, worktreePath string, cfg *config.Config, dryRun bool, vars *TemplateVars) (int, error) {
-	copyDir := filepath.Join(SharedPath(projectRoot, cfg), "copy")
+	return applyCopy(projectRoot, worktreePath, cfg, dryRun, vars, nil)
+}
 
-	if _, err := os.Stat(copyDir); os.IsNotExist(err) {
+func applyCopy(projectRoot, worktreePath string, cfg *config.Config, dryRun bool, vars *TemplateVars, rec *manifestRecorder) (int, error) {
+	vars = withCompose(cfg, withSecrets(projectRoot, cfg, vars))
+	if cfg.Shared.HasRules() {
+		return applyRules(projectRoot, worktreePath, cfg, branchOf(vars), dryRun, vars, rec, false)
+	}
+
+	layers := Layers(projectRoot, cfg, branchOf(vars))
+	if !anyLayerHas(layers, "copy") {
 		return 0, nil
 	}
+	plan, err := planCopies(layers, vars)
+	if err != nil {
+		return 0, err
+	}
 
 	ui.Step("Copying shared files")
 
 	var count int
 	logged := make(map[string]bool)
+	for i, layer := range layers {
+		n, err := copyLayer(layer, worktreePath, cfg, dryRun, vars, rec, logged,
+			func(rel string) bool { return plan.owns(i, copyDest(rel, vars)) })
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
 
-	// Fast path: reflink whole template-free subtrees in one syscall instead of walking file-by-file.
-	skipTrees, treeCount, err := fastPathCopyTrees(copyDir, worktreePath, vars, dryRun, logged)
+// copyLayer copies the files of one layer's copy/ dir that owns reports it
+// supplies, given their path relative to that dir.
+func copyLayer(layer Layer, worktreePath string, cfg *config.Config, dryRun bool, vars *TemplateVars, rec *manifestRecorder, logged map[string]bool, owns func(rel string) bool) (int, error) {
+	copyDir := filepath.Join(layer.Dir, "copy")
+	if _, err := os.Stat(copyDir); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	policy := cfg.ApplyConflictOrDefault()
+	var count int
+
+	// Fast path: copy whole template-free subtrees at once instead of walking file-by-file.
+	skipTrees, treeCount, err := fastPathCopyTrees(layer, worktreePath, cfg, vars, dryRun, logged, rec, owns)
 	if err != nil {
 		return 0, err
 	}
@@ -56,6 +110,9 @@ func ApplyCopy(projectRoot, worktreePath string, cfg *config.Config, dryRun bool
 			}
 			return nil
 		}
+		if !owns(rel) {
+			return nil
+		}
 		dest := filepath.Join(worktreePath, rel)
 
 		if dryRun {
@@ -85,13 +142,45 @@ func ApplyCopy(projectRoot, worktreePath string, cfg *config.Config, dryRun bool
 				return err
 			}
 			dest = filepath.Join(worktreePath, StripTemplateExt(rel))
-			processed := ProcessTemplate(string(content), *vars)
+			processed, err := RenderTemplate(string(content), *vars)
+			if err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+			if keep, err := keepLocalCopy(policy, StripTemplateExt(rel), dest, []byte(processed)); err != nil || keep {
+				return err
+			}
 			ui.Info(fmt.Sprintf("  substituted template variables in %s", StripTemplateExt(rel)))
 			count++
-			return os.WriteFile(dest, []byte(processed), srcInfo.Mode())
+			if err := writeRendered(dest, content, []byte(processed), srcInfo.Mode()); err != nil {
+				return err
+			}
+			return rec.copied(StripTemplateExt(rel), layer.source("copy", rel))
 		}
 
-		if err := fscopy.CopyFile(path, dest); err != nil {
+		opts := copyOptions(cfg, rel)
+		asLink := d.Type()&fs.ModeSymlink != 0 && opts.Symlinks
+		if !asLink && policy != config.ConflictOverwrite {
+			want, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if keep, err := keepLocalCopy(policy, rel, dest, want); err != nil || keep {
+				return err
+			}
+		}
+
+		strategy, err := fscopy.CopyFile(path, dest, opts)
+		if err != nil {
+			return err
+		}
+		rec.copiedWith(strategy, 1)
+		if asLink {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			rec.linked(rel, layer.source("copy", rel), target)
+		} else if err := rec.copied(rel, layer.source("copy", rel)); err != nil {
 			return err
 		}
 		if isNested {
@@ -105,10 +194,12 @@ func ApplyCopy(projectRoot, worktreePath string, cfg *config.Config, dryRun bool
 	return count, err
 }
 
-// fastPathCopyTrees reflinks each template-free top-level subtree and returns the
-// names the caller's per-file walk should skip, plus the file count for reporting.
-func fastPathCopyTrees(copyDir, worktreePath string, vars *TemplateVars, dryRun bool, logged map[string]bool) (skip map[string]bool, totalFiles int, err error) {
+// fastPathCopyTrees copies each template-free top-level subtree whose files
+// all come from this layer and returns the names the caller's per-file walk
+// should skip, plus the file count for reporting.
+func fastPathCopyTrees(layer Layer, worktreePath string, cfg *config.Config, vars *TemplateVars, dryRun bool, logged map[string]bool, rec *manifestRecorder, owns func(rel string) bool) (skip map[string]bool, totalFiles int, err error) {
 	skip = make(map[string]bool)
+	copyDir := filepath.Join(layer.Dir, "copy")
 
 	entries, err := os.ReadDir(copyDir)
 	if err != nil {
@@ -125,11 +216,20 @@ func fastPathCopyTrees(copyDir, worktreePath string, vars *TemplateVars, dryRun
 		subSrc := filepath.Join(copyDir, entry.Name())
 		subDst := filepath.Join(worktreePath, entry.Name())
 
-		hasTemplate, fileCount, scanErr := scanTreeForTemplates(subSrc, wantTemplateScan)
-		if scanErr != nil {
-			return skip, totalFiles, scanErr
+		// CopyTree requires a fresh dst; if one already exists, let the
+		// per-file walk overwrite it without scanning the tree first.
+		if _, lstatErr := os.Lstat(subDst); lstatErr == nil && !dryRun {
+			continue
 		}
-		if hasTemplate {
+		opts, uniform := treeCopyOptions(cfg, entry.Name())
+		if !uniform {
+			continue
+		}
+		whole, fileCount, err := scanTree(copyDir, subSrc, wantTemplateScan, owns)
+		if err != nil {
+			return skip, totalFiles, err
+		}
+		if !whole {
 			continue
 		}
 
@@ -140,18 +240,18 @@ func fastPathCopyTrees(copyDir, worktreePath string, vars *TemplateVars, dryRun
 			continue
 		}
 
-		// CopyTree requires a fresh dst; if one already exists, let the per-file walk overwrite it.
-		if _, lstatErr := os.Lstat(subDst); lstatErr == nil {
-			continue
+		stats, err := fscopy.CopyTree(subSrc, subDst, opts)
+		if err != nil {
+			return skip, totalFiles, err
 		}
-
-		if cloneErr := fscopy.CopyTree(subSrc, subDst); cloneErr != nil {
-			if fscopy.IsReflinkUnsupported(cloneErr) {
-				continue
-			}
-			return skip, totalFiles, cloneErr
+		if err := rec.copiedTree(entry.Name(), layer.source("copy", entry.Name())); err != nil {
+			return skip, totalFiles, err
 		}
-		logCopyDir(entry.Name(), logged, false)
+		for strategy, n := range stats.Strategies {
+			rec.copiedWith(strategy, n)
+		}
+		ui.Info(fmt.Sprintf("  copied %s/ (%s)", entry.Name(), describeTreeCopy(stats)))
+		logged[entry.Name()] = true
 		skip[entry.Name()] = true
 		totalFiles += fileCount
 	}
@@ -159,52 +259,69 @@ func fastPathCopyTrees(copyDir, worktreePath string, vars *TemplateVars, dryRun
 	return skip, totalFiles, nil
 }
 
-func scanTreeForTemplates(dir string, wantTemplateScan bool) (hasTemplate bool, fileCount int, err error) {
-	err = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
+// scanTree walks dir, a top-level tree in copyDir, once and reports whether
+// it can be copied whole: it holds no templates (when templates is set) and
+// every file in it is one owns reports this layer supplies. fileCount is the
+// number of files when it can.
+func scanTree(copyDir, dir string, templates bool, owns func(rel string) bool) (whole bool, fileCount int, err error) {
+	whole = true
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
 		}
-		if d.IsDir() {
-			return nil
+		if templates && IsTemplateFile(d.Name()) {
+			whole = false
+			return filepath.SkipAll
 		}
-		if wantTemplateScan && IsTemplateFile(d.Name()) {
-			hasTemplate = true
+		rel, err := filepath.Rel(copyDir, path)
+		if err != nil {
+			return err
+		}
+		if !owns(rel) {
+			// Supplied by another layer or dropped by an overlay.
+			whole = false
 			return filepath.SkipAll
 		}
 		fileCount++
 		return nil
 	})
-	if err != nil {
+	if err != nil || !whole {
 		return false, 0, err
 	}
-	if hasTemplate {
-		return true, 0, nil
-	}
-	return false, fileCount, nil
+	return true, fileCount, nil
 }
 
 // ApplySymlinks creates symlinks in the worktree for each top-level entry
-// in shared/symlink/. When a destination already exists as a real directory
-// (e.g. .claude/ created by Claude Code), the contents are symlinked
-// individually instead of replacing the directory.
-func ApplySymlinks(projectRoot, worktreePath string, cfg *config.Config, dryRun bool) (int, error) {
-	symlinkDir := filepath.Join(SharedPath(projectRoot, cfg), "symlink")
+// in shared/symlink/, and in the symlink/ of each overlay matching branch;
+// an overlay's entry replaces the base entry of the same name. When a
+// destination already exists as a real directory (e.g. .claude/ created by
+// Claude Code), the contents are symlinked individually instead of
+// replacing the directory.
+func ApplySymlinks(projectRoot, worktreePath string, cfg *config.Config, branch string, dryRun bool) (int, error) {
+	return applySymlinks(projectRoot, worktreePath, cfg, branch, dryRun, nil)
+}
 
-	if _, err := os.Stat(symlinkDir); os.IsNotExist(err) {
-		return 0, nil
+func applySymlinks(projectRoot, worktreePath string, cfg *config.Config, branch string, dryRun bool, rec *manifestRecorder) (int, error) {
+	if cfg.Shared.HasRules() {
+		return applyRules(projectRoot, worktreePath, cfg, branch, dryRun, nil, rec, true)
 	}
 
-	ui.Step("Creating symlinks")
-
-	entries, err := os.ReadDir(symlinkDir)
+	layers := Layers(projectRoot, cfg, branch)
+	if !anyLayerHas(layers, "symlink") {
+		return 0, nil
+	}
+	plan, err := planSymlinks(layers)
 	if err != nil {
 		return 0, err
 	}
 
+	ui.Step("Creating symlinks")
+
 	var count int
-	for _, entry := range entries {
-		target := filepath.Join(symlinkDir, entry.Name())
-		link := filepath.Join(worktreePath, entry.Name())
+	for _, name := range slices.Sorted(maps.Keys(plan)) {
+		layer := layers[plan[name]]
+		target := filepath.Join(layer.Dir, "symlink", name)
+		link := filepath.Join(worktreePath, name)
 
 		if dryRun {
 			ui.DryRunNotice(fmt.Sprintf("symlink %s -> %s", link, target))
@@ -215,9 +332,9 @@ func ApplySymlinks(projectRoot, worktreePath string, cfg *config.Config, dryRun
 		// symlink), symlink individual files inside instead of replacing the
 		// whole directory. This preserves worktree-local files like those in
 		// .claude/ while still symlinking shared config files.
-		if entry.IsDir() {
+		if info, err := os.Lstat(target); err == nil && info.IsDir() {
 			if info, err := os.Lstat(link); err == nil && info.IsDir() && info.Mode()&os.ModeSymlink == 0 {
-				n, err := symlinkDirContents(target, link, worktreePath)
+				n, err := symlinkDirContents(target, link, worktreePath, layer.source("symlink", name), cfg.RelativeSymlinks(), rec)
 				count += n
 				if err != nil {
 					return count, err
@@ -229,12 +346,14 @@ func ApplySymlinks(projectRoot, worktreePath string, cfg *config.Config, dryRun
 		// Remove existing file/symlink at destination (error ignored; Symlink will fail if needed)
 		_ = os.Remove(link)
 
-		if err := os.Symlink(target, link); err != nil {
+		written := linkTarget(target, link, cfg.RelativeSymlinks())
+		if err := os.Symlink(written, link); err != nil {
 			return count, err
 		}
+		rec.linked(name, layer.source("symlink", name), written)
 
 		relTarget, _ := filepath.Rel(worktreePath, target)
-		ui.Info(fmt.Sprintf("  symlinked %s → %s", entry.Name(), relTarget))
+		ui.Info(fmt.Sprintf("  symlinked %s → %s", name, relTarget))
 		count++
 	}
 
@@ -243,7 +362,9 @@ func ApplySymlinks(projectRoot, worktreePath string, cfg *config.Config, dryRun
 
 // symlinkDirContents symlinks individual entries from srcDir into destDir,
 // recursing into subdirectories that already exist at the destination.
-func symlinkDirContents(srcDir, destDir, worktreePath string) (int, error) {
+// source is srcDir relative to the shared dir, for the manifest. With
+// relative set, the links use relative targets.
+func symlinkDirContents(srcDir, destDir, worktreePath, source string, relative bool, rec *manifestRecorder) (int, error) {
 	if err := os.MkdirAll(destDir, 0o755); err != nil {
 		return 0, err
 	}
@@ -261,7 +382,7 @@ func symlinkDirContents(srcDir, destDir, worktreePath string) (int, error) {
 		// Recurse if both source and destination are real directories.
 		if entry.IsDir() {
 			if info, err := os.Lstat(dest); err == nil && info.IsDir() && info.Mode()&os.ModeSymlink == 0 {
-				n, err := symlinkDirContents(src, dest, worktreePath)
+				n, err := symlinkDirContents(src, dest, worktreePath, filepath.Join(source, entry.Name()), relative, rec)
 				count += n
 				if err != nil {
 					return count, err
@@ -272,12 +393,14 @@ func symlinkDirContents(srcDir, destDir, worktreePath string) (int, error) {
 
 		_ = os.Remove(dest)
 
-		if err := os.Symlink(src, dest); err != nil {
+		written := linkTarget(src, dest, relative)
+		if err := os.Symlink(written, dest); err != nil {
 			return count, err
 		}
 
 		relName, _ := filepath.Rel(worktreePath, dest)
 		relTarget, _ := filepath.Rel(worktreePath, src)
+		rec.linked(relName, filepath.Join(source, entry.Name()), written)
 		ui.Info(fmt.Sprintf("  symlinked %s → %s", relName, relTarget))
 		count++
 	}
@@ -285,16 +408,117 @@ func symlinkDirContents(srcDir, destDir, worktreePath string) (int, error) {
 	return count, nil
 }
 
+// Apply copies and symlinks the shared files into a worktree, layering the
+// overlays that match vars.BranchName over the base, and records what it
+// wrote in the worktree's manifest (see ManifestFile).
 func Apply(projectRoot, worktreePath string, cfg *config.Config, dryRun bool, vars *TemplateVars) (ApplyResult, error) {
-	copied, err := ApplyCopy(projectRoot, worktreePath, cfg, dryRun, vars)
+	var rec *manifestRecorder
+	if !dryRun {
+		rec = &manifestRecorder{worktree: worktreePath}
+	}
+
+	copied, err := applyCopy(projectRoot, worktreePath, cfg, dryRun, vars, rec)
 	if err != nil {
+		if fscopy.IsReflinkUnsupported(err) {
+			err = fmt.Errorf("%w; set copy_mode to auto to copy where cloning isn't possible", err)
+		}
 		return ApplyResult{}, err
 	}
-	symlinked, err := ApplySymlinks(projectRoot, worktreePath, cfg, dryRun)
+	symlinked, err := applySymlinks(projectRoot, worktreePath, cfg, branchOf(vars), dryRun, rec)
 	if err != nil {
 		return ApplyResult{}, err
 	}
-	return ApplyResult{Copied: copied, Symlinked: symlinked}, nil
+
+	if rec != nil {
+		if err := updateManifest(worktreePath, rec.entries); err != nil {
+			return ApplyResult{}, fmt.Errorf("write %s: %w", ManifestFile, err)
+		}
+	}
+	result := ApplyResult{Copied: copied, Symlinked: symlinked}
+	if rec != nil {
+		result.Strategies = rec.strategies
+	}
+	return result, nil
+}
+
+// copyOptions returns how the copy at dest, relative to the worktree, gets
+// its content and what it keeps from the shared file, per copy_mode and
+// copy_preserve.
+func copyOptions(cfg *config.Config, dest string) fscopy.Options {
+	return fscopy.Options{
+		Times:    cfg.Preserves("times"),
+		Xattrs:   cfg.Preserves("xattrs"),
+		Owner:    cfg.Preserves("owner"),
+		Symlinks: cfg.Preserves("symlinks"),
+		Mode:     fscopy.Mode(copyModeFor(cfg, dest)),
+	}
+}
+
+// copyModeFor returns the copy mode for dest: that of the longest
+// copy_mode_paths pattern matching it or a directory containing it, else
+// copy_mode.
+func copyModeFor(cfg *config.Config, dest string) config.CopyMode {
+	mode, best := cfg.CopyMode, ""
+	rel := filepath.ToSlash(dest)
+	for pattern, m := range cfg.CopyModePaths {
+		longer := len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best)
+		if longer && globsMatch([]string{pattern}, rel) {
+			mode, best = m, pattern
+		}
+	}
+	if mode == "" {
+		return config.CopyAuto
+	}
+	return mode
+}
+
+// treeCopyOptions returns the options for copying the top-level directory
+// name whole, and whether one mode applies to everything in it. A
+// copy_mode_paths pattern that could match inside name, but not name
+// itself, means its files must be copied one at a time.
+func treeCopyOptions(cfg *config.Config, name string) (fscopy.Options, bool) {
+	opts := copyOptions(cfg, name)
+	for pattern, m := range cfg.CopyModePaths {
+		if fscopy.Mode(m) == opts.Mode || matchGlob(pattern, name) {
+			continue
+		}
+		first, _, _ := strings.Cut(pattern, "/")
+		if ok, _ := path.Match(first, name); ok || first == "**" {
+			return opts, false
+		}
+	}
+	return opts, true
+}
+
+// FormatStrategies lists how many files each copy strategy handled, most
+// used first, e.g. "1204 reflink, 3 copy".
+func FormatStrategies(strategies map[fscopy.Strategy]int) string {
+	names := slices.SortedFunc(maps.Keys(strategies), func(a, b fscopy.Strategy) int {
+		if strategies[a] != strategies[b] {
+			return strategies[b] - strategies[a]
+		}
+		return strings.Compare(string(a), string(b))
+	})
+	parts := make([]string, len(names))
+	for i, s := range names {
+		parts[i] = fmt.Sprintf("%d %s", strategies[s], s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeTreeCopy summarizes a CopyTree as its file count, size, and the
+// strategies used, e.g. "1204 files, 48 MB, reflink".
+func describeTreeCopy(stats fscopy.Stats) string {
+	desc := fmt.Sprintf("%d files, %s", stats.Files, ui.FormatBytes(uint64(stats.Bytes)))
+	switch len(stats.Strategies) {
+	case 0:
+		return desc
+	case 1:
+		for s := range stats.Strategies {
+			return desc + ", " + string(s)
+		}
+	}
+	return desc + ", " + FormatStrategies(stats.Strategies)
 }
 
 // logCopyDir logs a top-level directory copy once, collapsing nested files.
@@ -4,8 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/project/fscopy"
 )
 
 func TestApplyCopy(t *testing.T) {
@@ -202,7 +204,7 @@ func TestApplySymlinks(t *testing.T) {
 	}
 
 	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
-	if _, err := ApplySymlinks(root, wt, cfg, false); err != nil {
+	if _, err := ApplySymlinks(root, wt, cfg, "", false); err != nil {
 		t.Fatalf("ApplySymlinks error: %v", err)
 	}
 
@@ -226,7 +228,7 @@ func TestApplySymlinksDryRun(t *testing.T) {
 	}
 
 	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
-	if _, err := ApplySymlinks(root, wt, cfg, true); err != nil {
+	if _, err := ApplySymlinks(root, wt, cfg, "", true); err != nil {
 		t.Fatalf("ApplySymlinks dry-run error: %v", err)
 	}
 
@@ -240,7 +242,7 @@ func TestApplySymlinksMissingDir(t *testing.T) {
 	wt := t.TempDir()
 
 	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
-	if _, err := ApplySymlinks(root, wt, cfg, false); err != nil {
+	if _, err := ApplySymlinks(root, wt, cfg, "", false); err != nil {
 		t.Fatalf("ApplySymlinks with missing dir should not error: %v", err)
 	}
 }
@@ -338,7 +340,7 @@ func TestApplySymlinksDirectoryConflict(t *testing.T) {
 	}
 
 	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
-	count, err := ApplySymlinks(root, wt, cfg, false)
+	count, err := ApplySymlinks(root, wt, cfg, "", false)
 	if err != nil {
 		t.Fatalf("ApplySymlinks error: %v", err)
 	}
@@ -390,3 +392,109 @@ func TestApplyCopyNonTemplateFilesCopiedAsIs(t *testing.T) {
 		t.Errorf("non-template file was modified: got %q, want %q", got, content)
 	}
 }
+
+func TestApplyCopyPreservesMetadata(t *testing.T) {
+	root := t.TempDir()
+	wt := t.TempDir()
+	copyDir := filepath.Join(root, "shared", "copy")
+	writeFile(t, filepath.Join(copyDir, "cache", "obj.o"), "object")
+	if err := os.Symlink("obj.o", filepath.Join(copyDir, "cache", "latest")); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(copyDir, "cache", "obj.o"), mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{SharedDir: "shared"}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(wt, "cache", "obj.o")); err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("obj.o mtime = %v (%v), want %v", info.ModTime(), err, mtime)
+	}
+	if target, err := os.Readlink(filepath.Join(wt, "cache", "latest")); err != nil || target != "obj.o" {
+		t.Errorf("latest = %q, %v; want a symlink to obj.o", target, err)
+	}
+	if stale, err := StaleEntries(root, wt, cfg); err != nil || len(stale) != 0 {
+		t.Errorf("StaleEntries() = %v, %v", stale, err)
+	}
+
+	// copy_preserve: [none] gives plain copies that follow symlinks.
+	wt2 := t.TempDir()
+	cfg.CopyPreserve = []string{"none"}
+	if _, err := Apply(root, wt2, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(filepath.Join(wt2, "cache", "latest")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("latest should be a regular file without symlinks preserved: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(wt2, "cache", "obj.o")); err != nil || info.ModTime().Equal(mtime) {
+		t.Errorf("obj.o kept its mtime without times preserved: %v", err)
+	}
+}
+
+func TestApplyCopyModes(t *testing.T) {
+	root := t.TempDir()
+	wt := filepath.Join(root, "worktrees", "main")
+	copyDir := filepath.Join(root, "shared", "copy")
+	writeFile(t, filepath.Join(copyDir, ".toolchains", "go", "bin", "go"), "toolchain")
+	writeFile(t, filepath.Join(copyDir, "vendor", "lib", "a.go"), "a")
+	writeFile(t, filepath.Join(copyDir, "vendor", "bin", "tool"), "tool")
+	writeFile(t, filepath.Join(copyDir, ".env"), "A=1\n")
+
+	cfg := &config.Config{
+		SharedDir:     config.DefaultSharedDir,
+		CopyMode:      config.CopyBytes,
+		CopyModePaths: map[string]config.CopyMode{".toolchains": config.CopyHardlink, "vendor/bin": config.CopyHardlink},
+	}
+	result, err := Apply(root, wt, cfg, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Strategies[fscopy.StrategyHardlink] != 2 || result.Strategies[fscopy.StrategyByteCopy] != 2 {
+		t.Errorf("Strategies = %v, want 2 hardlink and 2 copy", result.Strategies)
+	}
+
+	linked := func(rel string) bool {
+		a, _ := os.Stat(filepath.Join(copyDir, rel))
+		b, err := os.Stat(filepath.Join(wt, rel))
+		return err == nil && os.SameFile(a, b)
+	}
+	for rel, want := range map[string]bool{
+		".toolchains/go/bin/go": true,
+		"vendor/bin/tool":       true,
+		"vendor/lib/a.go":       false,
+		".env":                  false,
+	} {
+		if got := linked(filepath.FromSlash(rel)); got != want {
+			t.Errorf("%s hard-linked = %t, want %t", rel, got, want)
+		}
+	}
+}
+
+func TestCopyModeFor(t *testing.T) {
+	cfg := &config.Config{CopyModePaths: map[string]config.CopyMode{
+		"vendor":       config.CopyHardlink,
+		"vendor/cache": config.CopyBytes,
+		"**/*.db":      config.CopyReflink,
+	}}
+	for dest, want := range map[string]config.CopyMode{
+		".env":                config.CopyAuto,
+		"vendor/lib/a.go":     config.CopyHardlink,
+		"vendor/cache/x":      config.CopyBytes,
+		"data/app.db":         config.CopyReflink,
+		"vendor/cache/big.db": config.CopyBytes,
+	} {
+		if got := copyModeFor(cfg, filepath.FromSlash(dest)); got != want {
+			t.Errorf("copyModeFor(%s) = %s, want %s", dest, got, want)
+		}
+	}
+
+	if _, uniform := treeCopyOptions(cfg, "vendor"); uniform {
+		t.Error("vendor has paths with other modes inside; want it copied file by file")
+	}
+	if _, uniform := treeCopyOptions(&config.Config{CopyModePaths: map[string]config.CopyMode{"vendor": config.CopyHardlink}}, "vendor"); !uniform {
+		t.Error("vendor has one mode; want it copied whole")
+	}
+}
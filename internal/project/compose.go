@@ -0,0 +1,182 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// composeBinary is the docker CLI that runs 'docker compose'.
+const composeBinary = "docker"
+
+// HookCompose is the WT_HOOK value for the 'docker compose' commands wt
+// runs.
+const HookCompose HookPoint = "compose"
+
+// composeStatusTimeout bounds 'docker compose ps', so a stopped or slow
+// Docker daemon can't hang 'wt status'. Tests shorten it.
+var composeStatusTimeout = 5 * time.Second
+
+// ComposeProjectName returns the compose project name for the target
+// worktree: compose.project_name with template variables expanded, or
+// <project dir>-<worktree id>, lowercased and with characters compose
+// rejects replaced by dashes.
+func ComposeProjectName(cfg *config.Config, target HookTarget) string {
+	vars := NewTemplateVars(target.ProjectRoot, target.WorktreePath, target.Branch)
+	name := filepath.Base(vars.ProjectRoot) + "-" + vars.WorktreeID
+	if cfg.Compose != nil && cfg.Compose.ProjectName != "" {
+		name = ProcessTemplate(cfg.Compose.ProjectName, vars)
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	// Compose names must start with a letter or digit.
+	return strings.TrimLeft(b.String(), "_-")
+}
+
+// withCompose adds ${COMPOSE_PROJECT_NAME} to vars when compose is
+// configured. vars is copied, never modified.
+func withCompose(cfg *config.Config, vars *TemplateVars) *TemplateVars {
+	if vars == nil || vars.ComposeProject != "" || cfg.Compose == nil {
+		return vars
+	}
+	v := *vars
+	v.ComposeProject = ComposeProjectName(cfg, HookTarget{ProjectRoot: v.ProjectRoot, WorktreePath: v.WorktreePath, Branch: v.BranchName})
+	return &v
+}
+
+// composeCommand builds 'docker compose' with the project name, files, and
+// profiles from cfg, running args in the target worktree.
+func composeCommand(ctx context.Context, cfg *config.Config, target HookTarget, args ...string) *exec.Cmd {
+	name := ComposeProjectName(cfg, target)
+	argv := []string{"compose", "--project-name", name}
+	for _, f := range cfg.Compose.Files {
+		argv = append(argv, "--file", f)
+	}
+	for _, p := range cfg.Compose.Profiles {
+		argv = append(argv, "--profile", p)
+	}
+	cmd := exec.CommandContext(ctx, composeBinary, append(argv, args...)...)
+	cmd.Dir = target.WorktreePath
+	// Compose files can interpolate the same variables hooks get.
+	cmd.Env = hookEnv(cfg, HookCompose, target)
+	return cmd
+}
+
+// ComposeUp starts the worktree's compose project in the background,
+// before its setup hooks run. It does nothing unless compose is configured
+// with up enabled.
+func ComposeUp(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool) error {
+	if !cfg.Compose.UpEnabled() {
+		return nil
+	}
+	return runCompose(ctx, cfg, target, dryRun, "Starting", "up", "--detach")
+}
+
+// ComposeDown stops the worktree's compose project after its teardown
+// hooks, removing its named volumes when compose.volumes is set.
+func ComposeDown(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool) error {
+	if !cfg.Compose.DownEnabled() {
+		return nil
+	}
+	args := []string{"down", "--remove-orphans"}
+	if cfg.Compose.Volumes {
+		args = append(args, "--volumes")
+	}
+	return runCompose(ctx, cfg, target, dryRun, "Stopping", args...)
+}
+
+func runCompose(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool, verb string, args ...string) error {
+	cmd := composeCommand(ctx, cfg, target, args...)
+	ui.Step(fmt.Sprintf("%s compose project: %s", verb, ComposeProjectName(cfg, target)))
+	cmdStr := strings.Join(cmd.Args, " ")
+	if dryRun {
+		ui.DryRunNotice("exec: " + cmdStr)
+		return nil
+	}
+	ui.Command(cmdStr)
+	cmd.Stdout = ui.Output
+	cmd.Stderr = ui.Output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", cmdStr, err)
+	}
+	return nil
+}
+
+// ComposeState counts the containers of a compose project.
+type ComposeState struct {
+	Running int
+	Total   int
+}
+
+// ComposeStatus lists the containers of the worktree's compose project,
+// stopped ones included. It gives up after composeStatusTimeout.
+func ComposeStatus(ctx context.Context, cfg *config.Config, target HookTarget) (ComposeState, error) {
+	ctx, cancel := context.WithTimeout(ctx, composeStatusTimeout)
+	defer cancel()
+
+	cmd := composeCommand(ctx, cfg, target, "ps", "--all", "--format", "json")
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return ComposeState{}, fmt.Errorf("%s: no answer from docker after %s", strings.Join(cmd.Args, " "), composeStatusTimeout)
+		}
+		return ComposeState{}, fmt.Errorf("%s: %w\n%s", strings.Join(cmd.Args, " "), err, stderr.String())
+	}
+	return parseComposePS(stdout.Bytes())
+}
+
+// parseComposePS reads 'docker compose ps --format json', which older
+// versions print as one JSON array and newer ones as a JSON object per line.
+func parseComposePS(out []byte) (ComposeState, error) {
+	type container struct {
+		State string `json:"State"`
+	}
+	var containers []container
+	trimmed := bytes.TrimSpace(out)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &containers); err != nil {
+			return ComposeState{}, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var c container
+			if err := json.Unmarshal(line, &c); err != nil {
+				return ComposeState{}, err
+			}
+			containers = append(containers, c)
+		}
+	}
+
+	state := ComposeState{Total: len(containers)}
+	for _, c := range containers {
+		if c.State == "running" {
+			state.Running++
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,167 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestComposeProjectName(t *testing.T) {
+	target := HookTarget{ProjectRoot: "/src/My.App", WorktreePath: "/src/My.App/worktrees/feature/x", Branch: "feature/X"}
+	tests := []struct {
+		name    string
+		compose *config.Compose
+		want    string
+	}{
+		{"default", &config.Compose{}, "my-app-feature-x"},
+		{"template", &config.Compose{ProjectName: "shop_${WORKTREE_ID}"}, "shop_feature-x"},
+		{"leading dash trimmed", &config.Compose{ProjectName: "-${BRANCH_NAME}"}, "feature-x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Compose: tt.compose}
+			if got := ComposeProjectName(cfg, target); got != tt.want {
+				t.Errorf("ComposeProjectName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseComposePS(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want ComposeState
+	}{
+		{"empty", "", ComposeState{}},
+		{"array", `[{"Name":"a","State":"running"},{"Name":"b","State":"exited"}]`, ComposeState{Running: 1, Total: 2}},
+		{"lines", "{\"State\":\"running\"}\n{\"State\":\"running\"}\n", ComposeState{Running: 2, Total: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseComposePS([]byte(tt.out))
+			if err != nil || got != tt.want {
+				t.Errorf("parseComposePS = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// stubDocker puts a docker on PATH that appends its arguments and
+// COMPOSE_PROJECT_NAME to the returned log file.
+func stubDocker(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	log := filepath.Join(bin, "docker.log")
+	script := "#!/bin/sh\necho \"$COMPOSE_PROJECT_NAME $*\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestComposeUpDown(t *testing.T) {
+	log := stubDocker(t)
+	root := t.TempDir()
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	off := false
+	cfg := &config.Config{Compose: &config.Compose{ProjectName: "app-${WORKTREE_ID}", Files: []string{"compose.yml"}, Volumes: true}}
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt, Branch: "main"}
+
+	ctx := context.Background()
+	if err := ComposeUp(ctx, cfg, target, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := ComposeDown(ctx, cfg, target, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := ComposeUp(ctx, cfg, target, true); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Compose.Down = &off
+	if err := ComposeDown(ctx, cfg, target, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "app-main compose --project-name app-main --file compose.yml up --detach\n" +
+		"app-main compose --project-name app-main --file compose.yml down --remove-orphans --volumes\n"
+	if string(data) != want {
+		t.Errorf("docker calls:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestComposeStatus(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho \"$WT_HOOK $COMPOSE_PROJECT_NAME\" > env.txt\n" +
+		"[ -n \"$HANG\" ] && exec sleep 10\n" +
+		"echo '{\"State\":\"running\"}'\necho '{\"State\":\"exited\"}'\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	root := t.TempDir()
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Compose: &config.Compose{ProjectName: "app-${WORKTREE_ID}"}}
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt, Branch: "main"}
+
+	state, err := ComposeStatus(context.Background(), cfg, target)
+	if err != nil || state != (ComposeState{Running: 1, Total: 2}) {
+		t.Errorf("ComposeStatus() = %+v, %v; want 1 of 2 running", state, err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, "env.txt")); string(got) != "compose app-main\n" {
+		t.Errorf("docker saw WT_HOOK and COMPOSE_PROJECT_NAME %q, want the hook environment", got)
+	}
+
+	orig := composeStatusTimeout
+	composeStatusTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { composeStatusTimeout = orig })
+	t.Setenv("HANG", "1")
+	start := time.Now()
+	if _, err := ComposeStatus(context.Background(), cfg, target); err == nil {
+		t.Error("ComposeStatus() of a hung docker succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ComposeStatus() took %s; want it to give up after the timeout", elapsed)
+	}
+}
+
+func TestHooksGetComposeProjectName(t *testing.T) {
+	root := t.TempDir()
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		Compose: &config.Compose{ProjectName: "app-${WORKTREE_ID}"},
+		Setup:   []config.Hook{{Run: `echo "$COMPOSE_PROJECT_NAME" > name.txt`}},
+	}
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt, Branch: "main"}
+	if err := RunSetupHooks(context.Background(), cfg, target, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(filepath.Join(wt, "name.txt")); err != nil || strings.TrimSpace(string(got)) != "app-main" {
+		t.Errorf("hook saw COMPOSE_PROJECT_NAME %q, %v", got, err)
+	}
+
+	vars := NewTemplateVars(root, wt, "main")
+	if got := ProcessTemplate("COMPOSE_PROJECT_NAME=${COMPOSE_PROJECT_NAME}", *withCompose(cfg, &vars)); got != "COMPOSE_PROJECT_NAME=app-main" {
+		t.Errorf("template rendered %q", got)
+	}
+}
@@ -0,0 +1,233 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+	isatty "github.com/mattn/go-isatty"
+)
+
+// DriftStatus describes how a worktree's copy of a shared file differs from
+// what apply would write.
+type DriftStatus string
+
+const (
+	DriftMissing  DriftStatus = "missing"
+	DriftModified DriftStatus = "modified"
+)
+
+// Drift is a copied file whose worktree version no longer matches
+// shared/copy (after template substitution).
+type Drift struct {
+	Rel    string // destination, relative to the worktree
+	Source string // file under shared/copy it is rendered from
+	Status DriftStatus
+	Want   []byte // content apply would write
+	Have   []byte // current worktree content; nil when missing
+
+	secrets *Secrets // redacts secret values from Diff
+}
+
+// Diff renders the drift as a unified diff from the rendered shared file to
+// the worktree file, labelled with paths relative to projectRoot. Secret
+// values are shown as their ${secret:NAME} reference.
+func (d Drift) Diff(projectRoot, worktreePath string) string {
+	aName := relOrAbs(projectRoot, d.Source)
+	bName := "/dev/null"
+	if d.Status != DriftMissing {
+		bName = relOrAbs(projectRoot, filepath.Join(worktreePath, d.Rel))
+	}
+	want := []byte(d.secrets.Redact(string(d.Want)))
+	var have []byte
+	if d.Have != nil {
+		have = []byte(d.secrets.Redact(string(d.Have)))
+	}
+	return unifiedDiff(aName, bName, want, have)
+}
+
+// CheckDrift compares every file apply would copy into worktreePath, from
+// the base and any overlays matching vars.BranchName, with the worktree's
+// current version and returns those that differ, in walk order. It never
+// writes.
+func CheckDrift(projectRoot, worktreePath string, cfg *config.Config, vars *TemplateVars) ([]Drift, error) {
+	vars = withCompose(cfg, withSecrets(projectRoot, cfg, vars))
+	drifts, err := checkDrift(projectRoot, worktreePath, cfg, vars)
+	if vars != nil {
+		for i := range drifts {
+			drifts[i].secrets = vars.Secrets
+		}
+	}
+	return drifts, err
+}
+
+func checkDrift(projectRoot, worktreePath string, cfg *config.Config, vars *TemplateVars) ([]Drift, error) {
+	layers := Layers(projectRoot, cfg, branchOf(vars))
+	if cfg.Shared.HasRules() {
+		return checkRuleDrift(projectRoot, worktreePath, cfg, layers, vars)
+	}
+	plan, err := planCopies(layers, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	for i, layer := range layers {
+		copyDir := filepath.Join(layer.Dir, "copy")
+		if _, err := os.Stat(copyDir); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(copyDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(copyDir, path)
+			if err != nil {
+				return err
+			}
+			if !plan.owns(i, copyDest(rel, vars)) {
+				return nil
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if vars != nil && IsTemplateFile(rel) {
+				rel = StripTemplateExt(rel)
+				rendered, err := RenderTemplate(string(want), *vars)
+				if err != nil {
+					return fmt.Errorf("%s: %w", rel, err)
+				}
+				want = []byte(rendered)
+			}
+
+			drift, err := compareCopy(worktreePath, rel, path, want)
+			if drift != nil {
+				drifts = append(drifts, *drift)
+			}
+			return err
+		})
+		if err != nil {
+			return drifts, err
+		}
+	}
+	return drifts, nil
+}
+
+// checkRuleDrift is CheckDrift for the items placed by shared rules. Hard
+// links are compared by content like copies.
+func checkRuleDrift(projectRoot, worktreePath string, cfg *config.Config, layers []Layer, vars *TemplateVars) ([]Drift, error) {
+	plan, err := planRules(projectRoot, cfg, layers, vars)
+	if err != nil {
+		return nil, err
+	}
+	var drifts []Drift
+	for _, it := range plan.applied(false) {
+		want, err := it.want(vars)
+		if err != nil {
+			return drifts, err
+		}
+		drift, err := compareCopy(worktreePath, it.Dest, it.Src, want)
+		if err != nil {
+			return drifts, err
+		}
+		if drift != nil {
+			drifts = append(drifts, *drift)
+		}
+	}
+	return drifts, nil
+}
+
+// compareCopy returns the drift of the worktree file at rel from want, or
+// nil when it matches.
+func compareCopy(worktreePath, rel, source string, want []byte) (*Drift, error) {
+	have, err := os.ReadFile(filepath.Join(worktreePath, rel))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return &Drift{Rel: rel, Source: source, Status: DriftMissing, Want: want}, nil
+	case err != nil:
+		return nil, err
+	case !bytes.Equal(have, want):
+		return &Drift{Rel: rel, Source: source, Status: DriftModified, Want: want, Have: have}, nil
+	}
+	return nil, nil
+}
+
+// keepLocalCopy applies policy before apply overwrites dest with want. It
+// returns true when dest holds different content that should be left in
+// place; a backed-up dest is moved aside and false is returned.
+func keepLocalCopy(policy config.ConflictPolicy, rel, dest string, want []byte) (bool, error) {
+	if policy == config.ConflictOverwrite {
+		return false, nil
+	}
+	have, err := os.ReadFile(dest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(have, want) {
+		return false, nil
+	}
+
+	switch policy {
+	case config.ConflictSkipModified:
+		ui.Info("  skipped locally modified " + rel)
+		return true, nil
+	case config.ConflictPrompt:
+		overwrite, err := confirmOverwrite(rel)
+		if err != nil {
+			return false, err
+		}
+		if !overwrite {
+			ui.Info("  kept local " + rel)
+			return true, nil
+		}
+	case config.ConflictBackup:
+		backup := backupPath(dest)
+		if err := os.Rename(dest, backup); err != nil {
+			return false, err
+		}
+		ui.Info(fmt.Sprintf("  backed up %s to %s", rel, filepath.Base(backup)))
+	}
+	return false, nil
+}
+
+// confirmOverwrite asks whether to replace a locally modified file. Without
+// a terminal to ask on, the file is kept.
+var confirmOverwrite = func(rel string) (bool, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		ui.Warning("Not a terminal; keeping locally modified " + rel)
+		return false, nil
+	}
+	prompter := &ui.InteractivePrompter{}
+	return prompter.Confirm(rel + " differs from shared/copy. Overwrite it?")
+}
+
+// backupPath returns a free name to move path aside to: path.bak, then
+// path.bak.1, path.bak.2, and so on.
+func backupPath(path string) string {
+	candidate := path + ".bak"
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(candidate); errors.Is(err, fs.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.bak.%d", path, i)
+	}
+}
+
+// relOrAbs returns path relative to base when it is inside base.
+func relOrAbs(base, path string) string {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return path
+	}
+	return rel
+}
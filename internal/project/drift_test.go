@@ -0,0 +1,132 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+// writeDriftFixture creates a shared .env.template and plain file.txt, and
+// returns the project root and a worktree with both applied.
+func writeDriftFixture(t *testing.T) (root, wt string, vars TemplateVars) {
+	t.Helper()
+	root, wt = t.TempDir(), t.TempDir()
+	copyDir := filepath.Join(root, "shared", "copy")
+	if err := os.MkdirAll(copyDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(copyDir, ".env.template"), []byte("ID=${WORKTREE_ID}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(copyDir, "file.txt"), []byte("shared\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vars = NewTemplateVars(root, wt, "feature/x")
+	if _, err := ApplyCopy(root, wt, &config.Config{SharedDir: config.DefaultSharedDir}, false, &vars); err != nil {
+		t.Fatal(err)
+	}
+	return root, wt, vars
+}
+
+func TestCheckDrift(t *testing.T) {
+	root, wt, vars := writeDriftFixture(t)
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+
+	drifts, err := CheckDrift(root, wt, cfg, &vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("freshly applied worktree has drift: %+v", drifts)
+	}
+
+	if err := os.WriteFile(filepath.Join(wt, ".env"), []byte("ID=local\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(wt, "file.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	drifts, err = CheckDrift(root, wt, cfg, &vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 2 {
+		t.Fatalf("got %d drifts, want 2: %+v", len(drifts), drifts)
+	}
+	if d := drifts[0]; d.Rel != ".env" || d.Status != DriftModified {
+		t.Errorf("drifts[0] = %s %s, want modified .env", d.Status, d.Rel)
+	}
+	if d := drifts[1]; d.Rel != "file.txt" || d.Status != DriftMissing {
+		t.Errorf("drifts[1] = %s %s, want missing file.txt", d.Status, d.Rel)
+	}
+
+	diff := drifts[0].Diff(root, wt)
+	for _, want := range []string{"--- " + filepath.Join("shared", "copy", ".env.template"), "-ID=feature-x", "+ID=local"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+	if diff := drifts[1].Diff(root, wt); !strings.Contains(diff, "+++ /dev/null") {
+		t.Errorf("missing file diff should target /dev/null:\n%s", diff)
+	}
+}
+
+func TestApplyCopyConflictPolicies(t *testing.T) {
+	tests := []struct {
+		policy  config.ConflictPolicy
+		confirm bool
+		want    string
+		backup  bool
+	}{
+		{config.ConflictOverwrite, false, "ID=feature-x\n", false},
+		{config.ConflictSkipModified, false, "ID=local\n", false},
+		{config.ConflictPrompt, false, "ID=local\n", false},
+		{config.ConflictPrompt, true, "ID=feature-x\n", false},
+		{config.ConflictBackup, false, "ID=feature-x\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			root, wt, vars := writeDriftFixture(t)
+			env := filepath.Join(wt, ".env")
+			if err := os.WriteFile(env, []byte("ID=local\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			orig := confirmOverwrite
+			confirmOverwrite = func(string) (bool, error) { return tt.confirm, nil }
+			t.Cleanup(func() { confirmOverwrite = orig })
+
+			cfg := &config.Config{SharedDir: config.DefaultSharedDir, ApplyConflict: tt.policy}
+			if _, err := ApplyCopy(root, wt, cfg, false, &vars); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, _ := os.ReadFile(env); string(got) != tt.want {
+				t.Errorf(".env = %q, want %q", got, tt.want)
+			}
+			got, err := os.ReadFile(env + ".bak")
+			if tt.backup && string(got) != "ID=local\n" {
+				t.Errorf(".env.bak = %q, %v; want the local version", got, err)
+			}
+			if !tt.backup && err == nil {
+				t.Error("unexpected .env.bak")
+			}
+		})
+	}
+}
+
+func TestBackupPathAvoidsExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path+".bak", nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := backupPath(path); got != path+".bak.1" {
+		t.Errorf("backupPath() = %q, want %q", got, path+".bak.1")
+	}
+}
@@ -14,23 +14,24 @@ import (
 var excludePatterns = []string{
 	SetupStateFile,
 	SetupLogFile,
+	ManifestFile,
 }
 
-// EnsureGitExclude ensures that wt-managed file patterns are listed in
-// the repository's info/exclude file so they don't appear as untracked.
-func EnsureGitExclude(gitDir string, dryRun bool) error {
-	infoDir := filepath.Join(gitDir, "info")
-	excludePath := filepath.Join(infoDir, "exclude")
+// MissingGitExcludes returns the wt-managed patterns not yet listed in the
+// repository's info/exclude file.
+func MissingGitExcludes(gitDir string) ([]string, error) {
+	_, missing, err := readGitExclude(filepath.Join(gitDir, "info", "exclude"))
+	return missing, err
+}
 
-	// Read existing content.
+func readGitExclude(excludePath string) ([]byte, []string, error) {
 	existing, err := os.ReadFile(excludePath)
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return err
+		return nil, nil, err
 	}
 
-	lines := strings.Split(string(existing), "\n")
 	present := make(map[string]bool)
-	for _, line := range lines {
+	for _, line := range strings.Split(string(existing), "\n") {
 		present[strings.TrimSpace(line)] = true
 	}
 
@@ -40,6 +41,19 @@ func EnsureGitExclude(gitDir string, dryRun bool) error {
 			missing = append(missing, p)
 		}
 	}
+	return existing, missing, nil
+}
+
+// EnsureGitExclude ensures that wt-managed file patterns are listed in
+// the repository's info/exclude file so they don't appear as untracked.
+func EnsureGitExclude(gitDir string, dryRun bool) error {
+	infoDir := filepath.Join(gitDir, "info")
+	excludePath := filepath.Join(infoDir, "exclude")
+
+	existing, missing, err := readGitExclude(excludePath)
+	if err != nil {
+		return err
+	}
 
 	if len(missing) == 0 {
 		return nil
@@ -124,3 +124,26 @@ func TestEnsureGitExclude_DryRun(t *testing.T) {
 		t.Error("dry run should not create info directory")
 	}
 }
+
+func TestMissingGitExcludes(t *testing.T) {
+	gitDir := t.TempDir()
+
+	missing, err := MissingGitExcludes(gitDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != len(excludePatterns) {
+		t.Errorf("without an exclude file, missing = %v, want all of %v", missing, excludePatterns)
+	}
+
+	if err := EnsureGitExclude(gitDir, false); err != nil {
+		t.Fatal(err)
+	}
+	missing, err = MissingGitExcludes(gitDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("after EnsureGitExclude, missing = %v", missing)
+	}
+}
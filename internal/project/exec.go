@@ -0,0 +1,98 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// HookExec is the WT_HOOK value for commands started by 'wt exec'.
+const HookExec HookPoint = "exec"
+
+// ExecResult is the outcome of running a command in one worktree.
+type ExecResult struct {
+	Branch   string
+	ExitCode int // -1 when the command could not be started
+	Err      error
+	Elapsed  time.Duration
+}
+
+// ExecInWorktrees runs argv directly (not through a shell) in each target,
+// at most jobs at a time, with the environment hooks get. Every line of output is prefixed with the branch;
+// stdout and stderr keep their streams so the output can be piped. Results
+// are returned in target order. Commands never read from stdin, since
+// concurrent runs would race for it.
+func ExecInWorktrees(ctx context.Context, cfg *config.Config, targets []HookTarget, argv []string, jobs int, dryRun bool) []ExecResult {
+	results := make([]ExecResult, len(targets))
+	if dryRun {
+		for i, t := range targets {
+			ui.DryRunNotice("exec (" + t.Branch + "): " + strings.Join(argv, " "))
+			results[i] = ExecResult{Branch: t.Branch}
+		}
+		return results
+	}
+
+	jobs = max(jobs, 1)
+	sem := make(chan struct{}, jobs)
+	var (
+		wg       sync.WaitGroup
+		outputMu sync.Mutex
+	)
+	for i, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			stdout := &prefixWriter{out: os.Stdout, prefix: t.Branch, mu: &outputMu}
+			stderr := &prefixWriter{out: ui.Output, prefix: t.Branch, mu: &outputMu}
+
+			cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+			cmd.Dir = t.WorktreePath
+			cmd.Env = hookEnv(cfg, HookExec, t)
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+
+			start := time.Now()
+			err := cmd.Run()
+			stdout.flush()
+			stderr.flush()
+			results[i] = ExecResult{Branch: t.Branch, ExitCode: exitCode(err), Err: err, Elapsed: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// exitCode extracts a process exit status from err: 0 for success, the
+// status for a process that exited, and -1 if it never ran.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// Describe renders the result for a summary line, e.g. "exit 2".
+func (r ExecResult) Describe() string {
+	switch {
+	case r.Err == nil:
+		return "ok"
+	case r.ExitCode >= 0:
+		return fmt.Sprintf("exit %d", r.ExitCode)
+	}
+	return r.Err.Error()
+}
@@ -0,0 +1,75 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+func TestExecInWorktrees(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	targets := []HookTarget{
+		{WorktreePath: t.TempDir(), Branch: "main"},
+		{WorktreePath: t.TempDir(), Branch: "broken"},
+	}
+	argv := []string{"sh", "-c", `echo "on $WT_BRANCH" >&2; test "$WT_BRANCH" != broken || exit 3`}
+
+	results := ExecInWorktrees(context.Background(), &config.Config{}, targets, argv, 2, false)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if r := results[0]; r.Branch != "main" || r.Err != nil || r.ExitCode != 0 {
+		t.Errorf("main result = %+v, want success", r)
+	}
+	if r := results[1]; r.Branch != "broken" || r.ExitCode != 3 || r.Describe() != "exit 3" {
+		t.Errorf("broken result = %+v, want exit 3", r)
+	}
+	for _, want := range []string{"[main] on main", "[broken] on broken"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestExecInWorktreesComposeProject(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	cfg := &config.Config{Compose: &config.Compose{ProjectName: "app-${WORKTREE_ID}"}}
+	targets := []HookTarget{{ProjectRoot: t.TempDir(), WorktreePath: t.TempDir(), Branch: "main"}}
+	results := ExecInWorktrees(context.Background(), cfg, targets,
+		[]string{"sh", "-c", `echo "project $COMPOSE_PROJECT_NAME" >&2`}, 1, false)
+	if r := results[0]; r.Err != nil {
+		t.Fatalf("result = %+v", r)
+	}
+	if want := "[main] project app-main"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestExecInWorktreesCommandNotFound(t *testing.T) {
+	results := ExecInWorktrees(context.Background(), &config.Config{}, []HookTarget{{WorktreePath: t.TempDir(), Branch: "main"}},
+		[]string{"wt-no-such-command"}, 1, false)
+	if r := results[0]; r.ExitCode != -1 || r.Err == nil {
+		t.Errorf("result = %+v, want start failure", r)
+	}
+}
+
+func TestExecInWorktreesDryRun(t *testing.T) {
+	dir := t.TempDir()
+	results := ExecInWorktrees(context.Background(), &config.Config{}, []HookTarget{{WorktreePath: dir, Branch: "main"}},
+		[]string{"sh", "-c", "exit 1"}, 1, true)
+	if r := results[0]; r.Err != nil {
+		t.Errorf("dry-run should not execute: %+v", r)
+	}
+}
@@ -0,0 +1,146 @@
+package fscopy
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// treeWorkers bounds the goroutines copying files in copyTree. The copies
+// wait on the disk more than the CPU, so a few more than the CPU count
+// keeps it busy.
+var treeWorkers = min(max(runtime.NumCPU(), 4), 32)
+
+type treeEntry struct {
+	src, dst string
+	info     os.FileInfo
+}
+
+// copyTree copies the tree at src to dst, which must not exist. The walk
+// creates the directories and symlinks and hands each regular file to a
+// bounded pool of workers that copy it as opts.Mode says. The first error
+// stops the walk.
+func copyTree(src, dst string, opts Options) (Stats, error) {
+	var (
+		stats    Stats
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	failed := make(chan struct{})
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			close(failed)
+		}
+	}
+
+	jobs := make(chan treeEntry)
+	for range treeWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				strategy, err := copyTreeFile(job, opts)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				mu.Lock()
+				stats.add(strategy, job.info.Size())
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var dirs []treeEntry
+	walkErr := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		select {
+		case <-failed:
+			return filepath.SkipAll
+		default:
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		entry := treeEntry{src: p, dst: filepath.Join(dst, rel)}
+		if entry.info, err = d.Info(); err != nil {
+			return err
+		}
+
+		mode := entry.info.Mode()
+		if mode&os.ModeSymlink != 0 && !opts.Symlinks {
+			// Follow links to files; a link to a directory is kept as a
+			// link rather than copying what may be outside the tree.
+			if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+				entry.info, mode = info, info.Mode()
+			}
+		}
+		switch {
+		case mode.IsDir():
+			// Owner write lets the files in; the mode is set once they are.
+			if err := os.Mkdir(entry.dst, mode.Perm()|0o700); err != nil {
+				return err
+			}
+			dirs = append(dirs, entry)
+		case mode&os.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			if err := os.Symlink(target, entry.dst); err != nil {
+				return err
+			}
+			return preserveMetadata(p, entry.dst, entry.info, opts)
+		case mode.IsRegular():
+			jobs <- entry
+		}
+		// Sockets, FIFOs, and devices are not copied.
+		return nil
+	})
+	close(jobs)
+	wg.Wait()
+	if walkErr != nil {
+		return stats, walkErr
+	}
+	if firstErr != nil {
+		return stats, firstErr
+	}
+
+	// Deepest first, so setting a directory's times isn't undone by
+	// changes inside it.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		d := dirs[i]
+		if err := os.Chmod(d.dst, d.info.Mode()); err != nil {
+			return stats, err
+		}
+		if err := preserveMetadata(d.src, d.dst, d.info, opts); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// copyTreeFile copies one regular file for copyTree and reports the
+// strategy that worked.
+func copyTreeFile(job treeEntry, opts Options) (Strategy, error) {
+	strategy, err := copyContent(job.src, job.dst, job.info, opts.Mode)
+	if err != nil || strategy == StrategyHardlink {
+		return strategy, err
+	}
+	if err := os.Chmod(job.dst, job.info.Mode()); err != nil {
+		return "", err
+	}
+	if err := preserveMetadata(job.src, job.dst, job.info, opts); err != nil {
+		return "", err
+	}
+	return strategy, nil
+}
@@ -0,0 +1,77 @@
+//go:build linux
+
+package fscopy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyTreeManyFiles(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "vendor")
+	dst := filepath.Join(t.TempDir(), "vendor")
+	old := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := range 200 {
+		p := filepath.Join(src, fmt.Sprintf("pkg%d", i%10), fmt.Sprintf("f%d.go", i))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(p), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(filepath.Join(src, "pkg3"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := CopyTree(src, dst, Preserve)
+	if err != nil {
+		t.Fatalf("CopyTree: %v", err)
+	}
+	if stats.Files != 200 {
+		t.Errorf("stats.Files = %d, want 200", stats.Files)
+	}
+	for i := range 200 {
+		p := filepath.Join(fmt.Sprintf("pkg%d", i%10), fmt.Sprintf("f%d.go", i))
+		got, err := os.ReadFile(filepath.Join(dst, p))
+		if err != nil || string(got) != filepath.Join(src, p) {
+			t.Fatalf("%s = %q, %v", p, got, err)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dst, "pkg3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Errorf("pkg3 mtime = %v, want %v", info.ModTime(), old)
+	}
+}
+
+func TestCopyTreeFailureLeavesNoDst(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read unreadable files")
+	}
+	src := filepath.Join(t.TempDir(), "tree")
+	dst := filepath.Join(t.TempDir(), "tree")
+	if err := os.MkdirAll(src, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "ok.txt"), []byte("ok"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "secret.txt"), []byte("no"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CopyTree(src, dst, Options{}); err == nil {
+		t.Fatal("CopyTree succeeded with an unreadable file")
+	}
+	for _, p := range []string{dst, dst + ".wtclone"} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("%s exists after a failed copy", p)
+		}
+	}
+}
@@ -10,8 +10,12 @@
 // that's the reflink working, not a bug.
 //
 // On Darwin, unix.Clonefile with flag 0 also preserves extended
-// attributes and ACLs on top of the mode bits that the byte-copy fallback
-// carries over. This is a forgiving superset of the fallback's behavior.
+// attributes and ACLs. Elsewhere CopyFile carries timestamps, extended
+// attributes, and ownership over itself, as selected by Options, so a
+// byte copy is as faithful as a clone.
+//
+// Options.Mode overrides the preference: require a reflink, hard-link
+// read-only trees instead of copying them, or always copy bytes.
 package fscopy
 
 import (
@@ -19,47 +23,154 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"syscall"
 )
 
 // errReflinkUnsupported signals that tryReflink could not clone on this
-// platform/filesystem pair and CopyFile should fall back to a byte copy.
-// It is intentionally unexported: CopyFile handles the fallback itself,
-// so no caller needs to distinguish this case.
+// platform/filesystem pair, or tryCopyRange could not copy in the kernel,
+// and the next strategy should be tried. Only ModeReflink lets it reach
+// callers, who can detect it with IsReflinkUnsupported.
 var errReflinkUnsupported = errors.New("fscopy: reflink not supported")
 
-// CopyFile copies src to dst. It first tries a filesystem-level reflink;
-// if the underlying filesystem or platform does not support cloning, it
-// falls back to a byte-for-byte copy. The destination inherits the
-// source's mode bits.
+// Mode selects how CopyFile and CopyTree copy a file's content. The zero
+// value behaves like ModeAuto.
+type Mode string
+
+const (
+	ModeAuto     Mode = "auto"     // reflink where the filesystem can, else the fastest copy
+	ModeReflink  Mode = "reflink"  // reflink, failing where the filesystem can't
+	ModeHardlink Mode = "hardlink" // hard link to the source, copying across filesystems
+	ModeCopy     Mode = "copy"     // an independent byte-for-byte copy
+)
+
+// CopyFile copies src to dst. The destination inherits the source's mode
+// bits, plus the metadata opts selects, and its content is copied as
+// opts.Mode says: by default with a filesystem-level reflink where
+// supported, falling back to an in-kernel copy (Linux) and then a
+// byte-for-byte copy. A hard link shares the source's inode, mode, and
+// metadata. With opts.Symlinks, a symlink at src is recreated at dst rather
+// than followed.
 //
 // CopyFile writes into "<dst>.wtclone" and atomically renames it into
 // place, so an interrupted run never leaves a half-written dst. Any
 // leftover tmp file from a prior crash is removed before the new attempt.
-func CopyFile(src, dst string) error {
-	srcInfo, err := os.Stat(src)
+// It returns the strategy that copied the content, or "" for a symlink.
+func CopyFile(src, dst string, opts Options) (Strategy, error) {
+	srcInfo, err := os.Lstat(src)
 	if err != nil {
-		return err
+		return "", err
+	}
+	if srcInfo.Mode()&os.ModeSymlink != 0 {
+		if opts.Symlinks {
+			return "", copySymlink(src, dst, srcInfo, opts)
+		}
+		if srcInfo, err = os.Stat(src); err != nil {
+			return "", err
+		}
 	}
 
 	tmp := dst + ".wtclone"
 	_ = os.Remove(tmp)
 
-	if err := tryReflink(src, tmp); err != nil {
-		if !errors.Is(err, errReflinkUnsupported) {
+	strategy, err := copyContent(src, tmp, srcInfo, opts.Mode)
+	if err != nil {
+		_ = os.Remove(tmp)
+		if errors.Is(err, errReflinkUnsupported) {
+			return "", fmt.Errorf("fscopy: %s -> %s: %w", src, dst, err)
+		}
+		return "", err
+	}
+
+	// A hard link is the source; changing it would change the source. And
+	// renaming over another link to it would do nothing, leaving tmp.
+	if strategy == StrategyHardlink {
+		if isSameFile(src, dst) {
+			_ = os.Remove(tmp)
+			return strategy, nil
+		}
+	} else {
+		if err := os.Chmod(tmp, srcInfo.Mode()); err != nil {
 			_ = os.Remove(tmp)
-			return fmt.Errorf("fscopy: reflink %s -> %s: %w", src, dst, err)
+			return "", err
 		}
-		if err := byteCopy(src, tmp, srcInfo.Mode()); err != nil {
+		if err := preserveMetadata(src, tmp, srcInfo, opts); err != nil {
 			_ = os.Remove(tmp)
-			return err
+			return "", fmt.Errorf("fscopy: preserve metadata of %s: %w", src, err)
 		}
 	}
 
-	if err := os.Chmod(tmp, srcInfo.Mode()); err != nil {
+	if err := os.Rename(tmp, dst); err != nil {
 		_ = os.Remove(tmp)
+		return "", err
+	}
+	return strategy, nil
+}
+
+// isSameFile reports whether a and b are the same file on disk.
+func isSameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
+
+// copyContent creates dst, which must not exist, with the content of the
+// regular file src as mode says, and reports the strategy used.
+func copyContent(src, dst string, info os.FileInfo, mode Mode) (Strategy, error) {
+	switch mode {
+	case ModeCopy:
+		return StrategyByteCopy, byteCopy(src, dst, info.Mode())
+	case ModeReflink:
+		if err := tryReflink(src, dst); err != nil {
+			return "", err
+		}
+		return StrategyReflink, nil
+	case ModeHardlink:
+		err := os.Link(src, dst)
+		if err == nil {
+			return StrategyHardlink, nil
+		}
+		// Hard links can't cross filesystems; copy as ModeAuto would.
+		if !errors.Is(err, syscall.EXDEV) {
+			return "", err
+		}
+	}
+
+	if err := tryReflink(src, dst); !errors.Is(err, errReflinkUnsupported) {
+		if err != nil {
+			return "", fmt.Errorf("fscopy: reflink %s -> %s: %w", src, dst, err)
+		}
+		return StrategyReflink, nil
+	}
+	if err := tryCopyRange(src, dst, info.Mode()); !errors.Is(err, errReflinkUnsupported) {
+		if err != nil {
+			return "", err
+		}
+		return StrategyCopyRange, nil
+	}
+	return StrategyByteCopy, byteCopy(src, dst, info.Mode())
+}
+
+// copySymlink recreates the symlink src at dst with the same target,
+// relative or absolute, via the same tmp-and-rename as CopyFile.
+func copySymlink(src, dst string, info os.FileInfo, opts Options) error {
+	target, err := os.Readlink(src)
+	if err != nil {
 		return err
 	}
 
+	tmp := dst + ".wtclone"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := preserveMetadata(src, tmp, info, opts); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("fscopy: preserve metadata of %s: %w", src, err)
+	}
 	if err := os.Rename(tmp, dst); err != nil {
 		_ = os.Remove(tmp)
 		return err
@@ -67,43 +178,75 @@ func CopyFile(src, dst string) error {
 	return nil
 }
 
-// CopyTree clones a directory in one filesystem reflink syscall, writing via
-// "<dst>.wtclone" + atomic rename. Returns an error for which IsReflinkUnsupported
-// is true when the FS can't clone trees; callers fall back to a per-file walk.
-func CopyTree(src, dst string) error {
-	if _, err := os.Stat(src); err != nil {
-		return err
+// Strategy is how a file's content was copied.
+type Strategy string
+
+const (
+	StrategyReflink   Strategy = "reflink"         // copy-on-write clone sharing the source's blocks
+	StrategyCopyRange Strategy = "copy_file_range" // copied in the kernel, without a trip through wt
+	StrategyByteCopy  Strategy = "copy"            // read and written by wt
+	StrategyHardlink  Strategy = "hardlink"        // another name for the source's inode
+)
+
+// Stats summarizes a CopyTree: the regular files and bytes copied, and how
+// many files each strategy handled.
+type Stats struct {
+	Files      int
+	Bytes      int64
+	Strategies map[Strategy]int
+}
+
+func (s *Stats) add(strategy Strategy, size int64) {
+	if s.Strategies == nil {
+		s.Strategies = make(map[Strategy]int)
 	}
+	s.Files++
+	s.Bytes += size
+	s.Strategies[strategy]++
+}
 
+// CopyTree copies the directory src to dst, which must not exist, writing
+// via "<dst>.wtclone" + atomic rename. Missing parents of dst are created.
+// On Darwin a tree that may be cloned is cloned in one clonefile(2) call;
+// otherwise a bounded pool of workers copies the files, each as opts.Mode
+// says. Symlinks are recreated, or with opts.Symlinks unset, links to
+// files are copied as files.
+func CopyTree(src, dst string, opts Options) (Stats, error) {
+	if _, err := os.Stat(src); err != nil {
+		return Stats{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return Stats{}, err
+	}
 	tmp := dst + ".wtclone"
 	_ = os.RemoveAll(tmp)
 
-	if err := tryCloneTree(src, tmp); err != nil {
-		if errors.Is(err, errReflinkUnsupported) {
-			return err
-		}
+	stats, err := tryCloneTree(src, tmp, opts)
+	if errors.Is(err, errReflinkUnsupported) {
 		_ = os.RemoveAll(tmp)
-		return fmt.Errorf("fscopy: clone tree %s -> %s: %w", src, dst, err)
+		stats, err = copyTree(src, tmp, opts)
+	}
+	if err != nil {
+		_ = os.RemoveAll(tmp)
+		return Stats{}, fmt.Errorf("fscopy: copy tree %s -> %s: %w", src, dst, err)
 	}
 
 	if err := os.Rename(tmp, dst); err != nil {
 		_ = os.RemoveAll(tmp)
-		return err
+		return Stats{}, err
 	}
-	return nil
+	return stats, nil
 }
 
-// IsReflinkUnsupported reports whether err indicates the platform or
-// filesystem does not support reflink/tree-clone. Callers of CopyTree
-// use this to decide whether to fall back to a per-file walk.
+// IsReflinkUnsupported reports whether err is ModeReflink failing because
+// the platform or filesystem cannot clone the file.
 func IsReflinkUnsupported(err error) bool {
 	return errors.Is(err, errReflinkUnsupported)
 }
 
-// byteCopy performs the non-reflink fallback: a plain io.Copy preserving
-// the source mode. Matches the semantics of the previous private copyFile
-// in internal/project/apply.go so existing callers see no behavior change
-// on platforms/filesystems without reflink support.
+// byteCopy performs the last fallback: a plain io.Copy preserving the
+// source mode. CopyFile applies any other metadata afterwards, whatever
+// the strategy.
 func byteCopy(src, dst string, mode os.FileMode) error {
 	in, err := os.Open(src)
 	if err != nil {
@@ -117,7 +260,8 @@ func byteCopy(src, dst string, mode os.FileMode) error {
 	}
 	defer func() { _ = out.Close() }()
 
-	if _, err := io.Copy(out, in); err != nil {
+	// Hide out's ReadFrom, which would use copy_file_range on Linux.
+	if _, err := io.Copy(struct{ io.Writer }{out}, in); err != nil {
 		return err
 	}
 	return nil
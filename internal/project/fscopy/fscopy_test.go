@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCopyFileContentAndMode(t *testing.T) {
@@ -24,7 +25,7 @@ func TestCopyFileContentAndMode(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := CopyFile(src, dst); err != nil {
+	if _, err := CopyFile(src, dst, Options{}); err != nil {
 		t.Fatalf("CopyFile: %v", err)
 	}
 
@@ -58,7 +59,7 @@ func TestCopyFileEmpty(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := CopyFile(src, dst); err != nil {
+	if _, err := CopyFile(src, dst, Options{}); err != nil {
 		t.Fatalf("CopyFile: %v", err)
 	}
 	info, err := os.Stat(dst)
@@ -81,7 +82,7 @@ func TestCopyFileOverwritesExistingDst(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := CopyFile(src, dst); err != nil {
+	if _, err := CopyFile(src, dst, Options{}); err != nil {
 		t.Fatalf("CopyFile: %v", err)
 	}
 	got, err := os.ReadFile(dst)
@@ -105,7 +106,7 @@ func TestCopyFileReclaimsStaleTmp(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := CopyFile(src, dst); err != nil {
+	if _, err := CopyFile(src, dst, Options{}); err != nil {
 		t.Fatalf("CopyFile: %v", err)
 	}
 	got, err := os.ReadFile(dst)
@@ -123,7 +124,7 @@ func TestCopyFileReclaimsStaleTmp(t *testing.T) {
 func TestCopyFileMissingSource(t *testing.T) {
 	dir := t.TempDir()
 	dst := filepath.Join(dir, "dst")
-	err := CopyFile(filepath.Join(dir, "nope"), dst)
+	_, err := CopyFile(filepath.Join(dir, "nope"), dst, Options{})
 	if err == nil {
 		t.Fatal("expected error for missing source")
 	}
@@ -185,30 +186,7 @@ func TestByteCopyRefusesExistingDst(t *testing.T) {
 	}
 }
 
-// skipUnlessReflinkTree skips the test when tryCloneTree reports the
-// current filesystem cannot clone trees. On macOS t.TempDir is APFS so
-// this runs; on Linux / non-APFS it returns errReflinkUnsupported.
-func skipUnlessReflinkTree(t *testing.T) {
-	t.Helper()
-	if runtime.GOOS != "darwin" {
-		t.Skip("tree-level reflink only implemented on darwin")
-	}
-	// Probe: try cloning an empty dir. If unsupported on the test FS, skip.
-	probeSrc := filepath.Join(t.TempDir(), "probe-src")
-	if err := os.Mkdir(probeSrc, 0o755); err != nil {
-		t.Fatal(err)
-	}
-	probeDst := filepath.Join(t.TempDir(), "probe-dst")
-	if err := CopyTree(probeSrc, probeDst); err != nil {
-		if errors.Is(err, errReflinkUnsupported) {
-			t.Skipf("tree reflink unsupported on this filesystem: %v", err)
-		}
-		t.Fatalf("probe CopyTree: %v", err)
-	}
-}
-
 func TestCopyTreeNested(t *testing.T) {
-	skipUnlessReflinkTree(t)
 	srcRoot := t.TempDir()
 	dstRoot := t.TempDir()
 	src := filepath.Join(srcRoot, "tree")
@@ -224,9 +202,20 @@ func TestCopyTreeNested(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := CopyTree(src, dst); err != nil {
+	stats, err := CopyTree(src, dst, Options{})
+	if err != nil {
 		t.Fatalf("CopyTree: %v", err)
 	}
+	if stats.Files != 2 || stats.Bytes != int64(len("top")+len("deep")) {
+		t.Errorf("stats = %d files, %d bytes; want 2 files, 7 bytes", stats.Files, stats.Bytes)
+	}
+	var byStrategy int
+	for _, n := range stats.Strategies {
+		byStrategy += n
+	}
+	if byStrategy != stats.Files {
+		t.Errorf("strategies %v don't add up to %d files", stats.Strategies, stats.Files)
+	}
 
 	got, err := os.ReadFile(filepath.Join(dst, "a", "top.txt"))
 	if err != nil || string(got) != "top" {
@@ -251,7 +240,6 @@ func TestCopyTreeNested(t *testing.T) {
 }
 
 func TestCopyTreePreservesSymlink(t *testing.T) {
-	skipUnlessReflinkTree(t)
 	srcRoot := t.TempDir()
 	dstRoot := t.TempDir()
 	src := filepath.Join(srcRoot, "tree")
@@ -267,7 +255,7 @@ func TestCopyTreePreservesSymlink(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := CopyTree(src, dst); err != nil {
+	if _, err := CopyTree(src, dst, Preserve); err != nil {
 		t.Fatalf("CopyTree: %v", err)
 	}
 
@@ -288,7 +276,6 @@ func TestCopyTreePreservesSymlink(t *testing.T) {
 }
 
 func TestCopyTreeExistingDst(t *testing.T) {
-	skipUnlessReflinkTree(t)
 	srcRoot := t.TempDir()
 	dstRoot := t.TempDir()
 	src := filepath.Join(srcRoot, "tree")
@@ -301,7 +288,7 @@ func TestCopyTreeExistingDst(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err := CopyTree(src, dst)
+	_, err := CopyTree(src, dst, Options{})
 	if err == nil {
 		t.Fatal("expected error when dst already exists")
 	}
@@ -326,7 +313,7 @@ func TestCopyFileMissingDestinationParent(t *testing.T) {
 		t.Fatal(err)
 	}
 	// CopyFile's contract does not create parent dirs; callers do that.
-	err := CopyFile(src, filepath.Join(dir, "missing", "dst"))
+	_, err := CopyFile(src, filepath.Join(dir, "missing", "dst"), Options{})
 	if err == nil {
 		t.Fatal("expected error when parent dir missing")
 	}
@@ -337,3 +324,134 @@ func TestCopyFileMissingDestinationParent(t *testing.T) {
 		t.Logf("note: error text = %v", err)
 	}
 }
+
+func TestCopyFilePreservesTimes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("cache"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(src, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	kept := filepath.Join(dir, "kept")
+	if _, err := CopyFile(src, kept, Preserve); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(kept); err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("mtime with Preserve = %v (%v), want %v", info.ModTime(), err, mtime)
+	}
+
+	fresh := filepath.Join(dir, "fresh")
+	if _, err := CopyFile(src, fresh, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(fresh); err != nil || info.ModTime().Equal(mtime) {
+		t.Errorf("mtime without Times = %v (%v), want a fresh time", info.ModTime(), err)
+	}
+}
+
+func TestCopyFileSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "target"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "link")
+	if err := os.Symlink("target", src); err != nil {
+		t.Fatal(err)
+	}
+
+	asLink := filepath.Join(dir, "as-link")
+	if _, err := CopyFile(src, asLink, Preserve); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(asLink); err != nil || target != "target" {
+		t.Errorf("Readlink() = %q, %v; want the relative target kept", target, err)
+	}
+
+	followed := filepath.Join(dir, "followed")
+	if _, err := CopyFile(src, followed, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Lstat(followed)
+	if err != nil || !info.Mode().IsRegular() {
+		t.Errorf("without Symlinks the copy should be a regular file: %v, %v", info, err)
+	}
+}
+
+func TestCopyFileModes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	strategy, err := CopyFile(src, filepath.Join(dir, "linked"), Options{Mode: ModeHardlink, Times: true})
+	if err != nil || strategy != StrategyHardlink {
+		t.Fatalf("CopyFile(hardlink) = %q, %v", strategy, err)
+	}
+	srcInfo, _ := os.Stat(src)
+	linkInfo, _ := os.Stat(filepath.Join(dir, "linked"))
+	if !os.SameFile(srcInfo, linkInfo) {
+		t.Error("hardlink mode did not link to the source")
+	}
+	// Linking again is a no-op that leaves no tmp file behind.
+	if _, err := CopyFile(src, filepath.Join(dir, "linked"), Options{Mode: ModeHardlink}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "linked.wtclone")); !os.IsNotExist(err) {
+		t.Error("relinking left linked.wtclone behind")
+	}
+
+	strategy, err = CopyFile(src, filepath.Join(dir, "copied"), Options{Mode: ModeCopy})
+	if err != nil || strategy != StrategyByteCopy {
+		t.Fatalf("CopyFile(copy) = %q, %v", strategy, err)
+	}
+	copiedInfo, _ := os.Stat(filepath.Join(dir, "copied"))
+	if os.SameFile(srcInfo, copiedInfo) {
+		t.Error("copy mode linked to the source")
+	}
+
+	// Whether the temp dir can clone depends on the filesystem; either way
+	// reflink mode must not fall back quietly.
+	strategy, err = CopyFile(src, filepath.Join(dir, "cloned"), Options{Mode: ModeReflink})
+	switch {
+	case err == nil && strategy != StrategyReflink:
+		t.Errorf("CopyFile(reflink) = %q, want %q", strategy, StrategyReflink)
+	case err != nil && !IsReflinkUnsupported(err):
+		t.Errorf("CopyFile(reflink) error = %v, want one IsReflinkUnsupported recognizes", err)
+	case err != nil:
+		if _, statErr := os.Lstat(filepath.Join(dir, "cloned")); !os.IsNotExist(statErr) {
+			t.Error("a failed reflink left a file behind")
+		}
+	}
+}
+
+func TestCopyTreeHardlink(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "toolchain")
+	dst := filepath.Join(t.TempDir(), "toolchain")
+	if err := os.MkdirAll(filepath.Join(src, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "bin", "tool"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := CopyTree(src, dst, Options{Mode: ModeHardlink, Symlinks: true})
+	if err != nil {
+		t.Fatalf("CopyTree: %v", err)
+	}
+	if stats.Files != 1 || stats.Strategies[StrategyHardlink] != 1 {
+		t.Errorf("stats = %+v, want one hard link", stats)
+	}
+	a, _ := os.Stat(filepath.Join(src, "bin", "tool"))
+	b, _ := os.Stat(filepath.Join(dst, "bin", "tool"))
+	if !os.SameFile(a, b) {
+		t.Error("bin/tool was not hard-linked")
+	}
+}
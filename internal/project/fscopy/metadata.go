@@ -0,0 +1,46 @@
+package fscopy
+
+import "os"
+
+// Options chooses how CopyFile copies content and what it carries over
+// from the source besides content and mode bits. The zero value copies
+// content and mode only, as ModeAuto, following symlinks.
+type Options struct {
+	Times    bool // modification and access times
+	Xattrs   bool // extended attributes the process may set
+	Owner    bool // user and group, when permitted (usually only as root)
+	Symlinks bool // recreate symlinks instead of copying what they point to
+	Mode     Mode // how content is copied
+}
+
+// Preserve carries over everything Options can. Build tools such as make,
+// Gradle, and Bazel compare timestamps, so copied caches keep working only
+// when mtimes survive the copy.
+var Preserve = Options{Times: true, Xattrs: true, Owner: true, Symlinks: true}
+
+// preserveMetadata applies the metadata opts selects from src to dst. info
+// describes src without following a symlink. Extended attributes and
+// ownership the process is not allowed to set are skipped.
+func preserveMetadata(src, dst string, info os.FileInfo, opts Options) error {
+	if opts.Xattrs {
+		if err := copyXattrs(src, dst); err != nil {
+			return err
+		}
+	}
+	isLink := info.Mode()&os.ModeSymlink != 0
+	if opts.Owner {
+		if err := copyOwner(src, dst, info); err != nil {
+			return err
+		}
+		// chown clears setuid and setgid bits; put them back.
+		if !isLink {
+			if err := os.Chmod(dst, info.Mode()); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.Times {
+		return copyTimes(src, dst, info)
+	}
+	return nil
+}
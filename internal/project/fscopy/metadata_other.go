@@ -0,0 +1,24 @@
+//go:build !linux && !darwin
+
+package fscopy
+
+import "os"
+
+// copyXattrs is a no-op where extended attributes aren't supported.
+func copyXattrs(_, _ string) error {
+	return nil
+}
+
+// copyOwner is a no-op where ownership isn't a uid/gid pair.
+func copyOwner(_, _ string, _ os.FileInfo) error {
+	return nil
+}
+
+// copyTimes sets the modification time only; os.Chtimes follows symlinks,
+// so a symlink keeps the time it was created.
+func copyTimes(_, dst string, info os.FileInfo) error {
+	if info.Mode()&os.ModeSymlink != 0 {
+		return nil
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
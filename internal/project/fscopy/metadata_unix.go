@@ -0,0 +1,93 @@
+//go:build linux || darwin
+
+package fscopy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// copyXattrs copies the extended attributes of src to dst, neither
+// followed if a symlink.
+func copyXattrs(src, dst string) error {
+	size, err := unix.Llistxattr(src, nil)
+	if err != nil || size == 0 {
+		if xattrSkippable(err) {
+			return nil
+		}
+		return err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(src, buf)
+	if err != nil {
+		return err
+	}
+
+	for name := range bytes.SplitSeq(buf[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+		// An attribute that vanished or can't be read is left behind.
+		value, err := getXattr(src, string(name))
+		if err != nil {
+			continue
+		}
+		if err := unix.Lsetxattr(dst, string(name), value, 0); err != nil && !xattrSkippable(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil || size == 0 {
+		return nil, err
+	}
+	value := make([]byte, size)
+	size, err = unix.Lgetxattr(path, name, value)
+	if err != nil {
+		return nil, err
+	}
+	return value[:size], nil
+}
+
+// xattrSkippable reports whether err means the filesystem or the process's
+// privileges rule the attribute out, rather than a real failure.
+func xattrSkippable(err error) bool {
+	return err == nil ||
+		errors.Is(err, unix.ENOTSUP) ||
+		errors.Is(err, unix.EOPNOTSUPP) ||
+		errors.Is(err, unix.EPERM) ||
+		errors.Is(err, unix.EACCES)
+}
+
+// copyOwner gives dst the owner and group of src, skipping what an
+// unprivileged process may not set.
+func copyOwner(src, dst string, _ os.FileInfo) error {
+	var st unix.Stat_t
+	if err := unix.Lstat(src, &st); err != nil {
+		return err
+	}
+	err := unix.Lchown(dst, int(st.Uid), int(st.Gid))
+	if errors.Is(err, unix.EPERM) {
+		// Keep the group if it alone is allowed, e.g. one the user is in.
+		_ = unix.Lchown(dst, -1, int(st.Gid))
+		return nil
+	}
+	return err
+}
+
+// copyTimes gives dst the access and modification times of src, without
+// following a symlink.
+func copyTimes(src, dst string, _ os.FileInfo) error {
+	var st unix.Stat_t
+	if err := unix.Lstat(src, &st); err != nil {
+		return err
+	}
+	ts := []unix.Timespec{st.Atim, st.Mtim}
+	return unix.UtimesNanoAt(unix.AT_FDCWD, dst, ts, unix.AT_SYMLINK_NOFOLLOW)
+}
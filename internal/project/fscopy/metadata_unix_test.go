@@ -0,0 +1,32 @@
+//go:build linux || darwin
+
+package fscopy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestCopyFilePreservesXattrs(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.WriteFile(src, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := unix.Setxattr(src, "user.wt.test", []byte("kept"), 0); err != nil {
+		t.Skipf("filesystem does not support user xattrs: %v", err)
+	}
+
+	dst := filepath.Join(dir, "dst")
+	if _, err := CopyFile(src, dst, Preserve); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 16)
+	n, err := unix.Getxattr(dst, "user.wt.test", buf)
+	if err != nil || string(buf[:n]) != "kept" {
+		t.Errorf("xattr = %q, %v; want kept", buf[:n], err)
+	}
+}
@@ -4,6 +4,9 @@ package fscopy
 
 import (
 	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 
 	"golang.org/x/sys/unix"
 )
@@ -23,10 +26,35 @@ func tryReflink(src, dst string) error {
 	return err
 }
 
+// tryCopyRange has no Darwin equivalent; clonefile is the fast path there.
+func tryCopyRange(_, _ string, _ os.FileMode) error {
+	return errReflinkUnsupported
+}
+
 // tryCloneTree clones a directory via clonefile(2), which walks the
-// whole hierarchy in one syscall on APFS.
-func tryCloneTree(src, dst string) error {
-	return tryReflink(src, dst)
+// whole hierarchy in one syscall on APFS. The clone keeps symlinks and
+// metadata, so it is only used when opts asks for clones that keep
+// symlinks. The stats come from a walk of the clone.
+func tryCloneTree(src, dst string, opts Options) (Stats, error) {
+	if !opts.Symlinks || (opts.Mode != "" && opts.Mode != ModeAuto && opts.Mode != ModeReflink) {
+		return Stats{}, errReflinkUnsupported
+	}
+	if err := tryReflink(src, dst); err != nil {
+		return Stats{}, err
+	}
+	var stats Stats
+	err := filepath.WalkDir(dst, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.add(StrategyReflink, info.Size())
+		return nil
+	})
+	return stats, err
 }
 
 // isUnsupported reports whether err from Clonefile indicates that the
@@ -45,11 +45,64 @@ func tryReflink(src, dst string) error {
 	return nil
 }
 
+// tryCopyRange copies src to dst with copy_file_range(2), which stays in
+// the kernel and lets filesystems such as NFS and btrfs share or offload
+// the blocks. Kernels before 5.19 refuse to copy between filesystems, and
+// some filesystems don't implement it; both report unsupported so the
+// caller falls back to a byte copy.
+func tryCopyRange(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	copyErr := copyRange(in, out, info.Size())
+	if closeErr := out.Close(); closeErr != nil && copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		_ = os.Remove(dst)
+		if isUnsupported(copyErr) {
+			return errReflinkUnsupported
+		}
+		return copyErr
+	}
+	return nil
+}
+
+// copyRange copies size bytes from in to out with copy_file_range(2).
+func copyRange(in, out *os.File, size int64) error {
+	for remaining := size; remaining > 0; {
+		n, err := unix.CopyFileRange(int(in.Fd()), nil, int(out.Fd()), nil, int(min(remaining, 1<<30)), 0)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return err
+		}
+		if n == 0 {
+			// The source shrank while being copied.
+			break
+		}
+		remaining -= int64(n)
+	}
+	return nil
+}
+
 // tryCloneTree is not supported on Linux: FICLONE is file-only and there
-// is no tree-level reflink syscall on btrfs/XFS. Callers fall back to a
-// per-file walk.
-func tryCloneTree(_, _ string) error {
-	return errReflinkUnsupported
+// is no tree-level reflink syscall on btrfs/XFS. CopyTree walks the tree
+// instead, cloning file by file.
+func tryCloneTree(_, _ string, _ Options) (Stats, error) {
+	return Stats{}, errReflinkUnsupported
 }
 
 func isUnsupported(err error) bool {
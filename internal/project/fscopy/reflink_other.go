@@ -2,15 +2,22 @@
 
 package fscopy
 
+import "os"
+
 // tryReflink on platforms without a supported COW clone syscall (the BSDs,
 // etc.) always reports unsupported so CopyFile falls back to a byte copy.
 func tryReflink(src, dst string) error {
 	return errReflinkUnsupported
 }
 
-// tryCloneTree on platforms without a supported tree-clone syscall always
-// reports unsupported so CopyTree's caller falls back to a per-file walk via
-// IsReflinkUnsupported.
-func tryCloneTree(src, dst string) error {
+// tryCopyRange has no in-kernel copy to use here, so CopyFile goes straight
+// to a byte copy.
+func tryCopyRange(src, dst string, mode os.FileMode) error {
 	return errReflinkUnsupported
 }
+
+// tryCloneTree on platforms without a supported tree-clone syscall always
+// reports unsupported so CopyTree walks the tree instead.
+func tryCloneTree(src, dst string, opts Options) (Stats, error) {
+	return Stats{}, errReflinkUnsupported
+}
@@ -0,0 +1,135 @@
+package project
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+// HookTarget identifies the project and worktree a hook runs for. Its
+// fields are exported to hooks as WT_PROJECT_ROOT, WT_WORKTREE_PATH, and
+// WT_BRANCH.
+type HookTarget struct {
+	ProjectRoot  string
+	WorktreePath string
+	Branch       string
+}
+
+// env returns the process environment for a hook at point.
+func (t HookTarget) env(point HookPoint) []string {
+	return append(os.Environ(),
+		"WT_HOOK="+string(point),
+		"WT_PROJECT_ROOT="+t.ProjectRoot,
+		"WT_WORKTREE_PATH="+t.WorktreePath,
+		"WT_BRANCH="+t.Branch,
+	)
+}
+
+// HooksPath returns the directory holding script hooks.
+func HooksPath(projectRoot string, cfg *config.Config) string {
+	return filepath.Join(SharedPath(projectRoot, cfg), HooksDir)
+}
+
+// hookCommand builds the process for h, running in the target worktree (or
+// the project root when point has no worktree). Script hooks are executed
+// directly so the kernel honors their shebang; command hooks are passed as
+// the last argument to the configured shell.
+func hookCommand(ctx context.Context, cfg *config.Config, point HookPoint, target HookTarget, h config.Hook) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	if h.Script != "" {
+		path, err := scriptPath(target.ProjectRoot, cfg, h.Script)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, path)
+	} else {
+		argv := append(cfg.ShellFor(h), h.Run)
+		cmd = exec.CommandContext(ctx, argv[0], argv[1:]...)
+	}
+	cmd.Dir = target.WorktreePath
+	if point.runsInProjectRoot() {
+		cmd.Dir = target.ProjectRoot
+	}
+	cmd.Env = hookEnv(cfg, point, target)
+	return cmd, nil
+}
+
+// hookEnv returns the environment of a command wt runs for target at point:
+// the WT_* variables, plus COMPOSE_PROJECT_NAME when compose is configured.
+func hookEnv(cfg *config.Config, point HookPoint, target HookTarget) []string {
+	env := target.env(point)
+	if cfg.Compose != nil {
+		env = append(env, "COMPOSE_PROJECT_NAME="+ComposeProjectName(cfg, target))
+	}
+	return env
+}
+
+// runHook runs h in the target worktree with its output sent to out.
+// Interactive hooks also get the user's stdin; the rest read EOF.
+func runHook(ctx context.Context, cfg *config.Config, point HookPoint, target HookTarget, h config.Hook, out io.Writer, interactive bool) error {
+	cmd, err := hookCommand(ctx, cfg, point, target, h)
+	if err != nil {
+		return err
+	}
+	var stdin *os.File
+	if interactive {
+		stdin = os.Stdin
+	}
+	return runHookCmd(cmd, out, cfg.UsePTY(h), stdin)
+}
+
+// scriptPath resolves a script hook and checks that it can be executed.
+func scriptPath(projectRoot string, cfg *config.Config, name string) (string, error) {
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("script %s must be a relative path inside %s", name, HooksPath(projectRoot, cfg))
+	}
+	path := filepath.Join(HooksPath(projectRoot, cfg), name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("script %s: %w", name, err)
+	}
+	if info.IsDir() || info.Mode()&0o111 == 0 {
+		return "", fmt.Errorf("script %s is not executable (chmod +x %s)", name, path)
+	}
+	return path, nil
+}
+
+// describeHook renders the command line h resolves to, for dry-run output.
+func describeHook(cfg *config.Config, projectRoot string, h config.Hook) string {
+	if h.Script != "" {
+		path := filepath.Join(HooksPath(projectRoot, cfg), h.Script)
+		if interp := readShebang(path); interp != "" {
+			return path + " (" + interp + ")"
+		}
+		return path
+	}
+	return strings.Join(cfg.ShellFor(h), " ") + " " + shellQuote(h.Run)
+}
+
+// readShebang returns the interpreter line of a script, or "" if it has none
+// or cannot be read.
+func readShebang(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	return strings.TrimSpace(line[2:])
+}
+
+// shellQuote wraps s in single quotes for display as a shell argument.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
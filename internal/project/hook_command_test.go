@@ -0,0 +1,117 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func writeHookScript(t *testing.T, root, name, content string, mode os.FileMode) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	dir := HooksPath(root, &cfg)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScriptHookRunsWithShebang(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	writeHookScript(t, root, "mark.sh", "#!/bin/sh\ntouch \"$(basename \"$0\").done\"\n", 0o755)
+
+	cfg := config.DefaultConfig()
+	cfg.Setup = []config.Hook{{Script: "mark.sh"}}
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt}
+	if err := RunSetupHooks(context.Background(), &cfg, target, false, nil); err != nil {
+		t.Fatalf("RunSetupHooks error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "mark.sh.done")); err != nil {
+		t.Errorf("script did not run in the worktree: %v", err)
+	}
+}
+
+func TestScriptPathErrors(t *testing.T) {
+	root := t.TempDir()
+	writeHookScript(t, root, "plain.sh", "#!/bin/sh\n", 0o644)
+	cfg := config.DefaultConfig()
+
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"not executable", "plain.sh", "chmod +x"},
+		{"missing", "missing.sh", "no such file"},
+		{"escapes hooks dir", "../copy/x.sh", "relative path inside"},
+		{"absolute", "/bin/true", "relative path inside"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := scriptPath(root, &cfg, tt.script)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHookCommandUsesShell(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Shell = "bash -euo pipefail -c"
+	target := HookTarget{WorktreePath: t.TempDir()}
+
+	cmd, err := hookCommand(context.Background(), &cfg, HookSetup, target, config.Hook{Run: "make"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cmd.Args, "|"); got != "bash|-euo|pipefail|-c|make" {
+		t.Errorf("args = %q, want the project shell", got)
+	}
+
+	cmd, err = hookCommand(context.Background(), &cfg, HookSetup, target, config.Hook{Run: "make", Shell: "zsh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cmd.Args, "|"); got != "zsh|-c|make" {
+		t.Errorf("args = %q, want the hook's shell", got)
+	}
+	if cmd.Dir != target.WorktreePath {
+		t.Errorf("dir = %q, want %q", cmd.Dir, target.WorktreePath)
+	}
+}
+
+func TestDescribeHook(t *testing.T) {
+	root := t.TempDir()
+	writeHookScript(t, root, "seed.sh", "#!/usr/bin/env bash\n", 0o755)
+	writeHookScript(t, root, "bare", "echo hi\n", 0o755)
+	cfg := config.DefaultConfig()
+	hooksDir := HooksPath(root, &cfg)
+
+	tests := []struct {
+		name string
+		hook config.Hook
+		want string
+	}{
+		{"default shell", config.Hook{Run: "npm install"}, "sh -c 'npm install'"},
+		{"quotes", config.Hook{Run: "echo 'hi'"}, `sh -c 'echo '\''hi'\'''`},
+		{"hook shell", config.Hook{Run: "make", Shell: "bash -e -c"}, "bash -e -c 'make'"},
+		{"script with shebang", config.Hook{Script: "seed.sh"}, filepath.Join(hooksDir, "seed.sh") + " (/usr/bin/env bash)"},
+		{"script without shebang", config.Hook{Script: "bare"}, filepath.Join(hooksDir, "bare")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeHook(&cfg, root, tt.hook); got != tt.want {
+				t.Errorf("describeHook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
-	"os/exec"
+	"io"
+	"strings"
 	"sync"
 
 	"github.com/bkildow/wt-cli/internal/config"
@@ -13,7 +13,9 @@ import (
 )
 
 // prefixWriter wraps an io.Writer and prepends a prefix to each line.
+// Writers sharing mu never interleave within a line.
 type prefixWriter struct {
+	out    io.Writer
 	prefix string
 	mu     *sync.Mutex
 	buf    bytes.Buffer
@@ -29,7 +31,7 @@ func (pw *prefixWriter) Write(p []byte) (int, error) {
 			break
 		}
 		pw.mu.Lock()
-		fmt.Fprintf(ui.Output, "[%s] %s", pw.prefix, line)
+		fmt.Fprintf(pw.out, "[%s] %s", pw.prefix, line)
 		pw.mu.Unlock()
 	}
 	return len(p), nil
@@ -39,44 +41,124 @@ func (pw *prefixWriter) Write(p []byte) (int, error) {
 func (pw *prefixWriter) flush() {
 	if pw.buf.Len() > 0 {
 		pw.mu.Lock()
-		fmt.Fprintf(ui.Output, "[%s] %s\n", pw.prefix, pw.buf.String())
+		fmt.Fprintf(pw.out, "[%s] %s\n", pw.prefix, pw.buf.String())
 		pw.mu.Unlock()
 		pw.buf.Reset()
 	}
 }
 
+// HookPoint names the lifecycle event a list of hooks runs at. It matches
+// the config key and is exported to hooks as WT_HOOK.
+type HookPoint string
+
+const (
+	HookPreAdd           HookPoint = "pre_add"
+	HookSetup            HookPoint = "setup"
+	HookParallelSetup    HookPoint = "parallel_setup"
+	HookPostApply        HookPoint = "post_apply"
+	HookPostSync         HookPoint = "post_sync"
+	HookPreRemove        HookPoint = "pre_remove"
+	HookTeardown         HookPoint = "teardown"
+	HookParallelTeardown HookPoint = "parallel_teardown"
+	HookPostRemove       HookPoint = "post_remove"
+	HookOnEnter          HookPoint = "on_enter"
+)
+
+// hooks returns the configured hooks for p.
+func (p HookPoint) hooks(cfg *config.Config) []config.Hook {
+	switch p {
+	case HookPreAdd:
+		return cfg.PreAdd
+	case HookSetup:
+		return cfg.Setup
+	case HookParallelSetup:
+		return cfg.ParallelSetup
+	case HookPostApply:
+		return cfg.PostApply
+	case HookPostSync:
+		return cfg.PostSync
+	case HookPreRemove:
+		return cfg.PreRemove
+	case HookTeardown:
+		return cfg.Teardown
+	case HookParallelTeardown:
+		return cfg.ParallelTeardown
+	case HookPostRemove:
+		return cfg.PostRemove
+	case HookOnEnter:
+		return cfg.OnEnter
+	}
+	return nil
+}
+
+// canVeto reports whether a failing hook at p stops the operation. These
+// hooks stop at the first failure instead of running the rest.
+func (p HookPoint) canVeto() bool {
+	return p == HookPreAdd || p == HookPreRemove
+}
+
+// runsInProjectRoot reports whether hooks at p run in the project root
+// because the worktree directory does not exist (yet, or any more).
+func (p HookPoint) runsInProjectRoot() bool {
+	return p == HookPreAdd || p == HookPostRemove || p == HookResourceCleanup
+}
+
+// label is the human-readable name used in messages, e.g. "post sync".
+func (p HookPoint) label() string {
+	return strings.ReplaceAll(string(p), "_", " ")
+}
+
+// HasHooks reports whether any hooks are configured for p.
+func HasHooks(cfg *config.Config, p HookPoint) bool {
+	return len(p.hooks(cfg)) > 0
+}
+
 // HookProgressFunc is called after each serial hook completes.
 // index is the 0-based position, cmdStr is the command, err is nil on success.
 type HookProgressFunc func(index int, cmdStr string, err error)
 
+// RunHooks executes the serial hooks configured for point. Failures are
+// logged and later hooks still run, except at veto points (pre_add,
+// pre_remove) where the first failure stops the run and is returned so the
+// caller can abort.
+func RunHooks(ctx context.Context, cfg *config.Config, point HookPoint, target HookTarget, dryRun bool) error {
+	return runSerialHooks(ctx, cfg, point, target, dryRun, nil)
+}
+
 // RunSetupHooks executes each command in cfg.Setup inside the
 // worktree directory. Failures are logged but do not stop subsequent hooks.
 // An optional onProgress callback is called after each hook completes.
-func RunSetupHooks(ctx context.Context, cfg *config.Config, worktreePath string, dryRun bool, onProgress HookProgressFunc) error {
-	if len(cfg.Setup) == 0 {
+func RunSetupHooks(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool, onProgress HookProgressFunc) error {
+	return runSerialHooks(ctx, cfg, HookSetup, target, dryRun, onProgress)
+}
+
+// RunTeardownHooks executes each command in cfg.Teardown inside the
+// worktree directory. Failures are logged but do not stop subsequent hooks.
+func RunTeardownHooks(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool) error {
+	return runSerialHooks(ctx, cfg, HookTeardown, target, dryRun, nil)
+}
+
+func runSerialHooks(ctx context.Context, cfg *config.Config, point HookPoint, target HookTarget, dryRun bool, onProgress HookProgressFunc) error {
+	hooks := point.hooks(cfg)
+	if len(hooks) == 0 {
 		return nil
 	}
 
 	failCount := 0
-	for i, cmdStr := range cfg.Setup {
+	for i, h := range hooks {
+		cmdStr := h.Name()
 		ui.Step("Running: " + cmdStr)
 
 		if dryRun {
-			ui.DryRunNotice("exec: " + cmdStr)
+			ui.DryRunNotice("exec: " + describeHook(cfg, target.ProjectRoot, h))
 			if onProgress != nil {
 				onProgress(i, cmdStr, nil)
 			}
 			continue
 		}
 
-		cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
-		cmd.Dir = worktreePath
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = ui.Output
-		cmd.Stderr = ui.Output
-
 		var hookErr error
-		if err := cmd.Run(); err != nil {
+		if err := runHook(ctx, cfg, point, target, h, ui.Output, true); err != nil {
 			ui.Error("Failed: " + cmdStr + ": " + err.Error())
 			hookErr = err
 			failCount++
@@ -87,76 +169,78 @@ func RunSetupHooks(ctx context.Context, cfg *config.Config, worktreePath string,
 		if onProgress != nil {
 			onProgress(i, cmdStr, hookErr)
 		}
-	}
-
-	if failCount > 0 {
-		return fmt.Errorf("%d setup hook(s) failed", failCount)
-	}
-	return nil
-}
-
-// RunTeardownHooks executes each command in cfg.Teardown inside the
-// worktree directory. Failures are logged but do not stop subsequent hooks.
-func RunTeardownHooks(ctx context.Context, cfg *config.Config, worktreePath string, dryRun bool) error {
-	if len(cfg.Teardown) == 0 {
-		return nil
-	}
-
-	failCount := 0
-	for _, cmdStr := range cfg.Teardown {
-		ui.Step("Running: " + cmdStr)
-
-		if dryRun {
-			ui.DryRunNotice("exec: " + cmdStr)
-			continue
-		}
-
-		cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
-		cmd.Dir = worktreePath
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = ui.Output
-		cmd.Stderr = ui.Output
-
-		if err := cmd.Run(); err != nil {
-			ui.Error("Failed: " + cmdStr + ": " + err.Error())
-			failCount++
-			continue
+		if hookErr != nil && point.canVeto() {
+			return fmt.Errorf("%s hook failed: %s: %w", point, cmdStr, hookErr)
 		}
-		ui.Success("Completed: " + cmdStr)
 	}
 
 	if failCount > 0 {
-		return fmt.Errorf("%d teardown hook(s) failed", failCount)
+		return fmt.Errorf("%d %s hook(s) failed", failCount, point.label())
 	}
 	return nil
 }
 
 // RunParallelSetupHooks executes all commands in cfg.ParallelSetup concurrently
 // inside the worktree directory. All commands run to completion even if some fail.
-func RunParallelSetupHooks(ctx context.Context, cfg *config.Config, worktreePath string, dryRun bool) error {
-	return runParallelHooks(ctx, cfg.ParallelSetup, worktreePath, dryRun, "parallel setup")
+func RunParallelSetupHooks(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool) error {
+	return runParallelHooks(ctx, cfg, HookParallelSetup, target, dryRun)
 }
 
 // RunParallelTeardownHooks executes all commands in cfg.ParallelTeardown concurrently
 // inside the worktree directory. All commands run to completion even if some fail.
-func RunParallelTeardownHooks(ctx context.Context, cfg *config.Config, worktreePath string, dryRun bool) error {
-	return runParallelHooks(ctx, cfg.ParallelTeardown, worktreePath, dryRun, "parallel teardown")
+func RunParallelTeardownHooks(ctx context.Context, cfg *config.Config, target HookTarget, dryRun bool) error {
+	return runParallelHooks(ctx, cfg, HookParallelTeardown, target, dryRun)
 }
 
-func runParallelHooks(ctx context.Context, hooks []string, worktreePath string, dryRun bool, label string) error {
+// runParallelHooks runs hooks concurrently. They never read from stdin:
+// several processes sharing the terminal would race for the user's input.
+func runParallelHooks(ctx context.Context, cfg *config.Config, point HookPoint, target HookTarget, dryRun bool) error {
+	hooks := point.hooks(cfg)
+	label := point.label()
 	if len(hooks) == 0 {
 		return nil
 	}
 
 	if dryRun {
-		for _, cmdStr := range hooks {
-			ui.DryRunNotice("exec (" + label + "): " + cmdStr)
+		for _, h := range hooks {
+			ui.DryRunNotice("exec (" + label + "): " + describeHook(cfg, target.ProjectRoot, h))
 		}
 		return nil
 	}
 
 	ui.Step(fmt.Sprintf("Running %d %s hook(s) in parallel", len(hooks), label))
 
+	jobs := make([]hookJob, len(hooks))
+	for i, h := range hooks {
+		jobs[i] = hookJob{name: h.Name(), hook: h, target: target}
+	}
+	if failCount := runHookJobs(ctx, cfg, point, jobs); failCount > 0 {
+		return fmt.Errorf("%d %s hook(s) failed", failCount, label)
+	}
+	return nil
+}
+
+// hookJob is one process in a parallel batch: a hook and the worktree it
+// runs in. name labels its output lines and status row.
+type hookJob struct {
+	name   string
+	hook   config.Hook
+	target HookTarget
+}
+
+// runHookJobs runs jobs concurrently, with a live status region on a
+// terminal and prefixed output otherwise. Returns the number that failed.
+func runHookJobs(ctx context.Context, cfg *config.Config, point HookPoint, jobs []hookJob) int {
+	if ui.IsTerminal(ui.Output) {
+		return runParallelHooksLive(ctx, cfg, point, jobs)
+	}
+	return runParallelHooksPrefixed(ctx, cfg, point, jobs)
+}
+
+// runParallelHooksPrefixed runs jobs concurrently, streaming each line of
+// output prefixed with the job name. Used when output is not a terminal,
+// such as the background setup log. Returns the number of failed jobs.
+func runParallelHooksPrefixed(ctx context.Context, cfg *config.Config, point HookPoint, jobs []hookJob) int {
 	var (
 		wg        sync.WaitGroup
 		mu        sync.Mutex
@@ -164,39 +248,29 @@ func runParallelHooks(ctx context.Context, hooks []string, worktreePath string,
 		outputMu  sync.Mutex
 	)
 
-	for _, cmdStr := range hooks {
+	for _, job := range jobs {
 		wg.Add(1)
-		go func(cmdStr string) {
+		go func(job hookJob) {
 			defer wg.Done()
 
-			pw := &prefixWriter{prefix: cmdStr, mu: &outputMu}
-			cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
-			cmd.Dir = worktreePath
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = pw
-			cmd.Stderr = pw
-
-			if err := cmd.Run(); err != nil {
+			pw := &prefixWriter{out: ui.Output, prefix: job.name, mu: &outputMu}
+			if err := runHook(ctx, cfg, point, job.target, job.hook, pw, false); err != nil {
 				pw.flush()
 				mu.Lock()
 				failCount++
 				mu.Unlock()
 				outputMu.Lock()
-				fmt.Fprintf(ui.Output, "[%s] Failed: %s\n", cmdStr, err.Error())
+				fmt.Fprintf(ui.Output, "[%s] Failed: %s\n", job.name, err.Error())
 				outputMu.Unlock()
 				return
 			}
 			pw.flush()
 			outputMu.Lock()
-			fmt.Fprintf(ui.Output, "[%s] Completed\n", cmdStr)
+			fmt.Fprintf(ui.Output, "[%s] Completed\n", job.name)
 			outputMu.Unlock()
-		}(cmdStr)
+		}(job)
 	}
 
 	wg.Wait()
-
-	if failCount > 0 {
-		return fmt.Errorf("%d %s hook(s) failed", failCount, label)
-	}
-	return nil
+	return failCount
 }
@@ -0,0 +1,190 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// liveRefreshInterval is how often the parallel hook status region redraws.
+const liveRefreshInterval = 100 * time.Millisecond
+
+// hookCapture buffers a hook's full output and tracks its most recent line
+// for the live status region. Both \n and \r end a line so progress bars
+// that redraw with carriage returns show their latest state.
+type hookCapture struct {
+	mu   sync.Mutex
+	full bytes.Buffer
+	last string
+	cur  []byte
+}
+
+func (c *hookCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.full.Write(p)
+	for _, b := range p {
+		if b != '\n' && b != '\r' {
+			c.cur = append(c.cur, b)
+			continue
+		}
+		if line := cleanLine(c.cur); line != "" {
+			c.last = line
+		}
+		c.cur = c.cur[:0]
+	}
+	return len(p), nil
+}
+
+// lastLine returns the most recent non-empty line, preferring a partial
+// line still being written (e.g. a prompt or an in-place progress bar).
+func (c *hookCapture) lastLine() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if line := cleanLine(c.cur); line != "" {
+		return line
+	}
+	return c.last
+}
+
+// output returns everything the hook has written.
+func (c *hookCapture) output() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return bytes.Clone(c.full.Bytes())
+}
+
+func cleanLine(b []byte) string {
+	return strings.TrimSpace(ansi.Strip(string(b)))
+}
+
+// liveHook tracks one parallel hook for the live status region.
+type liveHook struct {
+	job    hookJob
+	cmdStr string
+	start  time.Time
+	out    hookCapture
+
+	mu      sync.Mutex
+	done    bool
+	elapsed time.Duration
+	err     error
+}
+
+func (h *liveHook) finish(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.done = true
+	h.elapsed = time.Since(h.start)
+	h.err = err
+}
+
+func (h *liveHook) snapshot() (done bool, elapsed time.Duration, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.done {
+		return false, time.Since(h.start), nil
+	}
+	return true, h.elapsed, h.err
+}
+
+// statusLine renders the hook's row in the live region.
+func (h *liveHook) statusLine(frame int) string {
+	done, elapsed, err := h.snapshot()
+	timing := ui.StyleMuted.Render(formatElapsedShort(elapsed))
+
+	switch {
+	case done && err != nil:
+		return ui.StyleError.Render("✗ "+h.cmdStr) + "  " + timing
+	case done:
+		return ui.StyleSuccess.Render("✓ "+h.cmdStr) + "  " + timing
+	}
+
+	spinner := ui.SpinnerFrames[frame%len(ui.SpinnerFrames)]
+	line := ui.StyleInfo.Render(spinner+" "+h.cmdStr) + "  " + timing
+	if last := h.out.lastLine(); last != "" {
+		line += "  " + ui.StyleMuted.Render(last)
+	}
+	return line
+}
+
+// runParallelHooksLive runs jobs concurrently while redrawing one status
+// line per job on the terminal. Once all jobs finish the region collapses
+// into a one-line summary per job, and the full output is dumped only for
+// jobs that failed. Returns the number of failed jobs.
+func runParallelHooksLive(ctx context.Context, cfg *config.Config, point HookPoint, jobs []hookJob) int {
+	live := make([]*liveHook, len(jobs))
+	region := ui.NewLiveRegion(ui.Output, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		h := &liveHook{job: job, cmdStr: job.name, start: time.Now()}
+		live[i] = h
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.finish(runHook(ctx, cfg, point, h.job.target, h.job.hook, &h.out, false))
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(liveRefreshInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		for i, h := range live {
+			region.Set(i, h.statusLine(frame))
+		}
+		region.Render()
+
+		select {
+		case <-done:
+			region.Stop()
+			return summarizeLiveHooks(live)
+		case <-ticker.C:
+		}
+	}
+}
+
+// summarizeLiveHooks prints the final state of each hook and the captured
+// output of failed ones, returning the failure count.
+func summarizeLiveHooks(live []*liveHook) int {
+	failCount := 0
+	var outputMu sync.Mutex
+	for _, h := range live {
+		_, elapsed, err := h.snapshot()
+		if err == nil {
+			ui.Success(fmt.Sprintf("Completed: %s (%s)", h.cmdStr, ui.FormatDuration(elapsed)))
+			continue
+		}
+
+		failCount++
+		ui.Error(fmt.Sprintf("Failed: %s (%s): %s", h.cmdStr, ui.FormatDuration(elapsed), err))
+		pw := &prefixWriter{out: ui.Output, prefix: h.cmdStr, mu: &outputMu}
+		_, _ = pw.Write(h.out.output())
+		pw.flush()
+	}
+	return failCount
+}
+
+// formatElapsedShort formats a running timer compactly, e.g. "7s" or "2m05s".
+func formatElapsedShort(d time.Duration) string {
+	s := int(d.Seconds())
+	if s < 60 {
+		return fmt.Sprintf("%ds", s)
+	}
+	return fmt.Sprintf("%dm%02ds", s/60, s%60)
+}
@@ -0,0 +1,89 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+func TestHookCaptureLastLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"complete line", []string{"installing\n"}, "installing"},
+		{"skips blank lines", []string{"done\n", "\n  \n"}, "done"},
+		{"partial line wins", []string{"done\n", "progress 50%"}, "progress 50%"},
+		{"carriage return redraw", []string{"10%\r20%\r30%\r"}, "30%"},
+		{"split across writes", []string{"hel", "lo\n"}, "hello"},
+		{"strips ANSI", []string{"\x1b[32mok\x1b[0m\n"}, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c hookCapture
+			for _, w := range tt.writes {
+				_, _ = c.Write([]byte(w))
+			}
+			if got := c.lastLine(); got != tt.want {
+				t.Errorf("lastLine() = %q, want %q", got, tt.want)
+			}
+			if got := string(c.output()); got != strings.Join(tt.writes, "") {
+				t.Errorf("output() = %q, want all writes", got)
+			}
+		})
+	}
+}
+
+func TestFormatElapsedShort(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{7 * time.Second, "7s"},
+		{125 * time.Second, "2m05s"},
+	}
+	for _, tt := range tests {
+		if got := formatElapsedShort(tt.d); got != tt.want {
+			t.Errorf("formatElapsedShort(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRunParallelHooksLive(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	target := HookTarget{WorktreePath: t.TempDir()}
+	var jobs []hookJob
+	for _, h := range []config.Hook{{Run: "echo quiet-ok"}, {Run: "echo loud-failure; exit 2"}} {
+		jobs = append(jobs, hookJob{name: h.Name(), hook: h, target: target})
+	}
+	if got := runParallelHooksLive(context.Background(), &config.Config{}, HookParallelSetup, jobs); got != 1 {
+		t.Fatalf("failCount = %d, want 1", got)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "Completed: echo quiet-ok") {
+		t.Errorf("missing success summary in %q", out)
+	}
+	if !strings.Contains(out, "Failed: echo loud-failure; exit 2") {
+		t.Errorf("missing failure summary in %q", out)
+	}
+	if !strings.Contains(out, "[echo loud-failure; exit 2] loud-failure") {
+		t.Errorf("failed hook output not dumped in %q", out)
+	}
+	if strings.Contains(out, "[echo quiet-ok] quiet-ok") {
+		t.Errorf("successful hook output should not be dumped: %q", out)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bkildow/wt-cli/internal/config"
@@ -11,11 +12,11 @@ import (
 
 func TestRunSetupHooks(t *testing.T) {
 	cfg := &config.Config{
-		Setup: []string{"echo hello"},
+		Setup: []config.Hook{{Run: "echo hello"}},
 	}
 	wt := t.TempDir()
 
-	err := RunSetupHooks(context.Background(), cfg, wt, false, nil)
+	err := RunSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false, nil)
 	if err != nil {
 		t.Fatalf("RunSetupHooks error: %v", err)
 	}
@@ -23,11 +24,11 @@ func TestRunSetupHooks(t *testing.T) {
 
 func TestRunSetupHooksDryRun(t *testing.T) {
 	cfg := &config.Config{
-		Setup: []string{"echo hello"},
+		Setup: []config.Hook{{Run: "echo hello"}},
 	}
 	wt := t.TempDir()
 
-	err := RunSetupHooks(context.Background(), cfg, wt, true, nil)
+	err := RunSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, true, nil)
 	if err != nil {
 		t.Fatalf("RunSetupHooks dry-run error: %v", err)
 	}
@@ -35,11 +36,11 @@ func TestRunSetupHooksDryRun(t *testing.T) {
 
 func TestRunSetupHooksFailure(t *testing.T) {
 	cfg := &config.Config{
-		Setup: []string{"false"},
+		Setup: []config.Hook{{Run: "false"}},
 	}
 	wt := t.TempDir()
 
-	err := RunSetupHooks(context.Background(), cfg, wt, false, nil)
+	err := RunSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false, nil)
 	if err == nil {
 		t.Fatal("expected error from failing hook")
 	}
@@ -49,7 +50,7 @@ func TestRunSetupHooksEmpty(t *testing.T) {
 	cfg := &config.Config{}
 	wt := t.TempDir()
 
-	err := RunSetupHooks(context.Background(), cfg, wt, false, nil)
+	err := RunSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false, nil)
 	if err != nil {
 		t.Fatalf("RunSetupHooks with empty hooks error: %v", err)
 	}
@@ -57,11 +58,11 @@ func TestRunSetupHooksEmpty(t *testing.T) {
 
 func TestRunSetupHooksContinuesOnFailure(t *testing.T) {
 	cfg := &config.Config{
-		Setup: []string{"echo ok", "false", "echo still-runs"},
+		Setup: []config.Hook{{Run: "echo ok"}, {Run: "false"}, {Run: "echo still-runs"}},
 	}
 	wt := t.TempDir()
 
-	err := RunSetupHooks(context.Background(), cfg, wt, false, nil)
+	err := RunSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false, nil)
 	if err == nil {
 		t.Fatal("expected error from failing hook")
 	}
@@ -69,11 +70,11 @@ func TestRunSetupHooksContinuesOnFailure(t *testing.T) {
 
 func TestRunTeardownHooks(t *testing.T) {
 	cfg := &config.Config{
-		Teardown: []string{"echo cleanup"},
+		Teardown: []config.Hook{{Run: "echo cleanup"}},
 	}
 	wt := t.TempDir()
 
-	err := RunTeardownHooks(context.Background(), cfg, wt, false)
+	err := RunTeardownHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err != nil {
 		t.Fatalf("RunTeardownHooks error: %v", err)
 	}
@@ -83,7 +84,7 @@ func TestRunTeardownHooksEmpty(t *testing.T) {
 	cfg := &config.Config{}
 	wt := t.TempDir()
 
-	err := RunTeardownHooks(context.Background(), cfg, wt, false)
+	err := RunTeardownHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err != nil {
 		t.Fatalf("RunTeardownHooks with empty hooks error: %v", err)
 	}
@@ -91,11 +92,11 @@ func TestRunTeardownHooksEmpty(t *testing.T) {
 
 func TestRunTeardownHooksFailure(t *testing.T) {
 	cfg := &config.Config{
-		Teardown: []string{"false"},
+		Teardown: []config.Hook{{Run: "false"}},
 	}
 	wt := t.TempDir()
 
-	err := RunTeardownHooks(context.Background(), cfg, wt, false)
+	err := RunTeardownHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err == nil {
 		t.Fatal("expected error from failing teardown hook")
 	}
@@ -104,13 +105,13 @@ func TestRunTeardownHooksFailure(t *testing.T) {
 func TestRunParallelSetupHooks(t *testing.T) {
 	wt := t.TempDir()
 	cfg := &config.Config{
-		ParallelSetup: []string{
-			"echo hello",
-			"echo world",
+		ParallelSetup: []config.Hook{
+			{Run: "echo hello"},
+			{Run: "echo world"},
 		},
 	}
 
-	err := RunParallelSetupHooks(context.Background(), cfg, wt, false)
+	err := RunParallelSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err != nil {
 		t.Fatalf("RunParallelSetupHooks error: %v", err)
 	}
@@ -119,10 +120,10 @@ func TestRunParallelSetupHooks(t *testing.T) {
 func TestRunParallelSetupHooksDryRun(t *testing.T) {
 	wt := t.TempDir()
 	cfg := &config.Config{
-		ParallelSetup: []string{"echo hello", "echo world"},
+		ParallelSetup: []config.Hook{{Run: "echo hello"}, {Run: "echo world"}},
 	}
 
-	err := RunParallelSetupHooks(context.Background(), cfg, wt, true)
+	err := RunParallelSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, true)
 	if err != nil {
 		t.Fatalf("RunParallelSetupHooks dry-run error: %v", err)
 	}
@@ -132,7 +133,7 @@ func TestRunParallelSetupHooksEmpty(t *testing.T) {
 	wt := t.TempDir()
 	cfg := &config.Config{}
 
-	err := RunParallelSetupHooks(context.Background(), cfg, wt, false)
+	err := RunParallelSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err != nil {
 		t.Fatalf("RunParallelSetupHooks with empty hooks error: %v", err)
 	}
@@ -141,10 +142,10 @@ func TestRunParallelSetupHooksEmpty(t *testing.T) {
 func TestRunParallelSetupHooksFailure(t *testing.T) {
 	wt := t.TempDir()
 	cfg := &config.Config{
-		ParallelSetup: []string{"echo ok", "false", "echo still-runs"},
+		ParallelSetup: []config.Hook{{Run: "echo ok"}, {Run: "false"}, {Run: "echo still-runs"}},
 	}
 
-	err := RunParallelSetupHooks(context.Background(), cfg, wt, false)
+	err := RunParallelSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err == nil {
 		t.Fatal("expected error from failing parallel setup hook")
 	}
@@ -154,14 +155,14 @@ func TestRunParallelSetupHooksConcurrency(t *testing.T) {
 	wt := t.TempDir()
 	// Each command writes a file; verify all files exist afterward.
 	cfg := &config.Config{
-		ParallelSetup: []string{
-			"touch " + filepath.Join(wt, "a.txt"),
-			"touch " + filepath.Join(wt, "b.txt"),
-			"touch " + filepath.Join(wt, "c.txt"),
+		ParallelSetup: []config.Hook{
+			{Run: "touch " + filepath.Join(wt, "a.txt")},
+			{Run: "touch " + filepath.Join(wt, "b.txt")},
+			{Run: "touch " + filepath.Join(wt, "c.txt")},
 		},
 	}
 
-	err := RunParallelSetupHooks(context.Background(), cfg, wt, false)
+	err := RunParallelSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err != nil {
 		t.Fatalf("RunParallelSetupHooks error: %v", err)
 	}
@@ -176,10 +177,10 @@ func TestRunParallelSetupHooksConcurrency(t *testing.T) {
 func TestRunParallelTeardownHooks(t *testing.T) {
 	wt := t.TempDir()
 	cfg := &config.Config{
-		ParallelTeardown: []string{"echo cleanup1", "echo cleanup2"},
+		ParallelTeardown: []config.Hook{{Run: "echo cleanup1"}, {Run: "echo cleanup2"}},
 	}
 
-	err := RunParallelTeardownHooks(context.Background(), cfg, wt, false)
+	err := RunParallelTeardownHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err != nil {
 		t.Fatalf("RunParallelTeardownHooks error: %v", err)
 	}
@@ -188,11 +189,78 @@ func TestRunParallelTeardownHooks(t *testing.T) {
 func TestRunParallelTeardownHooksFailure(t *testing.T) {
 	wt := t.TempDir()
 	cfg := &config.Config{
-		ParallelTeardown: []string{"echo ok", "false"},
+		ParallelTeardown: []config.Hook{{Run: "echo ok"}, {Run: "false"}},
 	}
 
-	err := RunParallelTeardownHooks(context.Background(), cfg, wt, false)
+	err := RunParallelTeardownHooks(context.Background(), cfg, HookTarget{WorktreePath: wt}, false)
 	if err == nil {
 		t.Fatal("expected error from failing parallel teardown hook")
 	}
 }
+
+func TestRunHooksVetoStopsAtFirstFailure(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{
+		PreAdd: []config.Hook{{Run: "exit 1"}, {Run: "touch should-not-run"}},
+	}
+
+	err := RunHooks(context.Background(), cfg, HookPreAdd, HookTarget{ProjectRoot: root}, false)
+	if err == nil || !strings.Contains(err.Error(), "pre_add hook failed") {
+		t.Fatalf("err = %v, want pre_add veto", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "should-not-run")); err == nil {
+		t.Error("hooks after a failed veto hook should not run")
+	}
+}
+
+func TestRunHooksContinuesOnFailure(t *testing.T) {
+	wt := t.TempDir()
+	cfg := &config.Config{
+		PostSync: []config.Hook{{Run: "exit 1"}, {Run: "touch still-runs"}},
+	}
+
+	err := RunHooks(context.Background(), cfg, HookPostSync, HookTarget{WorktreePath: wt}, false)
+	if err == nil || !strings.Contains(err.Error(), "1 post sync hook(s) failed") {
+		t.Fatalf("err = %v, want failure count", err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "still-runs")); err != nil {
+		t.Error("non-veto hooks should keep running after a failure")
+	}
+}
+
+func TestRunHooksEnvironmentAndDir(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	record := `printf '%s|%s|%s|%s' "$WT_HOOK" "$WT_PROJECT_ROOT" "$WT_WORKTREE_PATH" "$WT_BRANCH" > env.txt`
+	cfg := &config.Config{
+		PostApply:  []config.Hook{{Run: record}},
+		PostRemove: []config.Hook{{Run: record}},
+	}
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt, Branch: "feature/x"}
+
+	if err := RunHooks(context.Background(), cfg, HookPostApply, target, false); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(wt, "env.txt"))
+	if err != nil {
+		t.Fatalf("post_apply should run in the worktree: %v", err)
+	}
+	if want := "post_apply|" + root + "|" + wt + "|feature/x"; string(got) != want {
+		t.Errorf("env = %q, want %q", got, want)
+	}
+
+	if err := RunHooks(context.Background(), cfg, HookPostRemove, target, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "env.txt")); err != nil {
+		t.Errorf("post_remove should run in the project root: %v", err)
+	}
+}
+
+func TestRunHooksDryRun(t *testing.T) {
+	wt := t.TempDir()
+	cfg := &config.Config{PreRemove: []config.Hook{{Run: "exit 1"}}}
+
+	if err := RunHooks(context.Background(), cfg, HookPreRemove, HookTarget{WorktreePath: wt}, true); err != nil {
+		t.Errorf("dry-run should not execute or veto: %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+const (
+	// BaseLayer names the shared dir itself among the layers.
+	BaseLayer = "base"
+
+	// OverlaysDir holds one directory of copy/ and symlink/ per overlay,
+	// inside the shared dir.
+	OverlaysDir = "overlays"
+
+	// HooksDir holds the script hooks, inside the shared dir.
+	HooksDir = "hooks"
+)
+
+// Layer is a directory of shared files applied to a worktree: the base
+// shared dir, or an overlay selected by the worktree's branch.
+type Layer struct {
+	Name    string   // BaseLayer or the overlay name
+	Rel     string   // relative to the shared dir; "" for the base
+	Dir     string   // holds copy/ and symlink/
+	Pattern string   // branch glob that selected an overlay
+	Exclude []string // worktree paths dropped from the layers below
+}
+
+func (l Layer) String() string {
+	if l.Name == BaseLayer {
+		return BaseLayer
+	}
+	return fmt.Sprintf("overlay %s (%s)", l.Name, l.Pattern)
+}
+
+// source names a file in the layer relative to the shared dir, as recorded
+// in the manifest.
+func (l Layer) source(kind, rel string) string {
+	return filepath.Join(l.Rel, kind, rel)
+}
+
+// excludes reports whether rel, or a directory containing it, matches one of
+// the layer's exclude globs.
+func (l Layer) excludes(rel string) bool {
+	for p := filepath.ToSlash(rel); p != "." && p != ""; p = path.Dir(p) {
+		for _, pattern := range l.Exclude {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Layers returns the base layer followed by each overlay whose branch globs
+// match branch, in config order. Later layers take precedence. An empty
+// branch selects only the base.
+func Layers(projectRoot string, cfg *config.Config, branch string) []Layer {
+	shared := SharedPath(projectRoot, cfg)
+	layers := []Layer{{Name: BaseLayer, Dir: shared}}
+	if branch == "" {
+		return layers
+	}
+	for _, o := range cfg.Overlays {
+		pattern := o.Match(branch)
+		if pattern == "" {
+			continue
+		}
+		rel := filepath.Join(OverlaysDir, o.Name)
+		layers = append(layers, Layer{
+			Name:    o.Name,
+			Rel:     rel,
+			Dir:     filepath.Join(shared, rel),
+			Pattern: pattern,
+			Exclude: o.Exclude,
+		})
+	}
+	return layers
+}
+
+// branchOf returns the branch that selects overlays for an apply with vars.
+// Without vars only the base layer applies.
+func branchOf(vars *TemplateVars) string {
+	if vars == nil {
+		return ""
+	}
+	return vars.BranchName
+}
+
+// layerPlan maps each worktree path an apply step writes to the index of
+// the layer that supplies it. Paths excluded by an overlay are absent.
+type layerPlan map[string]int
+
+// owns reports whether layer i supplies the worktree path rel.
+func (p layerPlan) owns(i int, rel string) bool {
+	owner, ok := p[rel]
+	return ok && owner == i
+}
+
+// copyDest returns the worktree path a file at rel in a copy/ dir is
+// written to.
+func copyDest(rel string, vars *TemplateVars) string {
+	if vars != nil && IsTemplateFile(rel) {
+		return StripTemplateExt(rel)
+	}
+	return rel
+}
+
+// planCopies resolves which layer's copy/ supplies each copied file.
+func planCopies(layers []Layer, vars *TemplateVars) (layerPlan, error) {
+	plan := make(layerPlan)
+	for i, layer := range layers {
+		dropExcluded(plan, layer)
+		copyDir := filepath.Join(layer.Dir, "copy")
+		err := filepath.WalkDir(copyDir, func(p string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && p == copyDir {
+				return filepath.SkipAll
+			}
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(copyDir, p)
+			if err != nil {
+				return err
+			}
+			plan[copyDest(rel, vars)] = i
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// planSymlinks resolves which layer's symlink/ supplies each top-level
+// entry. Symlinked directories are not merged across layers.
+func planSymlinks(layers []Layer) (layerPlan, error) {
+	plan := make(layerPlan)
+	for i, layer := range layers {
+		dropExcluded(plan, layer)
+		entries, err := os.ReadDir(filepath.Join(layer.Dir, "symlink"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			plan[entry.Name()] = i
+		}
+	}
+	return plan, nil
+}
+
+func dropExcluded(plan layerPlan, layer Layer) {
+	if len(layer.Exclude) == 0 {
+		return
+	}
+	for rel := range plan {
+		if layer.excludes(rel) {
+			delete(plan, rel)
+		}
+	}
+}
+
+// anyLayerHas reports whether any layer has the given subdirectory, so the
+// apply steps only announce themselves when there is something to do.
+func anyLayerHas(layers []Layer, kind string) bool {
+	for _, layer := range layers {
+		if _, err := os.Stat(filepath.Join(layer.Dir, kind)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// LayerSource is a file that one layer offers for a worktree path.
+type LayerSource struct {
+	Layer Layer
+	Mode  config.RuleMode // how apply places it
+	Path  string          // the file in the layer
+	Rule  int             // index into shared.rules, or -1 for the directory convention
+}
+
+// Explanation describes where a worktree path comes from when shared files
+// are applied.
+type Explanation struct {
+	Rel        string
+	Sources    []LayerSource // every layer offering the path, in apply order
+	Applied    int           // index of the source apply uses, or -1
+	ExcludedBy string        // overlay that dropped the path, if any
+}
+
+// Explain reports which layers offer the worktree path rel to a worktree on
+// vars.BranchName and which one apply uses. Copies are applied before
+// symlinks, so a symlinked entry wins over a copied file at the same path.
+func Explain(projectRoot string, cfg *config.Config, vars TemplateVars, rel string) (Explanation, error) {
+	rel = filepath.Clean(rel)
+	layers := Layers(projectRoot, cfg, vars.BranchName)
+	ex := Explanation{Rel: rel, Applied: -1}
+	if cfg.Shared.HasRules() {
+		return explainRules(projectRoot, cfg, layers, vars, ex)
+	}
+
+	copies, err := planCopies(layers, &vars)
+	if err != nil {
+		return ex, err
+	}
+	links, err := planSymlinks(layers)
+	if err != nil {
+		return ex, err
+	}
+
+	for i, layer := range layers {
+		for _, name := range []string{rel, rel + ".template"} {
+			src := filepath.Join(layer.Dir, "copy", name)
+			if info, err := os.Stat(src); err != nil || info.IsDir() {
+				continue
+			}
+			if copies.owns(i, rel) {
+				ex.Applied = len(ex.Sources)
+			}
+			ex.Sources = append(ex.Sources, LayerSource{Layer: layer, Mode: config.RuleCopy, Path: src, Rule: -1})
+		}
+	}
+
+	top, _, _ := strings.Cut(rel, string(filepath.Separator))
+	for i, layer := range layers {
+		src := filepath.Join(layer.Dir, "symlink", rel)
+		if _, err := os.Lstat(src); err != nil {
+			continue
+		}
+		if links.owns(i, top) {
+			ex.Applied = len(ex.Sources)
+		}
+		ex.Sources = append(ex.Sources, LayerSource{Layer: layer, Mode: config.RuleSymlink, Path: src, Rule: -1})
+	}
+
+	return ex.findExclusion(layers), nil
+}
+
+// explainRules is Explain for the items placed by shared rules. A path
+// inside a symlinked directory is explained by the directory's item.
+func explainRules(projectRoot string, cfg *config.Config, layers []Layer, vars TemplateVars, ex Explanation) (Explanation, error) {
+	plan, err := planRules(projectRoot, cfg, layers, &vars)
+	if err != nil {
+		return ex, err
+	}
+	sep := string(filepath.Separator)
+	for i, it := range plan.items {
+		if it.Dest != ex.Rel && !(it.IsDir && strings.HasPrefix(ex.Rel, it.Dest+sep)) {
+			continue
+		}
+		if plan.chosen[it.Dest] == i {
+			ex.Applied = len(ex.Sources)
+		}
+		ex.Sources = append(ex.Sources, LayerSource{Layer: layers[it.Layer], Mode: it.Mode, Path: it.Src, Rule: it.Rule})
+	}
+	return ex.findExclusion(layers), nil
+}
+
+// findExclusion sets ExcludedBy when layers offer the path but an overlay
+// dropped it.
+func (ex Explanation) findExclusion(layers []Layer) Explanation {
+	if ex.Applied >= 0 || len(ex.Sources) == 0 {
+		return ex
+	}
+	for _, layer := range layers {
+		if layer.excludes(ex.Rel) {
+			ex.ExcludedBy = layer.Name
+		}
+	}
+	return ex
+}
@@ -0,0 +1,162 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func overlayConfig() *config.Config {
+	return &config.Config{
+		SharedDir: config.DefaultSharedDir,
+		Overlays: []config.Overlay{
+			{Name: "release", Branches: []string{"release/*"}},
+			{Name: "hotfix", Branches: []string{"hotfix/*", "release/hf-*"}, Exclude: []string{"cache", "conf/big.bin"}},
+		},
+	}
+}
+
+func TestLayers(t *testing.T) {
+	root := t.TempDir()
+	cfg := overlayConfig()
+
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"main", []string{BaseLayer}},
+		{"", []string{BaseLayer}},
+		{"release/1.2", []string{BaseLayer, "release"}},
+		{"release/hf-1", []string{BaseLayer, "release", "hotfix"}},
+		{"hotfix/x", []string{BaseLayer, "hotfix"}},
+	}
+	for _, tt := range tests {
+		layers := Layers(root, cfg, tt.branch)
+		var got []string
+		for _, l := range layers {
+			got = append(got, l.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Layers(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+
+	if dir := Layers(root, cfg, "hotfix/x")[1].Dir; dir != filepath.Join(root, "shared", "overlays", "hotfix") {
+		t.Errorf("overlay dir = %q", dir)
+	}
+}
+
+func TestApplyOverlays(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", ".env"), "ENV=base\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "app.ini"), "base\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "big.bin"), "big\n")
+	writeFile(t, filepath.Join(shared, "symlink", "cache", "blob"), "blob")
+	writeFile(t, filepath.Join(shared, "symlink", "notes.md"), "base notes")
+	writeFile(t, filepath.Join(shared, "overlays", "release", "copy", ".env.template"), "ENV=${BRANCH_NAME}\n")
+	writeFile(t, filepath.Join(shared, "overlays", "release", "symlink", "notes.md"), "release notes")
+	cfg := overlayConfig()
+
+	apply := func(branch string) string {
+		t.Helper()
+		wt := filepath.Join(root, "worktrees", WorktreeIDFromBranch(branch))
+		if err := os.MkdirAll(wt, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		vars := NewTemplateVars(root, wt, branch)
+		if _, err := Apply(root, wt, cfg, false, &vars); err != nil {
+			t.Fatal(err)
+		}
+		return wt
+	}
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	main := apply("main")
+	if got := read(filepath.Join(main, ".env")); got != "ENV=base\n" {
+		t.Errorf("main .env = %q", got)
+	}
+	if got := read(filepath.Join(main, "notes.md")); got != "base notes" {
+		t.Errorf("main notes.md = %q", got)
+	}
+
+	release := apply("release/hf-1")
+	if got := read(filepath.Join(release, ".env")); got != "ENV=release/hf-1\n" {
+		t.Errorf("release .env = %q, want the overlay template", got)
+	}
+	if got := read(filepath.Join(release, "conf", "app.ini")); got != "base\n" {
+		t.Errorf("release conf/app.ini = %q, want the base file", got)
+	}
+	if got := read(filepath.Join(release, "notes.md")); got != "release notes" {
+		t.Errorf("release notes.md = %q, want the overlay link", got)
+	}
+	for _, excluded := range []string{"cache", filepath.Join("conf", "big.bin")} {
+		if _, err := os.Lstat(filepath.Join(release, excluded)); !os.IsNotExist(err) {
+			t.Errorf("%s was applied despite the hotfix exclude: %v", excluded, err)
+		}
+	}
+
+	m, err := ReadManifest(release)
+	if err != nil || m == nil {
+		t.Fatalf("ReadManifest() = %v, %v", m, err)
+	}
+	sources := make(map[string]string)
+	for _, e := range m.Entries {
+		sources[e.Path] = e.Source
+	}
+	if want := filepath.Join("overlays", "release", "copy", ".env.template"); sources[".env"] != want {
+		t.Errorf(".env source = %q, want %q", sources[".env"], want)
+	}
+	if want := filepath.Join("copy", "conf", "app.ini"); sources[filepath.Join("conf", "app.ini")] != want {
+		t.Errorf("conf/app.ini source = %q, want %q", sources[filepath.Join("conf", "app.ini")], want)
+	}
+
+	vars := NewTemplateVars(root, release, "release/hf-1")
+	if drifts, err := CheckDrift(root, release, cfg, &vars); err != nil || len(drifts) != 0 {
+		t.Errorf("CheckDrift() = %+v, %v; want no drift right after apply", drifts, err)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", ".env"), "base")
+	writeFile(t, filepath.Join(shared, "overlays", "release", "copy", ".env.template"), "release")
+	writeFile(t, filepath.Join(shared, "symlink", "cache", "blob"), "blob")
+	cfg := overlayConfig()
+	wt := filepath.Join(root, "worktrees", "x")
+
+	ex, err := Explain(root, cfg, NewTemplateVars(root, wt, "release/1"), ".env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Sources) != 2 || ex.Applied != 1 || ex.Sources[1].Layer.Name != "release" {
+		t.Errorf("Explain(.env) = %+v, want the release overlay applied over base", ex)
+	}
+
+	ex, err = Explain(root, cfg, NewTemplateVars(root, wt, "hotfix/1"), filepath.Join("cache", "blob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Sources) != 1 || ex.Applied != -1 || ex.ExcludedBy != "hotfix" {
+		t.Errorf("Explain(cache/blob) = %+v, want excluded by hotfix", ex)
+	}
+
+	ex, err = Explain(root, cfg, NewTemplateVars(root, wt, "main"), "missing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ex.Sources) != 0 || ex.Applied != -1 {
+		t.Errorf("Explain(missing.txt) = %+v, want no sources", ex)
+	}
+}
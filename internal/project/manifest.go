@@ -0,0 +1,346 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/project/fscopy"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// ManifestFile records the shared files Apply wrote into a worktree.
+const ManifestFile = ".wt-manifest.json"
+
+// ManifestKind is how a shared file was placed in the worktree.
+type ManifestKind string
+
+const (
+	ManifestCopy    ManifestKind = "copy"
+	ManifestSymlink ManifestKind = "symlink"
+)
+
+// ManifestEntry is one file or symlink written by Apply. A copy's size and
+// modification time are a quick check for edits; its hash settles whether
+// a file that passes it still has the content written.
+type ManifestEntry struct {
+	Path    string       `json:"path"`             // relative to the worktree
+	Source  string       `json:"source"`           // relative to the shared dir, e.g. copy/.env.template
+	Kind    ManifestKind `json:"kind"`             // copy or symlink
+	Size    int64        `json:"size,omitempty"`   // size of the file written (copies)
+	ModTime time.Time    `json:"mtime,omitzero"`   // modification time of the file written (copies)
+	Hash    string       `json:"hash,omitempty"`   // sha256 of the content written (copies)
+	Target  string       `json:"target,omitempty"` // link target written (symlinks)
+}
+
+// Manifest lists everything Apply has placed in a worktree. Entries whose
+// source has since been removed from shared/ are kept until pruned.
+type Manifest struct {
+	AppliedAt time.Time       `json:"applied_at"`
+	Entries   []ManifestEntry `json:"entries"`
+}
+
+// ManifestPath returns the path to the manifest file for a worktree.
+func ManifestPath(worktreePath string) string {
+	return filepath.Join(worktreePath, ManifestFile)
+}
+
+// ReadManifest reads the worktree's manifest. Returns nil with no error if
+// the worktree has none.
+func ReadManifest(worktreePath string) (*Manifest, error) {
+	data, err := os.ReadFile(ManifestPath(worktreePath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	return &m, nil
+}
+
+// WriteManifest atomically writes the manifest to the worktree directory.
+func WriteManifest(worktreePath string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	target := ManifestPath(worktreePath)
+	tmp := target + ".tmp"
+
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, target)
+}
+
+// manifestRecorder collects the entries written to worktree by one Apply
+// run, and how the copies were made. A nil recorder records nothing, so dry
+// runs and the standalone ApplyCopy and ApplySymlinks skip the bookkeeping.
+type manifestRecorder struct {
+	worktree   string
+	entries    []ManifestEntry
+	strategies map[fscopy.Strategy]int
+	previous   map[string]ManifestEntry // the manifest before this run, loaded on first use
+}
+
+// copiedWith counts n files copied with strategy. Symlinks recreated by a
+// copy have no strategy and aren't counted.
+func (r *manifestRecorder) copiedWith(strategy fscopy.Strategy, n int) {
+	if r == nil || strategy == "" {
+		return
+	}
+	if r.strategies == nil {
+		r.strategies = make(map[fscopy.Strategy]int)
+	}
+	r.strategies[strategy] += n
+}
+
+// copied records the file just written at dest, relative to the worktree.
+func (r *manifestRecorder) copied(dest, source string) error {
+	if r == nil {
+		return nil
+	}
+	path := filepath.Join(r.worktree, dest)
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	e := ManifestEntry{Path: dest, Source: source, Kind: ManifestCopy, Size: info.Size(), ModTime: info.ModTime()}
+
+	// A file rewritten to the same size and mtime from the same source has
+	// the content hashed last time, so re-applying doesn't read it again.
+	if prev, ok := r.previousEntry(dest); ok && prev.Hash != "" && prev.Source == source &&
+		prev.Size == e.Size && prev.ModTime.Equal(e.ModTime) {
+		e.Hash = prev.Hash
+	} else if e.Hash, err = hashFile(path); err != nil {
+		return err
+	}
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// previousEntry returns the entry for dest in the manifest as it was before
+// this run.
+func (r *manifestRecorder) previousEntry(dest string) (ManifestEntry, bool) {
+	if r.previous == nil {
+		r.previous = make(map[string]ManifestEntry)
+		if m, err := ReadManifest(r.worktree); err == nil && m != nil {
+			for _, e := range m.Entries {
+				r.previous[e.Path] = e
+			}
+		}
+	}
+	e, ok := r.previous[dest]
+	return e, ok
+}
+
+// copiedTree records every file in the tree just copied to dest, with
+// source naming the tree's dir relative to the shared dir. Symlinks left in
+// the copy are recorded as links.
+func (r *manifestRecorder) copiedTree(dest, source string) error {
+	if r == nil {
+		return nil
+	}
+	root := filepath.Join(r.worktree, dest)
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			r.linked(filepath.Join(dest, rel), filepath.Join(source, rel), target)
+			return nil
+		}
+		return r.copied(filepath.Join(dest, rel), filepath.Join(source, rel))
+	})
+}
+
+// linked records a symlink at dest pointing to target.
+func (r *manifestRecorder) linked(dest, source, target string) {
+	if r == nil {
+		return
+	}
+	r.entries = append(r.entries, ManifestEntry{Path: dest, Source: source, Kind: ManifestSymlink, Target: target})
+}
+
+// updateManifest writes the entries from an Apply run, carrying over earlier
+// entries for paths this run did not write so stale files stay tracked.
+func updateManifest(worktreePath string, entries []ManifestEntry) error {
+	old, err := ReadManifest(worktreePath)
+	if err != nil {
+		ui.Warning("Ignoring unreadable manifest: " + err.Error())
+		old = nil
+	}
+	if old == nil && len(entries) == 0 {
+		return nil
+	}
+
+	written := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		written[e.Path] = true
+	}
+	if old != nil {
+		for _, e := range old.Entries {
+			if !written[e.Path] {
+				entries = append(entries, e)
+			}
+		}
+	}
+	slices.SortFunc(entries, func(a, b ManifestEntry) int { return strings.Compare(a.Path, b.Path) })
+
+	return WriteManifest(worktreePath, &Manifest{AppliedAt: time.Now(), Entries: entries})
+}
+
+// StaleEntries returns the manifest entries whose source no longer exists
+// in the shared dir: copies left behind and symlinks now dangling.
+func StaleEntries(projectRoot, worktreePath string, cfg *config.Config) ([]ManifestEntry, error) {
+	m, err := ReadManifest(worktreePath)
+	if err != nil || m == nil {
+		return nil, err
+	}
+
+	sharedDir := SharedPath(projectRoot, cfg)
+	var stale []ManifestEntry
+	for _, e := range m.Entries {
+		if _, err := os.Lstat(filepath.Join(sharedDir, e.Source)); errors.Is(err, fs.ErrNotExist) {
+			stale = append(stale, e)
+		}
+	}
+	return stale, nil
+}
+
+// PruneStale removes the stale files and symlinks recorded in the worktree's
+// manifest. Copies edited since they were applied, and symlinks that were
+// repointed, are kept and reported. Returns the number removed.
+func PruneStale(projectRoot, worktreePath string, cfg *config.Config, dryRun bool) (int, error) {
+	stale, err := StaleEntries(projectRoot, worktreePath, cfg)
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+
+	ui.Step("Pruning stale shared files")
+
+	pruned := make(map[string]bool)
+	var removed int
+	for _, e := range stale {
+		path := filepath.Join(worktreePath, e.Path)
+		unchanged, err := e.unchanged(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			pruned[e.Path] = true
+			continue
+		case err != nil:
+			return removed, err
+		case !unchanged:
+			ui.Warning(fmt.Sprintf("Keeping %s: changed since it was applied", e.Path))
+			continue
+		}
+
+		if dryRun {
+			ui.DryRunNotice("remove " + path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removeEmptyParents(filepath.Dir(path), worktreePath)
+		ui.Info("  removed " + e.Path)
+		pruned[e.Path] = true
+		removed++
+	}
+
+	if dryRun || len(pruned) == 0 {
+		return removed, nil
+	}
+
+	m, err := ReadManifest(worktreePath)
+	if err != nil || m == nil {
+		return removed, err
+	}
+	m.Entries = slices.DeleteFunc(m.Entries, func(e ManifestEntry) bool { return pruned[e.Path] })
+	return removed, WriteManifest(worktreePath, m)
+}
+
+// unchanged reports whether the file or symlink at path is still what Apply
+// wrote for e.
+func (e ManifestEntry) unchanged(path string) (bool, error) {
+	if e.Kind == ManifestSymlink {
+		target, err := os.Readlink(path)
+		if err != nil {
+			if _, statErr := os.Lstat(path); statErr == nil {
+				return false, nil // replaced by a regular file
+			}
+			return false, err
+		}
+		return target == e.Target, nil
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return false, err
+	}
+	if !info.Mode().IsRegular() {
+		return false, nil
+	}
+	// Older manifests have only the hash, and a few only size and mtime.
+	if !e.ModTime.IsZero() && (info.Size() != e.Size || !info.ModTime().Equal(e.ModTime)) {
+		return false, nil
+	}
+	if e.Hash == "" {
+		return true, nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return false, err
+	}
+	return hash == e.Hash, nil
+}
+
+// removeEmptyParents removes dir and its parents while they are empty,
+// stopping at root.
+func removeEmptyParents(dir, root string) {
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,212 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestApplyWritesManifest(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", ".env.template"), "ID=${WORKTREE_ID}\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "app.json"), "{}\n")
+	writeFile(t, filepath.Join(shared, "symlink", "CLAUDE.md"), "# notes\n")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	vars := NewTemplateVars(root, wt, "feature/x")
+	if _, err := Apply(root, wt, cfg, false, &vars); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ReadManifest(wt)
+	if err != nil || m == nil {
+		t.Fatalf("ReadManifest() = %v, %v", m, err)
+	}
+	want := []ManifestEntry{
+		{Path: ".env", Source: filepath.Join("copy", ".env.template"), Kind: ManifestCopy},
+		{Path: "CLAUDE.md", Source: filepath.Join("symlink", "CLAUDE.md"), Kind: ManifestSymlink, Target: filepath.Join(shared, "symlink", "CLAUDE.md")},
+		{Path: filepath.Join("conf", "app.json"), Source: filepath.Join("copy", "conf", "app.json"), Kind: ManifestCopy},
+	}
+	if len(m.Entries) != len(want) {
+		t.Fatalf("entries = %+v, want %d", m.Entries, len(want))
+	}
+	for i, w := range want {
+		got := m.Entries[i]
+		if got.Path != w.Path || got.Source != w.Source || got.Kind != w.Kind || got.Target != w.Target {
+			t.Errorf("entry %d = %+v, want %+v", i, got, w)
+		}
+		if w.Kind == ManifestCopy {
+			info, err := os.Stat(filepath.Join(wt, w.Path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			hash, _ := hashFile(filepath.Join(wt, w.Path))
+			if got.Size != info.Size() || !got.ModTime.Equal(info.ModTime()) || got.Hash != hash {
+				t.Errorf("entry %d = %+v, want the size, mtime and hash of the written file", i, got)
+			}
+		}
+	}
+}
+
+func TestApplyDryRunSkipsManifest(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(root, "shared", "copy", "a.txt"), "a")
+
+	if _, err := Apply(root, wt, &config.Config{SharedDir: config.DefaultSharedDir}, true, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ManifestPath(wt)); !os.IsNotExist(err) {
+		t.Errorf("dry-run wrote a manifest: %v", err)
+	}
+}
+
+func TestPruneStale(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", "keep.txt"), "keep")
+	writeFile(t, filepath.Join(shared, "copy", "old", "gone.txt"), "gone")
+	writeFile(t, filepath.Join(shared, "copy", "edited.txt"), "edited")
+	writeFile(t, filepath.Join(shared, "symlink", "link.md"), "link")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete sources from shared/ and edit one worktree copy locally.
+	for _, p := range []string{filepath.Join("copy", "old"), filepath.Join("copy", "edited.txt"), filepath.Join("symlink", "link.md")} {
+		if err := os.RemoveAll(filepath.Join(shared, p)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(wt, "edited.txt"), "local change")
+
+	// Re-applying carries the stale entries over.
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := StaleEntries(root, wt, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 3 {
+		t.Fatalf("stale = %+v, want 3 entries", stale)
+	}
+
+	if n, err := PruneStale(root, wt, cfg, true); err != nil || n != 0 {
+		t.Fatalf("dry-run PruneStale() = %d, %v", n, err)
+	}
+	if _, err := os.Lstat(filepath.Join(wt, "link.md")); err != nil {
+		t.Fatal("dry-run removed a file")
+	}
+
+	n, err := PruneStale(root, wt, cfg, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("removed %d, want 2", n)
+	}
+	for _, p := range []string{filepath.Join("old", "gone.txt"), "old", "link.md"} {
+		if _, err := os.Lstat(filepath.Join(wt, p)); !os.IsNotExist(err) {
+			t.Errorf("%s should be pruned: %v", p, err)
+		}
+	}
+	for _, p := range []string{"keep.txt", "edited.txt"} {
+		if _, err := os.Stat(filepath.Join(wt, p)); err != nil {
+			t.Errorf("%s should be kept: %v", p, err)
+		}
+	}
+
+	stale, err = StaleEntries(root, wt, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0].Path != "edited.txt" {
+		t.Errorf("stale after prune = %+v, want only the edited file", stale)
+	}
+}
+
+func TestManifestEntryUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	writeFile(t, path, "applied")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full := ManifestEntry{Kind: ManifestCopy, Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+	legacy := ManifestEntry{Kind: ManifestCopy, Hash: hash}
+	for name, e := range map[string]ManifestEntry{"full": full, "legacy hash": legacy} {
+		if same, err := e.unchanged(path); err != nil || !same {
+			t.Errorf("%s: unchanged() = %v, %v, want true", name, same, err)
+		}
+	}
+
+	// An edit of the same size that keeps the mtime, as when times are
+	// preserved or the filesystem's clock is coarse, is caught by the hash.
+	writeFile(t, path, "editedX")
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	for name, e := range map[string]ManifestEntry{"full": full, "legacy hash": legacy} {
+		if same, _ := e.unchanged(path); same {
+			t.Errorf("%s: a same-size edit with the old mtime should count as changed", name)
+		}
+	}
+
+	// A new mtime counts as changed without reading the file.
+	writeFile(t, path, "applied")
+	later := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if same, _ := full.unchanged(path); same {
+		t.Error("full: a file touched since it was applied should count as changed")
+	}
+}
+
+func TestApplyReusesManifestHash(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(root, "shared", "copy", "a.txt"), "a")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Plant a marker hash: a re-apply that leaves the file's size and mtime
+	// alone carries it over instead of reading the file again.
+	m, err := ReadManifest(wt)
+	if err != nil || m == nil || len(m.Entries) != 1 {
+		t.Fatalf("ReadManifest() = %+v, %v", m, err)
+	}
+	m.Entries[0].Hash = "marker"
+	if err := WriteManifest(wt, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ = ReadManifest(wt); m.Entries[0].Hash != "marker" {
+		t.Errorf("hash = %q, want the previous hash reused", m.Entries[0].Hash)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,44 @@
+package project
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ErrProcessScanUnsupported is returned by FindProcesses where there is no
+// /proc to scan.
+var ErrProcessScanUnsupported = errors.New("listing worktree processes needs /proc, which this platform doesn't have")
+
+// WorktreeProcess is a process whose working directory, executable, or an
+// open file is inside a worktree.
+type WorktreeProcess struct {
+	PID      int
+	Command  string
+	Ports    []int // TCP ports it listens on
+	Worktree string
+}
+
+// FindProcesses returns the processes running inside each of worktreePaths,
+// keyed by the path as given. wt itself and the processes that started it,
+// such as the user's shell, are left out. Processes of other users, whose
+// /proc entries can't be read, are not seen.
+func FindProcesses(worktreePaths []string) (map[string][]WorktreeProcess, error) {
+	roots := make(map[string]string, len(worktreePaths))
+	for _, p := range worktreePaths {
+		roots[ResolvePath(p)] = p
+	}
+	return scanProcesses(roots)
+}
+
+// worktreeFor returns the root in roots that contains path, preferring the
+// deepest when worktrees nest.
+func worktreeFor(roots map[string]string, path string) (string, bool) {
+	var best string
+	for root := range roots {
+		if (path == root || strings.HasPrefix(path, root+string(filepath.Separator))) && len(root) > len(best) {
+			best = root
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,174 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const procDir = "/proc"
+
+// tcpListen is the st column of /proc/net/tcp for a listening socket.
+const tcpListen = "0A"
+
+func scanProcesses(roots map[string]string) (map[string][]WorktreeProcess, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+	skip := selfAndAncestors()
+
+	var listening map[uint64]int // socket inode to port, read on first match
+	found := make(map[string][]WorktreeProcess)
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || skip[pid] {
+			continue
+		}
+		dir := filepath.Join(procDir, e.Name())
+		root, sockets, ok := inspectProcess(dir, roots)
+		if !ok {
+			continue
+		}
+		if listening == nil {
+			listening = listeningPorts()
+		}
+
+		p := WorktreeProcess{PID: pid, Command: processCommand(dir), Worktree: roots[root]}
+		for _, inode := range sockets {
+			if port, ok := listening[inode]; ok && !slices.Contains(p.Ports, port) {
+				p.Ports = append(p.Ports, port)
+			}
+		}
+		slices.Sort(p.Ports)
+		found[p.Worktree] = append(found[p.Worktree], p)
+	}
+
+	for _, procs := range found {
+		slices.SortFunc(procs, func(a, b WorktreeProcess) int { return a.PID - b.PID })
+	}
+	return found, nil
+}
+
+// inspectProcess reports which root, if any, the process in /proc/<pid> is
+// inside: its cwd first, then its executable and open files. It also
+// returns the inodes of the process's sockets.
+func inspectProcess(dir string, roots map[string]string) (string, []uint64, bool) {
+	var root string
+	for _, link := range []string{"cwd", "exe"} {
+		if target, err := os.Readlink(filepath.Join(dir, link)); err == nil && root == "" {
+			root, _ = worktreeFor(roots, target)
+		}
+	}
+
+	fds, err := os.ReadDir(filepath.Join(dir, "fd"))
+	if err != nil {
+		// Another user's process, or one that just exited.
+		return root, nil, root != ""
+	}
+	var sockets []uint64
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(dir, "fd", fd.Name()))
+		if err != nil {
+			continue
+		}
+		if inode, ok := strings.CutPrefix(target, "socket:["); ok {
+			if n, err := strconv.ParseUint(strings.TrimSuffix(inode, "]"), 10, 64); err == nil {
+				sockets = append(sockets, n)
+			}
+			continue
+		}
+		if root == "" && filepath.IsAbs(target) {
+			root, _ = worktreeFor(roots, target)
+		}
+	}
+	return root, sockets, root != ""
+}
+
+// processCommand returns the process's command line, or its name in
+// brackets when it has none, as ps does.
+func processCommand(dir string) string {
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		args := strings.Fields(string(bytes.ReplaceAll(cmdline, []byte{0}, []byte{' '})))
+		if len(args) > 0 {
+			return strings.Join(args, " ")
+		}
+	}
+	comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+	return "[" + strings.TrimSpace(string(comm)) + "]"
+}
+
+// selfAndAncestors returns wt's PID and those of its parents, up to init.
+// Killing them would take down the shell wt was run from.
+func selfAndAncestors() map[int]bool {
+	pids := make(map[int]bool)
+	for pid := os.Getpid(); pid > 1 && !pids[pid]; {
+		pids[pid] = true
+		stat, err := os.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "stat"))
+		if err != nil {
+			break
+		}
+		pid = parentPID(stat)
+	}
+	return pids
+}
+
+// parentPID reads the ppid field of /proc/<pid>/stat. The command name
+// before it is parenthesized and may itself contain spaces and parentheses.
+func parentPID(stat []byte) int {
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return 0
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
+
+// listeningPorts maps the inodes of listening TCP sockets to their ports.
+func listeningPorts() map[uint64]int {
+	ports := make(map[uint64]int)
+	for _, name := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(procDir, "net", name))
+		if err != nil {
+			continue
+		}
+		parseListenPorts(f, ports)
+		_ = f.Close()
+	}
+	return ports
+}
+
+// parseListenPorts adds the listening sockets of a /proc/net/tcp table to
+// ports.
+func parseListenPorts(r io.Reader, ports map[uint64]int) {
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseUint(hexPort, 16, 16)
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		ports[inode] = int(port)
+	}
+}
@@ -0,0 +1,76 @@
+package project
+
+import (
+	"net"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFindProcesses(t *testing.T) {
+	worktree := t.TempDir()
+	other := t.TempDir()
+
+	cmd := exec.Command("sleep", "30")
+	cmd.Dir = worktree
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("could not start sleep: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	found, err := FindProcesses([]string{worktree, other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found[other]) != 0 {
+		t.Errorf("found %v in an empty worktree", found[other])
+	}
+	i := slices.IndexFunc(found[worktree], func(p WorktreeProcess) bool { return p.PID == cmd.Process.Pid })
+	if i < 0 {
+		t.Fatalf("sleep (PID %d) not found in %v", cmd.Process.Pid, found[worktree])
+	}
+	if p := found[worktree][i]; p.Command != "sleep 30" || p.Worktree != worktree {
+		t.Errorf("got %+v, want command %q in %s", p, "sleep 30", worktree)
+	}
+}
+
+func TestListeningPorts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	ports := listeningPorts()
+	for _, p := range ports {
+		if p == port {
+			return
+		}
+	}
+	t.Errorf("listeningPorts() = %v, missing %d", ports, port)
+}
+
+func TestParseListenPorts(t *testing.T) {
+	table := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 0100007F:0BB8 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 41234 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:0BB8 0100007F:D431 01 00000000:00000000 00:00000000 00000000  1000        0 41299 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 0 1 0000000000000000 100 0 0 10 0
+`
+	ports := make(map[uint64]int)
+	parseListenPorts(strings.NewReader(table), ports)
+	if len(ports) != 1 || ports[41234] != 3000 {
+		t.Errorf("got %v, want only inode 41234 on port 3000", ports)
+	}
+}
+
+func TestParentPID(t *testing.T) {
+	stat := []byte("4242 (my (odd) cmd) S 17 4242 4242 0 -1 4194560")
+	if got := parentPID(stat); got != 17 {
+		t.Errorf("parentPID = %d, want 17", got)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package project
+
+func scanProcesses(map[string]string) (map[string][]WorktreeProcess, error) {
+	return nil, ErrProcessScanUnsupported
+}
@@ -0,0 +1,26 @@
+package project
+
+import "testing"
+
+func TestWorktreeFor(t *testing.T) {
+	roots := map[string]string{
+		"/p/worktrees/feature":   "feature",
+		"/p/worktrees/feature/x": "feature/x",
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/p/worktrees/feature", "/p/worktrees/feature"},
+		{"/p/worktrees/feature/src/main.go", "/p/worktrees/feature"},
+		{"/p/worktrees/feature/x/node_modules", "/p/worktrees/feature/x"},
+		{"/p/worktrees/feature-2", ""},
+		{"/p", ""},
+	}
+	for _, tt := range tests {
+		got, ok := worktreeFor(roots, tt.path)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("worktreeFor(%q) = %q, %v; want %q", tt.path, got, ok, tt.want)
+		}
+	}
+}
@@ -70,3 +70,12 @@ func WorktreesPath(projectRoot string, cfg *config.Config) string {
 func SharedPath(projectRoot string, cfg *config.Config) string {
 	return filepath.Join(projectRoot, cfg.SharedDir)
 }
+
+// ResolvePath returns p with symlinks resolved, or cleaned when it can't be,
+// so paths that reach the same directory compare equal.
+func ResolvePath(p string) string {
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved
+	}
+	return filepath.Clean(p)
+}
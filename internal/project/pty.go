@@ -0,0 +1,163 @@
+package project
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/charmbracelet/x/term"
+	"github.com/creack/pty"
+	"github.com/muesli/cancelreader"
+)
+
+const (
+	// ptyRows is the height reported to hooks running under a PTY. Only the
+	// width matters for progress bars, which are sized to the terminal.
+	ptyRows = 24
+
+	// ptyDrainTimeout bounds how long to keep reading after a PTY hook exits.
+	// Daemons it started can hold the terminal open indefinitely.
+	ptyDrainTimeout = 500 * time.Millisecond
+)
+
+// ptyEOF is the end-of-file character of a pseudo-terminal in its default,
+// line-buffered mode. Writing it on an empty line makes the child's read
+// return EOF.
+const ptyEOF = 0x04
+
+// runHookCmd runs cmd with stdout and stderr sent to out, under a
+// pseudo-terminal when usePTY is set. stdin is what the hook reads, or nil
+// for no input.
+func runHookCmd(cmd *exec.Cmd, out io.Writer, usePTY bool, stdin *os.File) error {
+	if !usePTY {
+		if stdin != nil {
+			cmd.Stdin = stdin
+		}
+		cmd.Stdout = out
+		cmd.Stderr = out
+		return cmd.Run()
+	}
+	return runUnderPTY(cmd, out, stdin)
+}
+
+// runUnderPTY runs cmd attached to a new pseudo-terminal and copies what it
+// writes to out. Escape codes are stripped unless out is itself a terminal,
+// and the terminal's \r\n line endings are turned back into \n.
+//
+// When stdin is a terminal, it is switched to raw mode and forwarded, so the
+// pseudo-terminal does the line editing and turns ^C into a signal for the
+// hook. Otherwise the hook reads EOF rather than waiting for input that
+// never comes.
+func runUnderPTY(cmd *exec.Cmd, out io.Writer, stdin *os.File) error {
+	if !ui.IsTerminal(out) {
+		out = colorprofile.NewWriter(out, os.Environ())
+	}
+	w := &crlfWriter{w: out}
+
+	cols := ui.TerminalWidth(ui.Output)
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{
+		Cols: uint16(cols), //nolint:gosec // G115: terminal widths fit in uint16
+		Rows: ptyRows,
+	})
+	if err != nil {
+		return err
+	}
+
+	stopInput := forwardInput(ptmx, stdin)
+
+	copied := make(chan struct{})
+	go func() {
+		// Reading fails with EIO once every process has closed the terminal,
+		// which is the normal end of output rather than an error.
+		_, _ = io.Copy(w, ptmx)
+		close(copied)
+	}()
+
+	err = cmd.Wait()
+	stopInput()
+	select {
+	case <-copied:
+	case <-time.After(ptyDrainTimeout):
+	}
+	_ = ptmx.Close()
+	<-copied
+	w.flush()
+	return err
+}
+
+// forwardInput copies stdin into ptmx when stdin is a terminal and sends EOF
+// otherwise. The returned function stops forwarding and restores the
+// terminal; it must be called once the hook exits, or the copy would keep
+// consuming keystrokes meant for wt.
+func forwardInput(ptmx, stdin *os.File) func() {
+	if stdin != nil && term.IsTerminal(stdin.Fd()) {
+		if stop, err := forwardTerminal(ptmx, stdin); err == nil {
+			return stop
+		}
+	}
+	_, _ = ptmx.Write([]byte{ptyEOF})
+	return func() {}
+}
+
+// forwardTerminal switches stdin to raw mode and copies it into ptmx until
+// the returned function is called.
+func forwardTerminal(ptmx, stdin *os.File) (func(), error) {
+	state, err := term.MakeRaw(stdin.Fd())
+	if err != nil {
+		return nil, err
+	}
+	r, err := cancelreader.NewReader(stdin)
+	if err != nil {
+		_ = term.Restore(stdin.Fd(), state)
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		// The copy ends with an error once the reader is canceled.
+		_, _ = io.Copy(ptmx, r)
+		close(done)
+	}()
+	return func() {
+		r.Cancel()
+		<-done
+		_ = r.Close()
+		_ = term.Restore(stdin.Fd(), state)
+	}, nil
+}
+
+// crlfWriter converts \r\n to \n, leaving lone carriage returns (used by
+// progress bars to redraw a line) intact.
+type crlfWriter struct {
+	w  io.Writer
+	cr bool // a trailing \r is held back until the next byte is known
+}
+
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(p)+1)
+	if c.cr {
+		buf = append(buf, '\r')
+		c.cr = false
+	}
+	buf = append(buf, p...)
+	if n := len(buf); n > 0 && buf[n-1] == '\r' {
+		c.cr = true
+		buf = buf[:n-1]
+	}
+	if _, err := c.w.Write(bytes.ReplaceAll(buf, []byte("\r\n"), []byte("\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// flush writes a held-back trailing carriage return.
+func (c *crlfWriter) flush() {
+	if c.cr {
+		_, _ = c.w.Write([]byte{'\r'})
+		c.cr = false
+	}
+}
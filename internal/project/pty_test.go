@@ -0,0 +1,120 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+func TestCRLFWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{"plain newline", []string{"a\n"}, "a\n"},
+		{"crlf", []string{"a\r\nb\r\n"}, "a\nb\n"},
+		{"crlf split across writes", []string{"a\r", "\nb"}, "a\nb"},
+		{"lone carriage return kept", []string{"10%\r20%\r\n"}, "10%\r20%\n"},
+		{"trailing carriage return flushed", []string{"50%\r"}, "50%\r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &crlfWriter{w: &buf}
+			for _, s := range tt.writes {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			w.flush()
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHookCmdPTY(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := exec.Command("sh", "-c", `test -t 1 && printf '\033[31mtty\033[0m\n'`)
+	if err := runHookCmd(cmd, &buf, true, nil); err != nil {
+		t.Skipf("pseudo-terminals unavailable: %v", err)
+	}
+
+	// Escape codes are stripped because the buffer is not a terminal.
+	if got := buf.String(); got != "tty\n" {
+		t.Errorf("output = %q, want %q", got, "tty\n")
+	}
+}
+
+func TestRunHookCmdWithoutPTY(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := exec.Command("sh", "-c", `test -t 1 || echo pipe`)
+	if err := runHookCmd(cmd, &buf, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "pipe\n" {
+		t.Errorf("output = %q, want %q", got, "pipe\n")
+	}
+}
+
+func TestRunHookCmdPTYExitStatus(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "exit 3")
+	err := runHookCmd(cmd, io.Discard, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("err = %v, want exit status 3", err)
+	}
+}
+
+func TestRunSetupHooksPTY(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	off := false
+	cfg := &config.Config{
+		PTY: true,
+		Setup: []config.Hook{
+			{Run: "test -t 1 && echo first-tty"},
+			{Run: "test -t 1 || echo second-pipe", PTY: &off},
+		},
+	}
+	if err := RunSetupHooks(context.Background(), cfg, HookTarget{WorktreePath: t.TempDir()}, false, nil); err != nil {
+		t.Fatalf("RunSetupHooks error: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "first-tty\n") || !strings.Contains(out, "second-pipe\n") {
+		t.Errorf("output = %q, want the pty hook on a terminal and the other on a pipe", out)
+	}
+}
+
+func TestRunHookCmdPTYStdinEOF(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := exec.Command("sh", "-c", `read x; echo "read=$?"`)
+	done := make(chan error, 1)
+	go func() { done <- runHookCmd(cmd, &buf, true, nil) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Skipf("pseudo-terminals unavailable: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		t.Fatal("hook reading stdin under a PTY did not get EOF")
+	}
+	if got := buf.String(); got != "read=1\n" {
+		t.Errorf("output = %q, want %q", got, "read=1\n")
+	}
+}
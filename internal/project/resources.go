@@ -0,0 +1,200 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"golang.org/x/sys/unix"
+)
+
+// ResourcesFile is the registry of external resources, such as docker
+// volumes and databases, that hooks created for worktrees. It lives in the
+// git dir so it outlives the worktrees it lists.
+const ResourcesFile = "wt-resources.json"
+
+// HookResourceCleanup is the WT_HOOK value for resource_cleanup commands.
+const HookResourceCleanup HookPoint = "resource_cleanup"
+
+// Resource is one external resource registered for a worktree.
+type Resource struct {
+	Kind         string    `json:"kind"`
+	ID           string    `json:"id"`
+	Branch       string    `json:"branch"`
+	WorktreePath string    `json:"worktree_path"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Orphaned reports whether the worktree the resource was registered for no
+// longer exists.
+func (r Resource) Orphaned() bool {
+	_, err := os.Stat(r.WorktreePath)
+	return errors.Is(err, fs.ErrNotExist)
+}
+
+type resourceRegistry struct {
+	Resources []Resource `json:"resources"`
+}
+
+// ResourcesPath returns the path to the project's resource registry.
+func ResourcesPath(projectRoot string, cfg *config.Config) string {
+	return filepath.Join(GitDirPath(projectRoot, cfg), ResourcesFile)
+}
+
+// ReadResources returns the registered resources, or none if nothing has
+// been registered.
+func ReadResources(projectRoot string, cfg *config.Config) ([]Resource, error) {
+	return readResources(ResourcesPath(projectRoot, cfg))
+}
+
+func readResources(path string) ([]Resource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var reg resourceRegistry
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("%s: %w", ResourcesFile, err)
+	}
+	return reg.Resources, nil
+}
+
+func writeResources(path string, resources []Resource) error {
+	data, err := json.MarshalIndent(resourceRegistry{Resources: resources}, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// updateResources applies fn to the registry under an exclusive lock, so
+// parallel setup hooks registering at once don't lose each other's entries.
+func updateResources(projectRoot string, cfg *config.Config, fn func([]Resource) []Resource) error {
+	path := ResourcesPath(projectRoot, cfg)
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Close() }()
+	fd := int(lock.Fd()) //nolint:gosec // G115: file descriptors always fit in an int
+	if err := unix.Flock(fd, unix.LOCK_EX); err != nil {
+		return err
+	}
+	defer func() { _ = unix.Flock(fd, unix.LOCK_UN) }()
+
+	resources, err := readResources(path)
+	if err != nil {
+		return err
+	}
+	return writeResources(path, fn(resources))
+}
+
+// RegisterResource records r. Registering a kind and ID again moves it to
+// r's worktree.
+func RegisterResource(projectRoot string, cfg *config.Config, r Resource, dryRun bool) error {
+	if dryRun {
+		ui.DryRunNotice(fmt.Sprintf("register %s %s for %s", r.Kind, r.ID, r.Branch))
+		return nil
+	}
+	if r.RegisteredAt.IsZero() {
+		r.RegisteredAt = time.Now()
+	}
+	return updateResources(projectRoot, cfg, func(resources []Resource) []Resource {
+		resources = slices.DeleteFunc(resources, func(e Resource) bool { return e.Kind == r.Kind && e.ID == r.ID })
+		return append(resources, r)
+	})
+}
+
+// MoveResources re-registers the resources of the worktree at from for the
+// worktree at to, after the worktree was moved there.
+func MoveResources(projectRoot string, cfg *config.Config, from, to string, dryRun bool) error {
+	resources, err := ReadResources(projectRoot, cfg)
+	if err != nil || len(WorktreeResources(resources, from)) == 0 {
+		return err
+	}
+	if dryRun {
+		ui.DryRunNotice(fmt.Sprintf("move resources of %s to %s", from, to))
+		return nil
+	}
+	return updateResources(projectRoot, cfg, func(resources []Resource) []Resource {
+		for i := range resources {
+			if resources[i].WorktreePath == from {
+				resources[i].WorktreePath = to
+			}
+		}
+		return resources
+	})
+}
+
+// WorktreeResources returns the resources registered for the worktree at
+// worktreePath.
+func WorktreeResources(resources []Resource, worktreePath string) []Resource {
+	var matched []Resource
+	for _, r := range resources {
+		if r.WorktreePath == worktreePath {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// CleanResources runs the resource_cleanup command for each resource's
+// kind in the project root, with WT_RESOURCE_KIND and WT_RESOURCE_ID set,
+// and drops the resources it cleaned from the registry. Resources whose
+// kind has no cleanup command, or whose cleanup failed, are reported and
+// kept. Returns how many were cleaned.
+func CleanResources(ctx context.Context, projectRoot string, cfg *config.Config, resources []Resource, dryRun bool) (int, error) {
+	var cleaned []Resource
+	for _, r := range resources {
+		h, ok := cfg.ResourceCleanup[r.Kind]
+		if !ok {
+			ui.Warning(fmt.Sprintf("Keeping %s %s: no resource_cleanup command for %s", r.Kind, r.ID, r.Kind))
+			continue
+		}
+		if dryRun {
+			ui.DryRunNotice(fmt.Sprintf("clean %s %s: %s", r.Kind, r.ID, describeHook(cfg, projectRoot, h)))
+			continue
+		}
+
+		target := HookTarget{ProjectRoot: projectRoot, WorktreePath: r.WorktreePath, Branch: r.Branch}
+		cmd, err := hookCommand(ctx, cfg, HookResourceCleanup, target, h)
+		if err != nil {
+			ui.Warning(fmt.Sprintf("Keeping %s %s: %s", r.Kind, r.ID, err))
+			continue
+		}
+		cmd.Env = append(cmd.Env, "WT_RESOURCE_KIND="+r.Kind, "WT_RESOURCE_ID="+r.ID)
+		if err := runHookCmd(cmd, ui.Output, cfg.UsePTY(h), nil); err != nil {
+			ui.Warning(fmt.Sprintf("Keeping %s %s: cleanup failed: %s", r.Kind, r.ID, err))
+			continue
+		}
+		ui.Info(fmt.Sprintf("  cleaned %s %s", r.Kind, r.ID))
+		cleaned = append(cleaned, r)
+	}
+
+	if len(cleaned) == 0 {
+		return 0, nil
+	}
+	err := updateResources(projectRoot, cfg, func(resources []Resource) []Resource {
+		return slices.DeleteFunc(resources, func(e Resource) bool {
+			return slices.ContainsFunc(cleaned, func(c Resource) bool { return c.Kind == e.Kind && c.ID == e.ID })
+		})
+	})
+	return len(cleaned), err
+}
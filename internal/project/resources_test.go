@@ -0,0 +1,116 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestRegisterResource(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{GitDir: ".bare"}
+	if err := os.MkdirAll(filepath.Join(root, ".bare"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	wtA := filepath.Join(root, "worktrees", "a")
+	wtB := filepath.Join(root, "worktrees", "b")
+
+	for _, r := range []Resource{
+		{Kind: "docker-volume", ID: "a_data", Branch: "a", WorktreePath: wtA},
+		{Kind: "database", ID: "app_a", Branch: "a", WorktreePath: wtA},
+		// Registering again moves the resource instead of duplicating it.
+		{Kind: "database", ID: "app_a", Branch: "b", WorktreePath: wtB},
+	} {
+		if err := RegisterResource(root, cfg, r, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := RegisterResource(root, cfg, Resource{Kind: "database", ID: "dry", WorktreePath: wtA}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := ReadResources(root, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 {
+		t.Fatalf("ReadResources = %+v, want 2 resources", resources)
+	}
+	if got := WorktreeResources(resources, wtB); len(got) != 1 || got[0].ID != "app_a" {
+		t.Errorf("WorktreeResources(b) = %+v, want the moved database", got)
+	}
+	if !resources[0].Orphaned() {
+		t.Error("resource for a missing worktree should be orphaned")
+	}
+}
+
+func TestMoveResources(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{GitDir: ".bare"}
+	if err := os.MkdirAll(filepath.Join(root, ".bare"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	external := filepath.Join(t.TempDir(), "checkout")
+	adopted := filepath.Join(root, "worktrees", "feature")
+
+	if err := RegisterResource(root, cfg, Resource{Kind: "database", ID: "app_feature", Branch: "feature", WorktreePath: external}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveResources(root, cfg, external, adopted, false); err != nil {
+		t.Fatal(err)
+	}
+	resources, err := ReadResources(root, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(WorktreeResources(resources, external)) != 0 || len(WorktreeResources(resources, adopted)) != 1 {
+		t.Errorf("after MoveResources = %+v, want the database under %s", resources, adopted)
+	}
+}
+
+func TestCleanResources(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".bare"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		GitDir: ".bare",
+		ResourceCleanup: map[string]config.Hook{
+			"volume": {Run: `echo "$WT_RESOURCE_KIND $WT_RESOURCE_ID" >> cleaned.log`},
+			"broken": {Run: "exit 1"},
+		},
+	}
+	gone := filepath.Join(root, "worktrees", "gone")
+	resources := []Resource{
+		{Kind: "volume", ID: "gone_data", Branch: "gone", WorktreePath: gone},
+		{Kind: "broken", ID: "x", Branch: "gone", WorktreePath: gone},
+		{Kind: "unknown", ID: "y", Branch: "gone", WorktreePath: gone},
+	}
+	for _, r := range resources {
+		if err := RegisterResource(root, cfg, r, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleaned, err := CleanResources(context.Background(), root, cfg, resources, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cleaned != 1 {
+		t.Errorf("cleaned = %d, want 1", cleaned)
+	}
+	if got, err := os.ReadFile(filepath.Join(root, "cleaned.log")); err != nil || string(got) != "volume gone_data\n" {
+		t.Errorf("cleanup ran with %q, %v", got, err)
+	}
+
+	left, err := ReadResources(root, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 2 {
+		t.Errorf("registry after clean = %+v, want the failed and unconfigured resources kept", left)
+	}
+}
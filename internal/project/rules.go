@@ -0,0 +1,410 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/project/fscopy"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// ruleItem is one file or directory a shared rule places in the worktree.
+type ruleItem struct {
+	Dest   string // worktree path
+	Src    string // file or directory in the layer
+	Source string // Src relative to the shared dir, as recorded in the manifest
+	Mode   config.RuleMode
+	Render bool // substitute template variables
+	IsDir  bool // a symlinked directory
+	Rule   int  // index into cfg.Shared.Rules
+	Layer  int  // index into the layers
+}
+
+// rulePlan holds every item the rules produce across the layers, in layer
+// order, and which item is applied at each destination.
+type rulePlan struct {
+	items  []ruleItem
+	chosen map[string]int // Dest -> index into items
+}
+
+// applied returns the items apply writes, in destination order, filtered
+// to symlinks or to everything else.
+func (p rulePlan) applied(symlinks bool) []ruleItem {
+	var out []ruleItem
+	for _, i := range p.chosen {
+		if (p.items[i].Mode == config.RuleSymlink) == symlinks {
+			out = append(out, p.items[i])
+		}
+	}
+	slices.SortFunc(out, func(a, b ruleItem) int { return strings.Compare(a.Dest, b.Dest) })
+	return out
+}
+
+// reservedSharedDirs are directories of the base shared dir that hold wt's
+// own files rather than files to place.
+var reservedSharedDirs = []string{OverlaysDir, HooksDir}
+
+// planRules matches the rules against each layer's files. Later layers
+// replace earlier items at the same destination, and an overlay's excludes
+// drop items from the layers below it.
+func planRules(projectRoot string, cfg *config.Config, layers []Layer, vars *TemplateVars) (rulePlan, error) {
+	rules := cfg.Shared.Rules
+	sharedDir := SharedPath(projectRoot, cfg)
+	plan := rulePlan{chosen: make(map[string]int)}
+
+	for li, layer := range layers {
+		for dest := range plan.chosen {
+			if layer.excludes(dest) {
+				delete(plan.chosen, dest)
+			}
+		}
+
+		// dirRules records directories matched by a copy-like rule, whose
+		// files the rule covers.
+		type dirMatch struct {
+			rule  int
+			match string
+		}
+		dirRules := make(map[string]dirMatch)
+
+		err := filepath.WalkDir(layer.Dir, func(p string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && p == layer.Dir {
+				return filepath.SkipAll
+			}
+			if err != nil {
+				return err
+			}
+			if p == layer.Dir {
+				return nil
+			}
+			rel, err := filepath.Rel(layer.Dir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if li == 0 && d.IsDir() && slices.Contains(reservedSharedDirs, rel) {
+				return filepath.SkipDir
+			}
+
+			ri, match := matchRules(rules, rel)
+			// The nearest directory a rule matched covers rel too, and wins
+			// over a later rule matching rel itself: the first rule decides.
+			for dir := path.Dir(rel); dir != "."; dir = path.Dir(dir) {
+				if m, ok := dirRules[dir]; ok {
+					if (ri < 0 || m.rule < ri) && !globsMatch(rules[m.rule].Exclude, rel) {
+						ri, match = m.rule, m.match
+					}
+					break
+				}
+			}
+			if ri < 0 {
+				return nil
+			}
+
+			rule := rules[ri]
+			if d.IsDir() && rule.Mode != config.RuleSymlink {
+				dirRules[rel] = dirMatch{ri, match}
+				return nil
+			}
+
+			dest := ruleDest(rule, match, rel)
+			if dest == "." {
+				return nil // e.g. symlink/** matching symlink itself
+			}
+			render := rule.Mode == config.RuleTemplate || (rule.Mode == config.RuleCopy && IsTemplateFile(rel))
+			render = render && vars != nil
+			if render {
+				dest = StripTemplateExt(dest)
+			}
+
+			source, err := filepath.Rel(sharedDir, p)
+			if err != nil {
+				return err
+			}
+			plan.chosen[dest] = len(plan.items)
+			plan.items = append(plan.items, ruleItem{
+				Dest:   dest,
+				Src:    p,
+				Source: source,
+				Mode:   rule.Mode,
+				Render: render,
+				IsDir:  d.IsDir(),
+				Rule:   ri,
+				Layer:  li,
+			})
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+// matchRules returns the first rule with an include pattern matching rel,
+// and not excluding it, along with that pattern.
+func matchRules(rules []config.SharedRule, rel string) (int, string) {
+	for i, r := range rules {
+		for _, pattern := range r.Include {
+			if matchGlob(pattern, rel) && !globsMatch(r.Exclude, rel) {
+				return i, pattern
+			}
+		}
+	}
+	return -1, ""
+}
+
+// globsMatch reports whether rel, or a directory containing it, matches any
+// of patterns.
+func globsMatch(patterns []string, rel string) bool {
+	for p := rel; p != "." && p != ""; p = path.Dir(p) {
+		for _, pattern := range patterns {
+			if matchGlob(pattern, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ruleDest returns the worktree path for rel, matched by the include
+// pattern (or found below a directory matched by it).
+func ruleDest(rule config.SharedRule, pattern, rel string) string {
+	base, literal := globBase(pattern)
+	if literal {
+		// The pattern names one file or directory; Dest renames it.
+		name := path.Base(pattern)
+		if rule.Dest != "" {
+			name = filepath.ToSlash(filepath.Clean(rule.Dest))
+		}
+		if below := strings.TrimPrefix(rel, pattern); below != rel && below != "" {
+			name += below
+		}
+		return filepath.FromSlash(name)
+	}
+	below := rel
+	if base != "" {
+		below = strings.TrimPrefix(rel, base+"/")
+		if below == rel && rel == base {
+			below = "."
+		}
+	}
+	return filepath.Join(rule.Dest, filepath.FromSlash(below))
+}
+
+// globBase returns the leading directories of pattern that contain no
+// wildcards, and whether the whole pattern is free of them.
+func globBase(pattern string) (string, bool) {
+	segs := strings.Split(pattern, "/")
+	for i, seg := range segs {
+		if seg == "**" || strings.ContainsAny(seg, `*?[\`) {
+			return strings.Join(segs[:i], "/"), false
+		}
+	}
+	return path.Dir(pattern), true
+}
+
+// matchGlob reports whether the slash-separated path p matches pattern,
+// where a ** segment matches any number of directories.
+func matchGlob(pattern, p string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// want returns the content apply writes for a copy-like item.
+func (it ruleItem) want(vars *TemplateVars) ([]byte, error) {
+	content, err := os.ReadFile(it.Src)
+	if err != nil || !it.Render {
+		return content, err
+	}
+	rendered, err := RenderTemplate(string(content), *vars)
+	return []byte(rendered), err
+}
+
+// applyRules places the items the shared rules produce for the worktree:
+// the symlinks when symlinks is set, otherwise the copies, templates, and
+// hard links.
+func applyRules(projectRoot, worktreePath string, cfg *config.Config, branch string, dryRun bool, vars *TemplateVars, rec *manifestRecorder, symlinks bool) (int, error) {
+	plan, err := planRules(projectRoot, cfg, Layers(projectRoot, cfg, branch), vars)
+	if err != nil {
+		return 0, err
+	}
+	items := plan.applied(symlinks)
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	if symlinks {
+		ui.Step("Creating symlinks")
+	} else {
+		ui.Step("Copying shared files")
+	}
+
+	policy := cfg.ApplyConflictOrDefault()
+	var count int
+	for _, it := range items {
+		n, err := placeItem(it, worktreePath, cfg, policy, dryRun, vars, rec)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// placeItem writes one planned item into the worktree, handling a copy the
+// worktree has changed as policy says.
+func placeItem(it ruleItem, worktreePath string, cfg *config.Config, policy config.ConflictPolicy, dryRun bool, vars *TemplateVars, rec *manifestRecorder) (int, error) {
+	dest := filepath.Join(worktreePath, it.Dest)
+	if dryRun {
+		ui.DryRunNotice(fmt.Sprintf("%s %s -> %s", it.Mode, it.Src, dest))
+		return 0, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return 0, err
+	}
+
+	var n int
+	var err error
+	opts := copyOptions(cfg, it.Dest)
+	switch it.Mode {
+	case config.RuleSymlink:
+		n, err = placeRuleSymlink(it, dest, worktreePath, cfg.RelativeSymlinks(), rec)
+	case config.RuleHardlink:
+		// Edits through either name change the shared file.
+		opts.Mode = fscopy.ModeHardlink
+		n, err = placeRuleCopy(it, dest, policy, opts, vars, rec)
+	default:
+		n, err = placeRuleCopy(it, dest, policy, opts, vars, rec)
+	}
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", it.Dest, err)
+	}
+	return n, nil
+}
+
+func placeRuleCopy(it ruleItem, dest string, policy config.ConflictPolicy, opts fscopy.Options, vars *TemplateVars, rec *manifestRecorder) (int, error) {
+	if !it.Render {
+		info, err := os.Lstat(it.Src)
+		asLink := err == nil && info.Mode()&os.ModeSymlink != 0 && opts.Symlinks
+		if !asLink && it.Mode == config.RuleHardlink && sameFile(dest, it.Src) {
+			return 0, rec.copied(it.Dest, it.Source)
+		}
+		if !asLink && policy != config.ConflictOverwrite {
+			want, err := os.ReadFile(it.Src)
+			if err != nil {
+				return 0, err
+			}
+			if keep, err := keepLocalCopy(policy, it.Dest, dest, want); err != nil || keep {
+				return 0, err
+			}
+		}
+		strategy, err := fscopy.CopyFile(it.Src, dest, opts)
+		if err != nil {
+			return 0, err
+		}
+		rec.copiedWith(strategy, 1)
+		if asLink {
+			// Copied as a link; recorded like one so prune can tell it is unchanged.
+			target, err := os.Readlink(it.Src)
+			if err != nil {
+				return 0, err
+			}
+			rec.linked(it.Dest, it.Source, target)
+		} else if err := rec.copied(it.Dest, it.Source); err != nil {
+			return 0, err
+		}
+		switch {
+		case strategy == fscopy.StrategyHardlink:
+			ui.Info("  hard-linked " + it.Dest)
+		case it.Mode == config.RuleHardlink:
+			ui.Info("  copied " + it.Dest + " (hard link not possible across filesystems)")
+		default:
+			ui.Info("  copied " + it.Dest)
+		}
+		return 1, nil
+	}
+
+	template, err := os.ReadFile(it.Src)
+	if err != nil {
+		return 0, err
+	}
+	rendered, err := RenderTemplate(string(template), *vars)
+	if err != nil {
+		return 0, err
+	}
+	want := []byte(rendered)
+	info, err := os.Stat(it.Src)
+	if err != nil {
+		return 0, err
+	}
+	if keep, err := keepLocalCopy(policy, it.Dest, dest, want); err != nil || keep {
+		return 0, err
+	}
+	ui.Info("  substituted template variables in " + it.Dest)
+	if err := writeRendered(dest, template, want, info.Mode()); err != nil {
+		return 0, err
+	}
+	return 1, rec.copied(it.Dest, it.Source)
+}
+
+func placeRuleSymlink(it ruleItem, dest, worktreePath string, relative bool, rec *manifestRecorder) (int, error) {
+	// Merge into a real directory rather than replacing it, as with
+	// shared/symlink.
+	if it.IsDir {
+		if info, err := os.Lstat(dest); err == nil && info.IsDir() && info.Mode()&os.ModeSymlink == 0 {
+			return symlinkDirContents(it.Src, dest, worktreePath, it.Source, relative, rec)
+		}
+	}
+
+	_ = os.Remove(dest)
+	written := linkTarget(it.Src, dest, relative)
+	if err := os.Symlink(written, dest); err != nil {
+		return 0, err
+	}
+	rec.linked(it.Dest, it.Source, written)
+
+	relTarget, _ := filepath.Rel(worktreePath, it.Src)
+	ui.Info(fmt.Sprintf("  symlinked %s → %s", it.Dest, relTarget))
+	return 1, nil
+}
+
+// sameFile reports whether a and b are the same file on disk.
+func sameFile(a, b string) bool {
+	ai, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bi, err := os.Stat(b)
+	return err == nil && os.SameFile(ai, bi)
+}
@@ -0,0 +1,166 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"copy/**", "copy/a/b.txt", true},
+		{"copy/**", "copy", true},
+		{"copy/**", "symlink/a", false},
+		{"**/.env", ".env", true},
+		{"**/.env", "apps/web/.env", true},
+		{"**/.env", "apps/web/.env.local", false},
+		{"symlink/*", "symlink/node_modules", true},
+		{"symlink/*", "symlink/a/b", false},
+		{"apps/*/config/**", "apps/web/config/x.yml", true},
+		{"env/web.env", "env/web.env", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRuleDest(t *testing.T) {
+	tests := []struct {
+		rule    config.SharedRule
+		pattern string
+		rel     string
+		want    string
+	}{
+		{config.SharedRule{}, "copy/**", "copy/a/b.txt", "a/b.txt"},
+		{config.SharedRule{}, "symlink/*", "symlink/node_modules", "node_modules"},
+		{config.SharedRule{Dest: "apps/web/.env"}, "env/web.env", "env/web.env", "apps/web/.env"},
+		{config.SharedRule{}, "env/web.env", "env/web.env", "web.env"},
+		{config.SharedRule{Dest: "apps/web"}, "web/**", "web/config/x.yml", "apps/web/config/x.yml"},
+		{config.SharedRule{Dest: "third_party"}, "vendor", "vendor/lib/a.go", "third_party/lib/a.go"},
+	}
+	for _, tt := range tests {
+		if got := ruleDest(tt.rule, tt.pattern, tt.rel); got != filepath.FromSlash(tt.want) {
+			t.Errorf("ruleDest(%+v, %q, %q) = %q, want %q", tt.rule, tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "env", "web.env"), "BRANCH=${BRANCH_NAME}\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "app.ini"), "app\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "local.ini"), "local\n")
+	writeFile(t, filepath.Join(shared, "symlink", "node_modules", "x.js"), "x")
+	writeFile(t, filepath.Join(shared, "symlink", "CLAUDE.md"), "notes")
+	writeFile(t, filepath.Join(shared, "data", "seed.db"), "seed")
+	writeFile(t, filepath.Join(shared, "hooks", "setup.sh"), "#!/bin/sh\n")
+
+	cfg := &config.Config{
+		SharedDir: config.DefaultSharedDir,
+		Shared: config.Shared{Rules: []config.SharedRule{
+			{Include: []string{"env/web.env"}, Mode: config.RuleTemplate, Dest: "apps/web/.env"},
+			{Include: []string{"copy/**"}, Exclude: []string{"copy/conf/local.ini"}, Mode: config.RuleCopy},
+			{Include: []string{"symlink/*"}, Exclude: []string{"symlink/node_modules"}, Mode: config.RuleSymlink},
+			{Include: []string{"data/*.db"}, Mode: config.RuleHardlink, Dest: "db"},
+			{Include: []string{"**"}, Mode: config.RuleCopy, Dest: "everything"},
+		}},
+	}
+
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	vars := NewTemplateVars(root, wt, "main")
+	result, err := Apply(root, wt, cfg, false, &vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := os.ReadFile(filepath.Join(wt, "apps", "web", ".env")); string(got) != "BRANCH=main\n" {
+		t.Errorf("apps/web/.env = %q, want the rendered template", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, "conf", "app.ini")); string(got) != "app\n" {
+		t.Errorf("conf/app.ini = %q", got)
+	}
+	if info, err := os.Lstat(filepath.Join(wt, "CLAUDE.md")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("CLAUDE.md is not a symlink: %v", err)
+	}
+	if !sameFile(filepath.Join(wt, "db", "seed.db"), filepath.Join(shared, "data", "seed.db")) {
+		t.Error("db/seed.db is not hard-linked to the shared file")
+	}
+
+	// Excluded files fall through to the catch-all rule; wt's own
+	// directories are never placed.
+	if got, _ := os.ReadFile(filepath.Join(wt, "everything", "copy", "conf", "local.ini")); string(got) != "local\n" {
+		t.Errorf("everything/copy/conf/local.ini = %q, want the catch-all copy", got)
+	}
+	if _, err := os.Lstat(filepath.Join(wt, "node_modules")); !os.IsNotExist(err) {
+		t.Errorf("excluded node_modules was linked: %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(wt, "everything", "hooks")); !os.IsNotExist(err) {
+		t.Errorf("shared/hooks was placed: %v", err)
+	}
+	if result.Symlinked != 1 {
+		t.Errorf("Symlinked = %d, want 1", result.Symlinked)
+	}
+
+	vars = NewTemplateVars(root, wt, "main")
+	if drifts, err := CheckDrift(root, wt, cfg, &vars); err != nil || len(drifts) != 0 {
+		t.Errorf("CheckDrift() = %+v, %v; want no drift right after apply", drifts, err)
+	}
+
+	ex, err := Explain(root, cfg, vars, filepath.Join("apps", "web", ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.Applied != 0 || len(ex.Sources) != 1 || ex.Sources[0].Rule != 0 || ex.Sources[0].Mode != config.RuleTemplate {
+		t.Errorf("Explain(apps/web/.env) = %+v", ex)
+	}
+}
+
+func TestApplyRulesFirstMatchWins(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "conf", "app.ini"), "app\n")
+	writeFile(t, filepath.Join(shared, "conf", "nested", "db.ini"), "db\n")
+
+	// The first rule matches conf/ itself, so it covers the files below it
+	// even though the second rule matches them directly.
+	cfg := &config.Config{
+		SharedDir: config.DefaultSharedDir,
+		Shared: config.Shared{Rules: []config.SharedRule{
+			{Include: []string{"conf"}, Mode: config.RuleCopy, Dest: "settings"},
+			{Include: []string{"conf/**/*.ini"}, Mode: config.RuleSymlink},
+		}},
+	}
+
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rel := range []string{"settings/app.ini", "settings/nested/db.ini"} {
+		info, err := os.Lstat(filepath.Join(wt, rel))
+		if err != nil {
+			t.Errorf("%s was not placed: %v", rel, err)
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			t.Errorf("%s is a symlink; the first rule copies it", rel)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(wt, "app.ini")); !os.IsNotExist(err) {
+		t.Errorf("the second rule placed app.ini as well: %v", err)
+	}
+}
@@ -0,0 +1,292 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+// secretRef matches a ${secret:NAME} reference in a template.
+var secretRef = regexp.MustCompile(`\$\{secret:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Secrets resolves ${secret:NAME} references from a project's configured
+// sources. The sources are read on first use and then kept, and the values
+// rendered are remembered so output can be redacted. One Secrets serves one
+// apply pass, so a rotated secret is picked up by the next.
+type Secrets struct {
+	projectRoot string
+	cfg         config.Config
+
+	mu     sync.Mutex
+	loaded bool
+	values map[string]string
+	err    error
+	used   map[string]string // name -> value substituted into a template
+}
+
+// secretsTimeout bounds secrets_command and the age or gpg call, so one
+// that waits for input can't hang the apply. Tests shorten it.
+var secretsTimeout = 30 * time.Second
+
+// NewSecrets returns a secret resolver for the project, or nil when no
+// source is configured. Share it across the worktrees of one apply pass by
+// setting TemplateVars.Secrets, so the command runs or the file is
+// decrypted once.
+func NewSecrets(projectRoot string, cfg *config.Config) *Secrets {
+	if cfg == nil || !cfg.HasSecrets() {
+		return nil
+	}
+	return &Secrets{projectRoot: projectRoot, cfg: *cfg, used: make(map[string]string)}
+}
+
+// withSecrets returns vars with the project's secrets attached, leaving the
+// caller's copy alone.
+func withSecrets(projectRoot string, cfg *config.Config, vars *TemplateVars) *TemplateVars {
+	if vars == nil || vars.Secrets != nil {
+		return vars
+	}
+	v := *vars
+	v.Secrets = NewSecrets(projectRoot, cfg)
+	return &v
+}
+
+// Redact replaces every secret value rendered so far in text with its
+// ${secret:NAME} reference. It is safe to call on a nil Secrets.
+func (s *Secrets) Redact(text string) string {
+	if s == nil {
+		return text
+	}
+	s.mu.Lock()
+	used := maps.Clone(s.used)
+	s.mu.Unlock()
+
+	// Longest first, so a value containing another is replaced whole.
+	names := slices.SortedFunc(maps.Keys(used), func(a, b string) int { return len(used[b]) - len(used[a]) })
+	for _, name := range names {
+		if used[name] != "" {
+			text = strings.ReplaceAll(text, used[name], "${secret:"+name+"}")
+		}
+	}
+	return text
+}
+
+// substitute replaces the ${secret:NAME} references in content. Unknown
+// names are an error listing them, never their would-be values.
+func (s *Secrets) substitute(content string) (string, error) {
+	values, err := s.load()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missing []string
+	out := secretRef.ReplaceAllStringFunc(content, func(ref string) string {
+		name := secretRef.FindStringSubmatch(ref)[1]
+		value, ok := values[name]
+		if !ok {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return ref
+		}
+		s.used[name] = value
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unknown secret(s) %s: not defined by %s", strings.Join(missing, ", "), s.sourceNames())
+	}
+	return out, nil
+}
+
+func (s *Secrets) load() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loaded {
+		s.values, s.err = s.read()
+		s.loaded = true
+	}
+	return s.values, s.err
+}
+
+// read collects the values from every source. Earlier sources win, so they
+// are read last.
+func (s *Secrets) read() (map[string]string, error) {
+	values := make(map[string]string)
+	if s.cfg.SecretsDotenv != "" {
+		path := expandHome(s.cfg.SecretsDotenv)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.projectRoot, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("secrets_dotenv: %w", err)
+		}
+		maps.Copy(values, parseDotenv(data))
+	}
+	if s.cfg.SecretsFile != "" {
+		data, err := s.decrypt()
+		if err != nil {
+			return nil, fmt.Errorf("secrets_file: %w", err)
+		}
+		maps.Copy(values, parseDotenv(data))
+	}
+	if s.cfg.SecretsCommand != "" {
+		command, err := s.runCommand()
+		if err != nil {
+			return nil, fmt.Errorf("secrets_command: %w", err)
+		}
+		maps.Copy(values, command)
+	}
+	return values, nil
+}
+
+// decrypt returns the plaintext of secrets_file using the age or gpg CLI.
+func (s *Secrets) decrypt() ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	path := filepath.Join(s.projectRoot, s.cfg.SecretsFile)
+	var cmd *exec.Cmd
+	switch s.cfg.SecretsFileEncryption() {
+	case "age":
+		identity := s.cfg.SecretsIdentity
+		if identity == "" {
+			identity = "~/.config/age/keys.txt"
+		}
+		cmd = exec.CommandContext(ctx, "age", "--decrypt", "--identity", expandHome(identity), path)
+	default:
+		cmd = exec.CommandContext(ctx, "gpg", "--quiet", "--batch", "--decrypt", path)
+	}
+	return output(ctx, cmd)
+}
+
+// runCommand runs secrets_command in the project root and parses its
+// output: a JSON object of names to strings, numbers, or booleans.
+func (s *Secrets) runCommand() (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	argv := append(s.cfg.ShellFor(config.Hook{}), s.cfg.SecretsCommand)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = s.projectRoot
+	out, err := output(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(out, &raw); err != nil {
+		// The output holds secrets; don't echo it in the error.
+		return nil, fmt.Errorf("output is not a JSON object of names to values")
+	}
+	values := make(map[string]string, len(raw))
+	for name, v := range raw {
+		switch v := v.(type) {
+		case string:
+			values[name] = v
+		case float64, bool:
+			values[name] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("value of %s must be a string, number, or boolean", name)
+		}
+	}
+	return values, nil
+}
+
+// writeRendered writes a rendered template to dest with the template's
+// mode, or 0600 when the template references secrets, so other users can't
+// read them. An existing dest is narrowed before the secrets are written.
+func writeRendered(dest string, template, rendered []byte, mode fs.FileMode) error {
+	if secretRef.Match(template) {
+		mode = 0o600
+		if err := os.Chmod(dest, mode); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return os.WriteFile(dest, rendered, mode)
+}
+
+// sourceNames lists the configured sources for error messages.
+func (s *Secrets) sourceNames() string {
+	var names []string
+	if s.cfg.SecretsCommand != "" {
+		names = append(names, "secrets_command")
+	}
+	if s.cfg.SecretsFile != "" {
+		names = append(names, "secrets_file "+s.cfg.SecretsFile)
+	}
+	if s.cfg.SecretsDotenv != "" {
+		names = append(names, "secrets_dotenv "+s.cfg.SecretsDotenv)
+	}
+	return strings.Join(names, ", ")
+}
+
+// output runs cmd, which was made with ctx, and returns its stdout, with
+// stderr in the error when it fails. It never reads the user's stdin, so a
+// command that prompts fails rather than waiting.
+func output(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s: no result after %s", filepath.Base(cmd.Path), secretsTimeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", filepath.Base(cmd.Path), err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", filepath.Base(cmd.Path), err)
+	}
+	return out, nil
+}
+
+// parseDotenv reads KEY=VALUE lines, skipping blanks and comments. An
+// "export " prefix and matching surrounding quotes are dropped.
+func parseDotenv(data []byte) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	return values
+}
+
+// expandHome replaces a leading ~/ with the user's home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,154 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestParseDotenv(t *testing.T) {
+	got := parseDotenv([]byte("# comment\n\nexport A=1\nB = \"two words\"\nC='x=y'\nnot a pair\n"))
+	want := map[string]string{"A": "1", "B": "two words", "C": "x=y"}
+	if len(got) != len(want) {
+		t.Fatalf("parseDotenv() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestRenderTemplateSecrets(t *testing.T) {
+	root := t.TempDir()
+	dotenv := filepath.Join(t.TempDir(), "secrets.env")
+	writeFile(t, dotenv, "API_KEY=from-dotenv\nDB_PASSWORD=hunter2\n")
+	cfg := &config.Config{
+		SecretsDotenv:  dotenv,
+		SecretsCommand: `echo '{"API_KEY": "from-command", "PORT": 5432}'`,
+	}
+
+	vars := NewTemplateVars(root, filepath.Join(root, "worktrees", "main"), "main")
+	vars.Secrets = NewSecrets(root, cfg)
+	got, err := RenderTemplate("KEY=${secret:API_KEY}\nPW=${secret:DB_PASSWORD}\nPORT=${secret:PORT}\nB=${BRANCH_NAME}\n", vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The command wins over the dotenv file.
+	if want := "KEY=from-command\nPW=hunter2\nPORT=5432\nB=main\n"; got != want {
+		t.Errorf("RenderTemplate() = %q, want %q", got, want)
+	}
+
+	if got := vars.Secrets.Redact("PW=hunter2 KEY=from-command"); got != "PW=${secret:DB_PASSWORD} KEY=${secret:API_KEY}" {
+		t.Errorf("Redact() = %q", got)
+	}
+
+	_, err = RenderTemplate("X=${secret:MISSING}", vars)
+	if err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("RenderTemplate(unknown) error = %v, want it to name MISSING", err)
+	}
+
+	vars.Secrets = nil
+	if _, err := RenderTemplate("X=${secret:API_KEY}", vars); err == nil {
+		t.Error("RenderTemplate() with no sources succeeded, want an error")
+	}
+}
+
+func TestSecretsCommandErrorHidesOutput(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{SecretsCommand: "echo not-json-s3cret"}
+	vars := NewTemplateVars(root, root, "main")
+	vars.Secrets = NewSecrets(root, cfg)
+	_, err := RenderTemplate("${secret:X}", vars)
+	if err == nil || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("RenderTemplate() error = %v, want one without the command's output", err)
+	}
+}
+
+func TestApplySecretsRedactedInDiff(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "shared", "copy", ".env.template"), "TOKEN=${secret:TOKEN}\n")
+	dotenv := filepath.Join(t.TempDir(), "secrets.env")
+	writeFile(t, dotenv, "TOKEN=tok-123456\n")
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir, SecretsDotenv: dotenv}
+
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	vars := NewTemplateVars(root, wt, "main")
+	if _, err := Apply(root, wt, cfg, false, &vars); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, ".env")); string(got) != "TOKEN=tok-123456\n" {
+		t.Errorf(".env = %q, want the secret rendered", got)
+	}
+
+	writeFile(t, filepath.Join(wt, ".env"), "TOKEN=tok-123456\nEXTRA=1\n")
+	drifts, err := CheckDrift(root, wt, cfg, &vars)
+	if err != nil || len(drifts) != 1 {
+		t.Fatalf("CheckDrift() = %+v, %v", drifts, err)
+	}
+	diff := drifts[0].Diff(root, wt)
+	if strings.Contains(diff, "tok-123456") || !strings.Contains(diff, "${secret:TOKEN}") {
+		t.Errorf("Diff() shows the secret value:\n%s", diff)
+	}
+}
+
+func TestApplyRereadsRotatedSecrets(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "shared", "copy", ".env.template"), "TOKEN=${secret:TOKEN}\n")
+	if err := os.Chmod(filepath.Join(root, "shared", "copy", ".env.template"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dotenv := filepath.Join(t.TempDir(), "secrets.env")
+	writeFile(t, dotenv, "TOKEN=old\n")
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir, SecretsDotenv: dotenv, ApplyConflict: config.ConflictOverwrite}
+
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{"old", "new"} {
+		writeFile(t, dotenv, "TOKEN="+token+"\n")
+		vars := NewTemplateVars(root, wt, "main")
+		if _, err := Apply(root, wt, cfg, false, &vars); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := os.ReadFile(filepath.Join(wt, ".env")); string(got) != "TOKEN="+token+"\n" {
+			t.Errorf(".env = %q, want the current secret %s", got, token)
+		}
+	}
+
+	// A file holding secrets is readable by its owner only.
+	info, err := os.Stat(filepath.Join(wt, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf(".env mode = %o, want 600", perm)
+	}
+}
+
+func TestSecretsCommandTimeout(t *testing.T) {
+	orig := secretsTimeout
+	secretsTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { secretsTimeout = orig })
+
+	root := t.TempDir()
+	cfg := &config.Config{SecretsCommand: "sleep 10"}
+	vars := NewTemplateVars(root, root, "main")
+	vars.Secrets = NewSecrets(root, cfg)
+	start := time.Now()
+	_, err := RenderTemplate("${secret:X}", vars)
+	if err == nil || !strings.Contains(err.Error(), "no result after") {
+		t.Errorf("RenderTemplate() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RenderTemplate() took %s; want it to give up after the timeout", elapsed)
+	}
+}
@@ -27,7 +27,8 @@ const (
 	SetupSkipped  SetupStatus = "skipped"
 )
 
-// SetupState tracks the progress of setup hooks for a worktree.
+// SetupState tracks the progress of setup hooks for a worktree. Starting
+// the compose project counts as the first hook.
 type SetupState struct {
 	Status         SetupStatus `json:"status"`
 	PID            int         `json:"pid"`
@@ -0,0 +1,323 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/project/fscopy"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// ShareMode is how a shared file reaches the worktrees.
+type ShareMode string
+
+const (
+	ShareCopy     ShareMode = "copy"     // shared/copy/<path>
+	ShareSymlink  ShareMode = "symlink"  // shared/symlink/<path>
+	ShareTemplate ShareMode = "template" // shared/copy/<path>.template
+)
+
+// ErrNotShared is returned when no file under the shared dir produces a path.
+var ErrNotShared = errors.New("not a shared file")
+
+// shareRoot returns the shared subdirectory files for mode live in.
+func shareRoot(projectRoot string, cfg *config.Config, mode ShareMode) string {
+	if mode == ShareSymlink {
+		return filepath.Join(SharedPath(projectRoot, cfg), "symlink")
+	}
+	return filepath.Join(SharedPath(projectRoot, cfg), "copy")
+}
+
+// sharedSourcePath returns where the shared file producing rel lives for mode.
+func sharedSourcePath(projectRoot string, cfg *config.Config, rel string, mode ShareMode) string {
+	if mode == ShareTemplate {
+		rel += ".template"
+	}
+	return filepath.Join(shareRoot(projectRoot, cfg, mode), rel)
+}
+
+// FindSharedSource returns the file or directory under the shared dir that
+// apply places at rel in each worktree, and how it is placed.
+func FindSharedSource(projectRoot string, cfg *config.Config, rel string) (string, ShareMode, error) {
+	for _, mode := range []ShareMode{ShareTemplate, ShareCopy, ShareSymlink} {
+		path := sharedSourcePath(projectRoot, cfg, rel, mode)
+		if _, err := os.Lstat(path); err == nil {
+			return path, mode, nil
+		}
+	}
+	return "", "", fmt.Errorf("%s: %w", rel, ErrNotShared)
+}
+
+// ShareFile moves src, found at rel in a worktree, into the shared dir for
+// mode and returns its new path. Template mode turns the worktree's literal
+// paths and branch name into ${...} variables. The caller applies the
+// result to the worktrees.
+func ShareFile(projectRoot string, cfg *config.Config, src, rel string, mode ShareMode, vars TemplateVars, dryRun bool) (string, error) {
+	if existing, _, err := FindSharedSource(projectRoot, cfg, rel); err == nil {
+		return "", fmt.Errorf("%s is already shared from %s", rel, relOrAbs(projectRoot, existing))
+	}
+
+	info, err := os.Lstat(src)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("%s is a symlink; share the file it points to instead", rel)
+	}
+	if mode == ShareTemplate && !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s: only regular files can be shared as templates", rel)
+	}
+
+	dest := sharedSourcePath(projectRoot, cfg, rel, mode)
+	if dryRun {
+		ui.DryRunNotice(fmt.Sprintf("move %s -> %s", src, dest))
+		return dest, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", err
+	}
+
+	if mode != ShareTemplate {
+		if err := movePath(src, dest); err != nil {
+			return "", err
+		}
+		ui.Info(fmt.Sprintf("  moved %s to %s", rel, relOrAbs(projectRoot, dest)))
+		return dest, nil
+	}
+
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	templated, n := Templatize(string(content), vars)
+	if err := os.WriteFile(dest, []byte(templated), info.Mode().Perm()); err != nil {
+		return "", err
+	}
+	if err := os.Remove(src); err != nil {
+		return "", err
+	}
+	ui.Info(fmt.Sprintf("  moved %s to %s (%d value(s) replaced with variables)", rel, relOrAbs(projectRoot, dest), n))
+	return dest, nil
+}
+
+// UnshareFile stops sharing the file apply places at rel. The current
+// worktree keeps a real copy: its symlink is replaced by the shared file, or
+// its existing copy is left as is. In the other worktrees, symlinks to the
+// shared file and unmodified copies are removed, unless keep is set, in
+// which case each keeps a copy of its own. The file is then deleted from
+// the shared dir.
+func UnshareFile(projectRoot string, cfg *config.Config, rel string, current HookTarget, others []HookTarget, keep, dryRun bool) error {
+	source, mode, err := FindSharedSource(projectRoot, cfg, rel)
+	if err != nil {
+		return err
+	}
+
+	secrets := NewSecrets(projectRoot, cfg)
+	for _, t := range others {
+		if err := unshareFrom(cfg, secrets, t, rel, source, mode, keep, dryRun); err != nil {
+			return fmt.Errorf("%s: %w", t.Branch, err)
+		}
+	}
+
+	path := filepath.Join(current.WorktreePath, rel)
+	if dryRun {
+		ui.DryRunNotice(fmt.Sprintf("move %s -> %s", source, path))
+		return nil
+	}
+
+	switch {
+	case mode == ShareSymlink && linksTo(path, source):
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		if err := movePath(source, path); err != nil {
+			return err
+		}
+	case exists(path):
+		if err := os.RemoveAll(source); err != nil {
+			return err
+		}
+	default:
+		if err := placeCopy(cfg, secrets, source, path, mode, current); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(source); err != nil {
+			return err
+		}
+	}
+	removeEmptyParents(filepath.Dir(source), shareRoot(projectRoot, cfg, mode))
+	ui.Info(fmt.Sprintf("  moved %s back into %s", relOrAbs(projectRoot, source), current.Branch))
+
+	return forgetManifestEntries(current.WorktreePath, rel)
+}
+
+// unshareFrom handles one of the other worktrees for UnshareFile, before the
+// shared file is removed.
+func unshareFrom(cfg *config.Config, secrets *Secrets, t HookTarget, rel, source string, mode ShareMode, keep, dryRun bool) error {
+	path := filepath.Join(t.WorktreePath, rel)
+	if !exists(path) {
+		return nil
+	}
+
+	isLink := mode == ShareSymlink && linksTo(path, source)
+	switch {
+	case keep && isLink:
+		if dryRun {
+			ui.DryRunNotice(fmt.Sprintf("replace symlink %s with a copy", path))
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		if err := copyPath(source, path); err != nil {
+			return err
+		}
+		ui.Info(fmt.Sprintf("  kept a copy of %s in %s", rel, t.Branch))
+	case keep:
+		// Copies already belong to the worktree.
+	case isLink || matchesSource(cfg, secrets, path, source, mode, t):
+		if dryRun {
+			ui.DryRunNotice("remove " + path)
+			return nil
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		removeEmptyParents(filepath.Dir(path), t.WorktreePath)
+		ui.Info(fmt.Sprintf("  removed %s from %s", rel, t.Branch))
+	default:
+		ui.Warning(fmt.Sprintf("Keeping %s in %s: changed locally", rel, t.Branch))
+	}
+
+	if dryRun {
+		return nil
+	}
+	return forgetManifestEntries(t.WorktreePath, rel)
+}
+
+// matchesSource reports whether the regular file at path is exactly what
+// apply would write from source. Directories never match, so their copies
+// are kept.
+func matchesSource(cfg *config.Config, secrets *Secrets, path, source string, mode ShareMode, t HookTarget) bool {
+	if mode == ShareSymlink {
+		return false
+	}
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	want, err := os.ReadFile(source)
+	if err != nil {
+		return false
+	}
+	if mode == ShareTemplate {
+		rendered, err := RenderTemplate(string(want), targetVars(cfg, t, secrets))
+		if err != nil {
+			return false
+		}
+		want = []byte(rendered)
+	}
+	have, err := os.ReadFile(path)
+	return err == nil && bytes.Equal(have, want)
+}
+
+// placeCopy writes the worktree's own copy of a shared source at path.
+func placeCopy(cfg *config.Config, secrets *Secrets, source, path string, mode ShareMode, t HookTarget) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if mode != ShareTemplate {
+		return copyPath(source, path)
+	}
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	rendered, err := RenderTemplate(string(content), targetVars(cfg, t, secrets))
+	if err != nil {
+		return err
+	}
+	return writeRendered(path, content, []byte(rendered), info.Mode().Perm())
+}
+
+// targetVars returns the template variables for rendering into t, with the
+// secrets of the current pass.
+func targetVars(cfg *config.Config, t HookTarget, secrets *Secrets) TemplateVars {
+	vars := NewTemplateVars(t.ProjectRoot, t.WorktreePath, t.Branch)
+	vars.Secrets = secrets
+	return *withCompose(cfg, &vars)
+}
+
+// forgetManifestEntries drops rel, and anything under it, from the
+// worktree's manifest once the file is no longer shared.
+func forgetManifestEntries(worktreePath, rel string) error {
+	m, err := ReadManifest(worktreePath)
+	if err != nil || m == nil {
+		return err
+	}
+	n := len(m.Entries)
+	m.Entries = slices.DeleteFunc(m.Entries, func(e ManifestEntry) bool {
+		return e.Path == rel || strings.HasPrefix(e.Path, rel+string(filepath.Separator))
+	})
+	if len(m.Entries) == n {
+		return nil
+	}
+	return WriteManifest(worktreePath, m)
+}
+
+// linksTo reports whether path is a symlink resolving to target.
+func linksTo(path, target string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	a, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	b, err := os.Stat(target)
+	return err == nil && os.SameFile(a, b)
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// movePath renames src to dst, copying and deleting instead when they are
+// on different filesystems.
+func movePath(src, dst string) error {
+	err := os.Rename(src, dst)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := copyPath(src, dst); err != nil {
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+// copyPath copies a file or directory tree from src to dst.
+func copyPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		_, err := fscopy.CopyFile(src, dst, fscopy.Preserve)
+		return err
+	}
+	_, err = fscopy.CopyTree(src, dst, fscopy.Preserve)
+	return err
+}
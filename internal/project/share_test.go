@@ -0,0 +1,137 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestShareFile(t *testing.T) {
+	root := t.TempDir()
+	wt := filepath.Join(root, "worktrees", "feature-x")
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	vars := NewTemplateVars(root, wt, "feature/x")
+
+	writeFile(t, filepath.Join(wt, ".env"), "KEY=1\n")
+	dest, err := ShareFile(root, cfg, filepath.Join(wt, ".env"), ".env", ShareCopy, vars, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "shared", "copy", ".env"); dest != want {
+		t.Errorf("dest = %q, want %q", dest, want)
+	}
+	if _, err := os.Stat(filepath.Join(wt, ".env")); !os.IsNotExist(err) {
+		t.Errorf("source still present: %v", err)
+	}
+
+	writeFile(t, filepath.Join(wt, ".env"), "KEY=2\n")
+	if _, err := ShareFile(root, cfg, filepath.Join(wt, ".env"), ".env", ShareSymlink, vars, false); err == nil {
+		t.Error("sharing an already shared path succeeded")
+	}
+
+	writeFile(t, filepath.Join(wt, "conf", "app.ini"), "dir="+wt+"\nbranch=feature/x\n")
+	dest, err = ShareFile(root, cfg, filepath.Join(wt, "conf", "app.ini"), filepath.Join("conf", "app.ini"), ShareTemplate, vars, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(dest)
+	if want := "dir=${WORKTREE_PATH}\nbranch=${BRANCH_NAME}\n"; string(got) != want {
+		t.Errorf("template = %q, want %q", got, want)
+	}
+	if source, mode, err := FindSharedSource(root, cfg, filepath.Join("conf", "app.ini")); err != nil || source != dest || mode != ShareTemplate {
+		t.Errorf("FindSharedSource() = %q, %q, %v", source, mode, err)
+	}
+}
+
+func TestShareFileDryRun(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	writeFile(t, filepath.Join(wt, "a.txt"), "a")
+
+	if _, err := ShareFile(root, cfg, filepath.Join(wt, "a.txt"), "a.txt", ShareCopy, TemplateVars{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(wt, "a.txt")); err != nil {
+		t.Errorf("dry run moved the file: %v", err)
+	}
+	if _, _, err := FindSharedSource(root, cfg, "a.txt"); !errors.Is(err, ErrNotShared) {
+		t.Errorf("FindSharedSource() error = %v, want ErrNotShared", err)
+	}
+}
+
+func TestUnshareFile(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	targets := make([]HookTarget, 3)
+	for i, branch := range []string{"main", "clean", "edited"} {
+		targets[i] = HookTarget{ProjectRoot: root, WorktreePath: filepath.Join(root, "worktrees", branch), Branch: branch}
+	}
+	writeFile(t, filepath.Join(root, "shared", "copy", "sub", "a.txt"), "shared")
+	for _, tgt := range targets {
+		if _, err := Apply(root, tgt.WorktreePath, cfg, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	edited := filepath.Join(targets[2].WorktreePath, "sub", "a.txt")
+	writeFile(t, edited, "local")
+
+	rel := filepath.Join("sub", "a.txt")
+	if err := UnshareFile(root, cfg, rel, targets[0], targets[1:], false, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "shared", "copy", "sub")); !os.IsNotExist(err) {
+		t.Errorf("shared source dir still present: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "shared", "copy")); err != nil {
+		t.Errorf("shared/copy removed: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(targets[0].WorktreePath, rel)); string(got) != "shared" {
+		t.Errorf("current worktree has %q, want the shared content", got)
+	}
+	if _, err := os.Stat(filepath.Join(targets[1].WorktreePath, "sub")); !os.IsNotExist(err) {
+		t.Errorf("unchanged copy not removed: %v", err)
+	}
+	if got, _ := os.ReadFile(edited); string(got) != "local" {
+		t.Errorf("edited copy = %q, want it kept", got)
+	}
+	if m, _ := ReadManifest(targets[1].WorktreePath); m != nil && len(m.Entries) != 0 {
+		t.Errorf("manifest still lists %+v", m.Entries)
+	}
+}
+
+func TestUnshareFileKeepSymlinks(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	current := HookTarget{ProjectRoot: root, WorktreePath: filepath.Join(root, "worktrees", "main"), Branch: "main"}
+	other := HookTarget{ProjectRoot: root, WorktreePath: filepath.Join(root, "worktrees", "other"), Branch: "other"}
+	writeFile(t, filepath.Join(root, "shared", "symlink", "notes.md"), "notes")
+	for _, tgt := range []HookTarget{current, other} {
+		if err := os.MkdirAll(tgt.WorktreePath, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Apply(root, tgt.WorktreePath, cfg, false, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := UnshareFile(root, cfg, "notes.md", current, []HookTarget{other}, true, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, tgt := range []HookTarget{current, other} {
+		path := filepath.Join(tgt.WorktreePath, "notes.md")
+		info, err := os.Lstat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.Mode().IsRegular() {
+			t.Errorf("%s: mode %v, want a regular file", tgt.Branch, info.Mode())
+		}
+		if got, _ := os.ReadFile(path); string(got) != "notes" {
+			t.Errorf("%s: content %q", tgt.Branch, got)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// linkTarget returns what a symlink at link pointing to target holds:
+// target relative to the link's directory when relative is set, else
+// target itself.
+func linkTarget(target, link string, relative bool) string {
+	if !relative {
+		return target
+	}
+	if rel, err := filepath.Rel(filepath.Dir(link), target); err == nil {
+		return rel
+	}
+	return target
+}
+
+// RelinkSymlinks rewrites the shared symlinks in the worktree that have
+// absolute targets with relative ones, for projects switched to
+// symlink_style: relative. It covers the links apply plans for branch as
+// well as those in the manifest, so links written before the manifest
+// existed are fixed and recorded too. A link whose absolute target is the
+// shared file at a path the project has since moved from is pointed back at
+// the shared file. Links pointed outside the shared dir are left alone. It
+// returns how many links were rewritten, or would be with dryRun.
+func RelinkSymlinks(projectRoot, worktreePath string, cfg *config.Config, branch string, dryRun bool) (int, error) {
+	links, err := AbsoluteSymlinks(projectRoot, worktreePath, cfg, branch)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []ManifestEntry
+	for _, e := range links {
+		link := filepath.Join(worktreePath, e.Path)
+		rel := linkTarget(e.Target, link, true)
+		if dryRun {
+			ui.DryRunNotice(fmt.Sprintf("relink %s -> %s", e.Path, rel))
+			continue
+		}
+		tmp := link + ".wtrelink"
+		_ = os.Remove(tmp)
+		if err := os.Symlink(rel, tmp); err != nil {
+			return len(entries), err
+		}
+		if err := os.Rename(tmp, link); err != nil {
+			_ = os.Remove(tmp)
+			return len(entries), err
+		}
+		e.Target = rel
+		entries = append(entries, e)
+		ui.Info(fmt.Sprintf("  relinked %s → %s", e.Path, rel))
+	}
+
+	if dryRun || len(entries) == 0 {
+		return len(links), nil
+	}
+	return len(entries), updateManifest(worktreePath, entries)
+}
+
+// AbsoluteSymlinks returns the shared symlinks in the worktree that still
+// hold absolute targets, from the links apply plans for branch and those in
+// the manifest, with Target set to the shared file each should point at.
+func AbsoluteSymlinks(projectRoot, worktreePath string, cfg *config.Config, branch string) ([]ManifestEntry, error) {
+	planned, err := plannedSymlinks(projectRoot, worktreePath, cfg, branch)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ReadManifest(worktreePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedDir := SharedPath(projectRoot, cfg)
+	sep := string(filepath.Separator)
+	// moved reports whether target is the shared file for source at a path
+	// the project has since moved from.
+	moved := func(target, source string) bool {
+		return strings.HasSuffix(target, sep+filepath.Join(cfg.SharedDir, source))
+	}
+
+	seen := make(map[string]bool)
+	var absolute []ManifestEntry
+	check := func(e ManifestEntry, inShared bool) {
+		if seen[e.Path] {
+			return
+		}
+		seen[e.Path] = true
+		current, err := os.Readlink(filepath.Join(worktreePath, e.Path))
+		if err != nil || !filepath.IsAbs(current) {
+			return
+		}
+		want := filepath.Join(sharedDir, e.Source)
+		switch {
+		case current == want || moved(current, e.Source):
+			e.Target = want
+		case inShared && strings.HasPrefix(current, sharedDir+sep):
+			e.Target = current
+		default:
+			return
+		}
+		absolute = append(absolute, e)
+	}
+
+	for _, e := range planned {
+		check(e, true)
+	}
+	if m != nil {
+		for _, e := range m.Entries {
+			if e.Kind != ManifestSymlink || !filepath.IsAbs(e.Target) {
+				continue
+			}
+			// Only links apply wrote to the shared file itself; a symlink
+			// copied from shared/copy keeps the target its source has.
+			if e.Target != filepath.Join(sharedDir, e.Source) && !moved(e.Target, e.Source) {
+				continue
+			}
+			check(e, false)
+		}
+	}
+	slices.SortFunc(absolute, func(a, b ManifestEntry) int { return strings.Compare(a.Path, b.Path) })
+	return absolute, nil
+}
+
+// plannedSymlinks returns the links apply creates in the worktree for
+// branch, as entries whose Target is the shared file. A planned directory
+// the worktree holds as a real directory stands for the links apply merges
+// into it.
+func plannedSymlinks(projectRoot, worktreePath string, cfg *config.Config, branch string) ([]ManifestEntry, error) {
+	var planned []ManifestEntry
+	var add func(dest, src, source string) error
+	add = func(dest, src, source string) error {
+		if info, err := os.Lstat(src); err == nil && info.IsDir() {
+			if info, err := os.Lstat(filepath.Join(worktreePath, dest)); err == nil && info.IsDir() && info.Mode()&os.ModeSymlink == 0 {
+				entries, err := os.ReadDir(src)
+				if err != nil {
+					return err
+				}
+				for _, entry := range entries {
+					name := entry.Name()
+					if err := add(filepath.Join(dest, name), filepath.Join(src, name), filepath.Join(source, name)); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+		planned = append(planned, ManifestEntry{Path: dest, Source: source, Kind: ManifestSymlink, Target: src})
+		return nil
+	}
+
+	layers := Layers(projectRoot, cfg, branch)
+	if cfg.Shared.HasRules() {
+		plan, err := planRules(projectRoot, cfg, layers, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range plan.applied(true) {
+			if err := add(it.Dest, it.Src, it.Source); err != nil {
+				return nil, err
+			}
+		}
+		return planned, nil
+	}
+
+	plan, err := planSymlinks(layers)
+	if err != nil {
+		return nil, err
+	}
+	for name, i := range plan {
+		layer := layers[i]
+		if err := add(name, filepath.Join(layer.Dir, "symlink", name), layer.source("symlink", name)); err != nil {
+			return nil, err
+		}
+	}
+	return planned, nil
+}
+
+// DanglingSymlinks returns the shared symlinks in the worktree's manifest
+// whose target no longer exists, such as links to a file deleted from
+// shared/ or absolute links left behind when the project moved.
+func DanglingSymlinks(worktreePath string) ([]ManifestEntry, error) {
+	m, err := ReadManifest(worktreePath)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	var dangling []ManifestEntry
+	for _, e := range m.Entries {
+		if e.Kind != ManifestSymlink {
+			continue
+		}
+		link := filepath.Join(worktreePath, e.Path)
+		if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		if _, err := os.Stat(link); err != nil {
+			dangling = append(dangling, e)
+		}
+	}
+	return dangling, nil
+}
+
+// FixDanglingSymlinks removes the worktree's dangling shared symlinks whose
+// shared file is gone, and recreates the rest from shared/. It returns how
+// many dangling links were fixed, or would be with dryRun.
+func FixDanglingSymlinks(projectRoot, worktreePath string, cfg *config.Config, branch string, dryRun bool) (int, error) {
+	dangling, err := DanglingSymlinks(worktreePath)
+	if err != nil || len(dangling) == 0 {
+		return 0, err
+	}
+
+	sharedDir := SharedPath(projectRoot, cfg)
+	removed := make(map[string]bool)
+	var relink bool
+	for _, e := range dangling {
+		if _, err := os.Lstat(filepath.Join(sharedDir, e.Source)); err == nil {
+			relink = true
+			continue
+		}
+		link := filepath.Join(worktreePath, e.Path)
+		if dryRun {
+			ui.DryRunNotice("remove " + link)
+			continue
+		}
+		if err := os.Remove(link); err != nil {
+			return len(removed), err
+		}
+		removeEmptyParents(filepath.Dir(link), worktreePath)
+		ui.Info("  removed " + e.Path)
+		removed[e.Path] = true
+	}
+
+	if relink {
+		var rec *manifestRecorder
+		if !dryRun {
+			rec = &manifestRecorder{worktree: worktreePath}
+		}
+		if _, err := applySymlinks(projectRoot, worktreePath, cfg, branch, dryRun, rec); err != nil {
+			return len(removed), err
+		}
+		if rec != nil {
+			if err := updateManifest(worktreePath, rec.entries); err != nil {
+				return len(removed), err
+			}
+		}
+	}
+
+	if !dryRun && len(removed) > 0 {
+		m, err := ReadManifest(worktreePath)
+		if err != nil || m == nil {
+			return len(removed), err
+		}
+		m.Entries = slices.DeleteFunc(m.Entries, func(e ManifestEntry) bool { return removed[e.Path] })
+		if err := WriteManifest(worktreePath, m); err != nil {
+			return len(removed), err
+		}
+	}
+	return len(dangling), nil
+}
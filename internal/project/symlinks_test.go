@@ -0,0 +1,199 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestApplyRelativeSymlinks(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "shared", "symlink", "CLAUDE.md"), "notes")
+	writeFile(t, filepath.Join(root, "shared", "symlink", ".claude", "settings.json"), "{}")
+	wt := filepath.Join(root, "worktrees", "main")
+	writeFile(t, filepath.Join(wt, ".claude", "local.json"), "{}")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir, SymlinkStyle: config.SymlinkRelative}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	for rel, want := range map[string]string{
+		"CLAUDE.md":             "../../shared/symlink/CLAUDE.md",
+		".claude/settings.json": "../../../shared/symlink/.claude/settings.json",
+	} {
+		if got, err := os.Readlink(filepath.Join(wt, rel)); err != nil || got != want {
+			t.Errorf("%s -> %q, %v; want %q", rel, got, err, want)
+		}
+	}
+
+	// The links survive moving the whole project.
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(filepath.Join(moved, "worktrees", "main", "CLAUDE.md")); err != nil || string(got) != "notes" {
+		t.Errorf("CLAUDE.md after move = %q, %v", got, err)
+	}
+}
+
+func TestRelinkSymlinks(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	writeFile(t, filepath.Join(root, "shared", "symlink", "CLAUDE.md"), "notes")
+	writeFile(t, filepath.Join(root, "shared", "symlink", "edited.md"), "shared")
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	// A link the user has pointed elsewhere is left alone.
+	other := filepath.Join(t.TempDir(), "mine.md")
+	writeFile(t, other, "mine")
+	if err := os.Remove(filepath.Join(wt, "edited.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(other, filepath.Join(wt, "edited.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the project, so the absolute links dangle, then repair them.
+	moved := filepath.Join(filepath.Dir(root), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatal(err)
+	}
+	wt = filepath.Join(moved, "worktrees", "main")
+	cfg.SymlinkStyle = config.SymlinkRelative
+
+	if n, err := RelinkSymlinks(moved, wt, cfg, "main", true); err != nil || n != 1 {
+		t.Fatalf("RelinkSymlinks(dry run) = %d, %v; want 1", n, err)
+	}
+	if got, _ := os.Readlink(filepath.Join(wt, "CLAUDE.md")); !filepath.IsAbs(got) {
+		t.Errorf("dry run rewrote CLAUDE.md to %q", got)
+	}
+
+	n, err := RelinkSymlinks(moved, wt, cfg, "main", false)
+	if err != nil || n != 1 {
+		t.Fatalf("RelinkSymlinks() = %d, %v; want 1", n, err)
+	}
+	if got, err := os.ReadFile(filepath.Join(wt, "CLAUDE.md")); err != nil || string(got) != "notes" {
+		t.Errorf("CLAUDE.md = %q, %v", got, err)
+	}
+	if got, _ := os.Readlink(filepath.Join(wt, "edited.md")); got != other {
+		t.Errorf("edited.md -> %q, want it left at %q", got, other)
+	}
+	if stale, err := StaleEntries(moved, wt, cfg); err != nil || len(stale) != 0 {
+		t.Errorf("StaleEntries() = %v, %v; want the manifest updated", stale, err)
+	}
+	if n, err := RelinkSymlinks(moved, wt, cfg, "main", false); err != nil || n != 0 {
+		t.Errorf("second RelinkSymlinks() = %d, %v; want 0", n, err)
+	}
+}
+
+func TestRelinkSymlinksFromPlan(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "symlink", "CLAUDE.md"), "notes")
+	writeFile(t, filepath.Join(shared, "symlink", ".claude", "settings.json"), "{}")
+	writeFile(t, filepath.Join(shared, "agents", "AGENTS.md"), "agents")
+	wt := filepath.Join(root, "worktrees", "main")
+	writeFile(t, filepath.Join(wt, ".claude", "local.json"), "{}")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Links from before the manifest existed, and one from a rule.
+	if err := os.Remove(ManifestPath(wt)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(shared, "agents", "AGENTS.md"), filepath.Join(wt, "AGENTS.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SymlinkStyle = config.SymlinkRelative
+	cfg.Shared = config.Shared{Rules: []config.SharedRule{
+		{Include: []string{"symlink/*"}, Mode: config.RuleSymlink},
+		{Include: []string{"agents/*"}, Mode: config.RuleSymlink},
+	}}
+	if abs, err := AbsoluteSymlinks(root, wt, cfg, "main"); err != nil || len(abs) != 3 {
+		t.Fatalf("AbsoluteSymlinks = %+v, %v; want 3 links", abs, err)
+	}
+	n, err := RelinkSymlinks(root, wt, cfg, "main", false)
+	if err != nil || n != 3 {
+		t.Fatalf("RelinkSymlinks() = %d, %v; want 3", n, err)
+	}
+	for rel, want := range map[string]string{
+		"AGENTS.md":             "../../shared/agents/AGENTS.md",
+		"CLAUDE.md":             "../../shared/symlink/CLAUDE.md",
+		".claude/settings.json": "../../../shared/symlink/.claude/settings.json",
+	} {
+		if got, err := os.Readlink(filepath.Join(wt, rel)); err != nil || got != want {
+			t.Errorf("%s -> %q, %v; want %q", rel, got, err, want)
+		}
+	}
+
+	m, err := ReadManifest(wt)
+	if err != nil || m == nil || len(m.Entries) != 3 {
+		t.Fatalf("manifest = %+v, %v; want the 3 relinked links", m, err)
+	}
+	if e := m.Entries[0]; e.Path != filepath.Join(".claude", "settings.json") || e.Source != filepath.Join("symlink", ".claude", "settings.json") {
+		t.Errorf("first entry = %+v, want the link merged into .claude/", e)
+	}
+}
+
+func TestFixDanglingSymlinks(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	writeFile(t, filepath.Join(root, "shared", "symlink", "CLAUDE.md"), "notes")
+	writeFile(t, filepath.Join(root, "shared", "symlink", "gone.md"), "old")
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if abs, err := AbsoluteSymlinks(root, wt, cfg, "main"); err != nil || len(abs) != 2 {
+		t.Fatalf("AbsoluteSymlinks = %v, %v; want both links", abs, err)
+	}
+
+	// Moving the project leaves the absolute links dangling, and one shared
+	// file is deleted outright.
+	moved := filepath.Join(t.TempDir(), "moved")
+	if err := os.Rename(root, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(moved, "shared", "symlink", "gone.md")); err != nil {
+		t.Fatal(err)
+	}
+	wt = filepath.Join(moved, "worktrees", "main")
+
+	dangling, err := DanglingSymlinks(wt)
+	if err != nil || len(dangling) != 2 {
+		t.Fatalf("DanglingSymlinks = %v, %v; want 2", dangling, err)
+	}
+	n, err := FixDanglingSymlinks(moved, wt, cfg, "main", false)
+	if err != nil || n != 2 {
+		t.Fatalf("FixDanglingSymlinks = %d, %v; want 2", n, err)
+	}
+
+	if got, err := os.ReadFile(filepath.Join(wt, "CLAUDE.md")); err != nil || string(got) != "notes" {
+		t.Errorf("CLAUDE.md = %q, %v; want it relinked", got, err)
+	}
+	if _, err := os.Lstat(filepath.Join(wt, "gone.md")); !os.IsNotExist(err) {
+		t.Errorf("gone.md still present: %v", err)
+	}
+	if dangling, _ := DanglingSymlinks(wt); len(dangling) != 0 {
+		t.Errorf("still dangling after fix: %v", dangling)
+	}
+	m, err := ReadManifest(wt)
+	if err != nil || len(m.Entries) != 1 || m.Entries[0].Path != "CLAUDE.md" {
+		t.Errorf("manifest = %+v, %v; want only CLAUDE.md", m, err)
+	}
+}
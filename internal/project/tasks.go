@@ -0,0 +1,75 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+// HookTask is the WT_HOOK value for processes started by 'wt run'.
+const HookTask HookPoint = "task"
+
+// LookupTask returns the task called name, or an error listing the tasks
+// that are configured.
+func LookupTask(cfg *config.Config, name string) (config.Hook, error) {
+	if h, ok := cfg.Tasks[name]; ok {
+		return h, nil
+	}
+	if len(cfg.Tasks) == 0 {
+		return config.Hook{}, fmt.Errorf("unknown task %q: no tasks defined in %s", name, config.ConfigFileName)
+	}
+	return config.Hook{}, fmt.Errorf("unknown task %q (available: %s)", name, strings.Join(cfg.TaskNames(), ", "))
+}
+
+// RunTask runs the named task in the target worktree. The task is attached
+// to the user's terminal, so it needs no pseudo-terminal and its exit
+// status is returned as-is.
+func RunTask(ctx context.Context, cfg *config.Config, name string, target HookTarget, dryRun bool) error {
+	h, err := LookupTask(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		ui.DryRunNotice("exec: " + describeHook(cfg, target.ProjectRoot, h))
+		return nil
+	}
+
+	cmd, err := hookCommand(ctx, cfg, HookTask, target, h)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RunTaskParallel runs the named task in every target concurrently, with
+// output labeled by branch. All runs complete even if some fail.
+func RunTaskParallel(ctx context.Context, cfg *config.Config, name string, targets []HookTarget, dryRun bool) error {
+	h, err := LookupTask(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, t := range targets {
+			ui.DryRunNotice("exec (" + t.Branch + "): " + describeHook(cfg, t.ProjectRoot, h))
+		}
+		return nil
+	}
+
+	jobs := make([]hookJob, len(targets))
+	for i, t := range targets {
+		jobs[i] = hookJob{name: t.Branch, hook: h, target: t}
+	}
+	if failCount := runHookJobs(ctx, cfg, HookTask, jobs); failCount > 0 {
+		return fmt.Errorf("task %s failed in %d of %d worktree(s)", name, failCount, len(targets))
+	}
+	return nil
+}
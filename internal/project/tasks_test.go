@@ -0,0 +1,79 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+)
+
+func TestRunTaskEnvironment(t *testing.T) {
+	root, wt := t.TempDir(), t.TempDir()
+	cfg := &config.Config{Tasks: map[string]config.Hook{
+		"env": {Run: `printf '%s|%s' "$WT_HOOK" "$WT_BRANCH" > env.txt`},
+	}}
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt, Branch: "feature/x"}
+
+	if err := RunTask(context.Background(), cfg, "env", target, false); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(wt, "env.txt"))
+	if err != nil {
+		t.Fatalf("task should run in the worktree: %v", err)
+	}
+	if string(got) != "task|feature/x" {
+		t.Errorf("env = %q, want %q", got, "task|feature/x")
+	}
+}
+
+func TestRunTaskFailure(t *testing.T) {
+	cfg := &config.Config{Tasks: map[string]config.Hook{"fail": {Run: "exit 3"}}}
+	if err := RunTask(context.Background(), cfg, "fail", HookTarget{WorktreePath: t.TempDir()}, false); err == nil {
+		t.Error("expected error from failing task")
+	}
+}
+
+func TestRunTaskUnknown(t *testing.T) {
+	cfg := &config.Config{Tasks: map[string]config.Hook{"test": {Run: "true"}, "dev": {Run: "true"}}}
+	err := RunTask(context.Background(), cfg, "deploy", HookTarget{WorktreePath: t.TempDir()}, false)
+	if err == nil || !strings.Contains(err.Error(), "available: dev, test") {
+		t.Errorf("err = %v, want the available tasks listed", err)
+	}
+
+	err = RunTask(context.Background(), &config.Config{}, "deploy", HookTarget{}, false)
+	if err == nil || !strings.Contains(err.Error(), "no tasks defined") {
+		t.Errorf("err = %v, want no tasks defined", err)
+	}
+}
+
+func TestRunTaskParallel(t *testing.T) {
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	cfg := &config.Config{Tasks: map[string]config.Hook{
+		"check": {Run: `echo "checking $WT_BRANCH"; test "$WT_BRANCH" != broken`},
+	}}
+	targets := []HookTarget{
+		{WorktreePath: t.TempDir(), Branch: "main"},
+		{WorktreePath: t.TempDir(), Branch: "broken"},
+	}
+
+	err := RunTaskParallel(context.Background(), cfg, "check", targets, false)
+	if err == nil || !strings.Contains(err.Error(), "failed in 1 of 2") {
+		t.Errorf("err = %v, want one failure", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"[main] checking main", "[main] Completed", "[broken] Failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
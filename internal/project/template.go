@@ -1,7 +1,9 @@
 package project
 
 import (
+	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -10,6 +12,14 @@ type TemplateVars struct {
 	WorktreeID   string
 	WorktreePath string
 	BranchName   string
+
+	// ComposeProject is ${COMPOSE_PROJECT_NAME}; empty when the project has
+	// no compose block.
+	ComposeProject string
+
+	// Secrets resolves ${secret:NAME}; nil when the project has no secret
+	// sources.
+	Secrets *Secrets
 }
 
 func NewTemplateVars(projectRoot, worktreePath, branchName string) TemplateVars {
@@ -30,9 +40,73 @@ func ProcessTemplate(content string, vars TemplateVars) string {
 	s = strings.ReplaceAll(s, "${WORKTREE_ID}", vars.WorktreeID)
 	s = strings.ReplaceAll(s, "${WORKTREE_PATH}", vars.WorktreePath)
 	s = strings.ReplaceAll(s, "${BRANCH_NAME}", vars.BranchName)
+	if vars.ComposeProject != "" {
+		s = strings.ReplaceAll(s, "${COMPOSE_PROJECT_NAME}", vars.ComposeProject)
+	}
 	return s
 }
 
+// RenderTemplate is ProcessTemplate for shared files, which may also
+// reference ${secret:NAME}.
+func RenderTemplate(content string, vars TemplateVars) (string, error) {
+	s := ProcessTemplate(content, vars)
+	if !secretRef.MatchString(s) {
+		return s, nil
+	}
+	if vars.Secrets == nil {
+		name := secretRef.FindStringSubmatch(s)[1]
+		return "", fmt.Errorf("${secret:%s} is used but no secrets_command, secrets_file, or secrets_dotenv is configured", name)
+	}
+	return vars.Secrets.substitute(s)
+}
+
+// Templatize is the inverse of ProcessTemplate: it replaces each literal
+// value from vars in content with its ${...} variable and returns the result
+// and the number of replacements. A value is only replaced where it stands
+// on its own, not touching a letter, digit, '_' or '-', so branch main
+// leaves "maintainer" alone. Longer values win where they overlap, so a
+// worktree path is not rewritten as ${PROJECT_ROOT}/worktrees/...
+func Templatize(content string, vars TemplateVars) (string, int) {
+	pairs := []struct{ value, name string }{
+		{vars.WorktreePath, "${WORKTREE_PATH}"},
+		{vars.ProjectRoot, "${PROJECT_ROOT}"},
+		{vars.BranchName, "${BRANCH_NAME}"},
+		{vars.WorktreeID, "${WORKTREE_ID}"},
+		{vars.ComposeProject, "${COMPOSE_PROJECT_NAME}"},
+	}
+	slices.SortStableFunc(pairs, func(a, b struct{ value, name string }) int { return len(b.value) - len(a.value) })
+
+	var b strings.Builder
+	count := 0
+	for i := 0; i < len(content); {
+		matched := false
+		if i == 0 || !isNameByte(content[i-1]) {
+			for _, p := range pairs {
+				end := i + len(p.value)
+				if p.value == "" || !strings.HasPrefix(content[i:], p.value) || (end < len(content) && isNameByte(content[end])) {
+					continue
+				}
+				b.WriteString(p.name)
+				i = end
+				count++
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(content[i])
+			i++
+		}
+	}
+	return b.String(), count
+}
+
+// isNameByte reports whether c can be part of a name, so a value next to it
+// is part of a longer word rather than standing on its own.
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
 func IsTemplateFile(filename string) bool {
 	return strings.HasSuffix(filename, ".template")
 }
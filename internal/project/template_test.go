@@ -67,6 +67,67 @@ func TestProcessTemplate(t *testing.T) {
 	}
 }
 
+func TestTemplatize(t *testing.T) {
+	vars := TemplateVars{
+		ProjectRoot:  "/project",
+		WorktreeID:   "feature-login",
+		WorktreePath: "/project/worktrees/feature-login",
+		BranchName:   "feature/login",
+	}
+
+	tests := []struct {
+		input string
+		want  string
+		count int
+	}{
+		{"path: /project/worktrees/feature-login/tmp", "path: ${WORKTREE_PATH}/tmp", 1},
+		{"bare: /project/.bare", "bare: ${PROJECT_ROOT}/.bare", 1},
+		{"branch: feature/login, db: feature-login.db", "branch: ${BRANCH_NAME}, db: ${WORKTREE_ID}.db", 2},
+		{"origin/feature/login", "origin/${BRANCH_NAME}", 1},
+		{"db: feature-login_db, app-feature-login", "db: feature-login_db, app-feature-login", 0},
+		{"no literals", "no literals", 0},
+	}
+	for _, tt := range tests {
+		got, count := Templatize(tt.input, vars)
+		if got != tt.want || count != tt.count {
+			t.Errorf("Templatize(%q) = %q, %d; want %q, %d", tt.input, got, count, tt.want, tt.count)
+		}
+		if back := ProcessTemplate(got, vars); back != tt.input {
+			t.Errorf("ProcessTemplate(Templatize(%q)) = %q, want the input back", tt.input, back)
+		}
+	}
+}
+
+func TestTemplatizeShortValues(t *testing.T) {
+	tests := []struct {
+		branch string
+		input  string
+		want   string
+		count  int
+	}{
+		{"main", "maintainer=bob\nremain=1\nbranch=main\n", "maintainer=bob\nremain=1\nbranch=${BRANCH_NAME}\n", 1},
+		{"main", "ref: refs/heads/main, domain", "ref: refs/heads/${BRANCH_NAME}, domain", 1},
+		{"dev", "NODE_ENV=development\nDEV=true\ndevtools: dev", "NODE_ENV=development\nDEV=true\ndevtools: ${BRANCH_NAME}", 1},
+		{"dev", "dev_db, dev-server, mydev", "dev_db, dev-server, mydev", 0},
+		{"1", "PORT=3001\nWORKERS=1\n", "PORT=3001\nWORKERS=${BRANCH_NAME}\n", 1},
+	}
+	for _, tt := range tests {
+		vars := NewTemplateVars("/project", "/project/worktrees/"+tt.branch, tt.branch)
+		vars.ComposeProject = "project-" + tt.branch
+		got, count := Templatize(tt.input, vars)
+		if got != tt.want || count != tt.count {
+			t.Errorf("Templatize(%q) on branch %s = %q, %d; want %q, %d", tt.input, tt.branch, got, count, tt.want, tt.count)
+		}
+	}
+
+	// A short compose project name is held to the same boundaries.
+	vars := NewTemplateVars("/project", "/project/worktrees/x", "x")
+	vars.ComposeProject = "app"
+	if got, _ := Templatize("name: app\napplication: apps", vars); got != "name: ${COMPOSE_PROJECT_NAME}\napplication: apps" {
+		t.Errorf("Templatize with compose project app = %q", got)
+	}
+}
+
 func TestIsTemplateFile(t *testing.T) {
 	tests := []struct {
 		filename string
@@ -0,0 +1,249 @@
+package project
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/disk"
+)
+
+// WorktreeUsage is the disk space one worktree takes, split by where the
+// files came from. Sizes are allocated bytes.
+type WorktreeUsage struct {
+	Branch string `json:"branch"`
+	Path   string `json:"path"`
+
+	Tracked uint64 `json:"tracked_bytes"` // files git tracks
+	Ignored uint64 `json:"ignored_bytes"` // untracked and ignored files: build output, dependencies, caches
+	Copied  uint64 `json:"copied_bytes"`  // files apply copied from shared/
+	Total   uint64 `json:"total_bytes"`   // Tracked + Ignored + Copied
+
+	// Shared is the part of Total in blocks shared with other files:
+	// reflinked extents and hard-linked files. Removing the worktree doesn't
+	// free it.
+	Shared uint64 `json:"shared_bytes"`
+	// Reclaimable is roughly what removing the worktree would free: Total
+	// less Shared.
+	Reclaimable uint64 `json:"reclaimable_bytes"`
+	// Symlinked is the size of the shared files the worktree links to. It
+	// lives in shared/, so it isn't part of Total.
+	Symlinked uint64 `json:"symlinked_bytes"`
+
+	Files int `json:"files"`
+
+	Merged  bool `json:"merged"`
+	Dirty   bool `json:"dirty"`
+	Default bool `json:"default"`
+}
+
+// MeasureWorktree walks the worktree and sizes its files. tracked lists the
+// files git tracks, slash-separated as git ls-files prints them; the
+// manifest tells copied shared files and symlinks apart from the rest. The
+// branch and status fields are left for the caller.
+func MeasureWorktree(worktreePath string, tracked []string) (WorktreeUsage, error) {
+	u := WorktreeUsage{Path: worktreePath}
+
+	m, err := ReadManifest(worktreePath)
+	if err != nil {
+		return u, err
+	}
+	manifest := make(map[string]ManifestKind)
+	if m != nil {
+		for _, e := range m.Entries {
+			manifest[e.Path] = e.Kind
+		}
+	}
+	isTracked := make(map[string]bool, len(tracked))
+	for _, f := range tracked {
+		isTracked[filepath.FromSlash(f)] = true
+	}
+
+	seen := make(map[[2]uint64]bool)
+	err = filepath.WalkDir(worktreePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A file removed mid-walk, e.g. by a running build, isn't an error.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(worktreePath, path)
+		if err != nil {
+			return err
+		}
+		if rel == ".git" {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			if manifest[rel] == ManifestSymlink {
+				u.Symlinked += targetSize(path)
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		a := disk.Allocated(path, info)
+		if a.Links > 1 {
+			key := [2]uint64{a.Dev, a.Inode}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+			a.Shared = a.Bytes
+		}
+		u.Files++
+		u.Total += a.Bytes
+		u.Shared += a.Shared
+		switch {
+		case manifest[rel] == ManifestCopy:
+			u.Copied += a.Bytes
+		case isTracked[rel]:
+			u.Tracked += a.Bytes
+		default:
+			u.Ignored += a.Bytes
+		}
+		return nil
+	})
+	u.Reclaimable = u.Total - min(u.Shared, u.Total)
+	return u, err
+}
+
+// targetSize returns the allocated size of what the symlink at path points
+// to, walking it when it is a directory. A dangling link has size 0.
+func targetSize(path string) uint64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	if info.Mode().IsRegular() {
+		return disk.Blocks(info).Bytes
+	}
+	if !info.IsDir() {
+		return 0
+	}
+	return treeSize(path)
+}
+
+// WorktreeSize sizes the worktree's files like MeasureWorktree, but fills in
+// only Path, Total, Copied and Files. It neither asks git which files it
+// tracks nor queries extents, so it is cheap enough to run before each new
+// worktree.
+func WorktreeSize(worktreePath string) (WorktreeUsage, error) {
+	u := WorktreeUsage{Path: worktreePath}
+
+	m, err := ReadManifest(worktreePath)
+	if err != nil {
+		return u, err
+	}
+	copied := make(map[string]bool)
+	if m != nil {
+		for _, e := range m.Entries {
+			copied[e.Path] = e.Kind == ManifestCopy
+		}
+	}
+
+	seen := make(map[[2]uint64]bool)
+	err = filepath.WalkDir(worktreePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(worktreePath, path)
+		if err != nil {
+			return err
+		}
+		if rel == ".git" && d.IsDir() {
+			return filepath.SkipDir
+		}
+		if rel == ".git" || !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		a := disk.Blocks(info)
+		if a.Links > 1 {
+			key := [2]uint64{a.Dev, a.Inode}
+			if seen[key] {
+				return nil
+			}
+			seen[key] = true
+		}
+		u.Files++
+		u.Total += a.Bytes
+		if copied[rel] {
+			u.Copied += a.Bytes
+		}
+		return nil
+	})
+	return u, err
+}
+
+// SharedCopySize returns the allocated size of what apply copies into a new
+// worktree of branch: the copy/ files of the shared dir and of the overlays
+// branch selects, or the files the shared rules copy or render. Hard-linked
+// files take no space of their own and are left out. It is 0 when the plan
+// can't be read.
+func SharedCopySize(projectRoot string, cfg *config.Config, branch string) uint64 {
+	layers := Layers(projectRoot, cfg, branch)
+	var size uint64
+	if cfg.Shared.HasRules() {
+		plan, err := planRules(projectRoot, cfg, layers, nil)
+		if err != nil {
+			return 0
+		}
+		for _, it := range plan.applied(false) {
+			if it.Mode != config.RuleHardlink {
+				size += targetSize(it.Src)
+			}
+		}
+		return size
+	}
+
+	plan, err := planCopies(layers, nil)
+	if err != nil {
+		return 0
+	}
+	for rel, i := range plan {
+		size += targetSize(filepath.Join(layers[i].Dir, "copy", rel))
+	}
+	return size
+}
+
+// treeSize sums the allocated size of the regular files under dir, skipping
+// what can't be read.
+func treeSize(dir string) uint64 {
+	var size uint64
+	_ = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil // size what can be read
+		}
+		if info, err := d.Info(); err == nil {
+			size += disk.Blocks(info).Bytes
+		}
+		return nil
+	})
+	return size
+}
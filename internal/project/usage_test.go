@@ -0,0 +1,103 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestMeasureWorktree(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "shared", "copy", ".env"), "SECRET=1\n")
+	writeFile(t, filepath.Join(root, "shared", "symlink", "CLAUDE.md"), "notes")
+	wt := filepath.Join(root, "worktrees", "main")
+	writeFile(t, filepath.Join(wt, "main.go"), "package main\n")
+	writeFile(t, filepath.Join(wt, "node_modules", "dep", "index.js"), "module.exports = 1\n")
+	writeFile(t, filepath.Join(wt, ".git"), "gitdir: ../../.bare/worktrees/main\n")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	if _, err := Apply(root, wt, cfg, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := MeasureWorktree(wt, []string{"main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each small file takes at least one block; the manifest counts as ignored.
+	if u.Tracked == 0 || u.Ignored == 0 || u.Copied == 0 || u.Symlinked == 0 {
+		t.Errorf("MeasureWorktree = %+v, want every category non-zero", u)
+	}
+	if u.Files != 4 {
+		t.Errorf("Files = %d, want 4 (main.go, index.js, .env, manifest)", u.Files)
+	}
+	if u.Total != u.Tracked+u.Ignored+u.Copied {
+		t.Errorf("Total = %d, want tracked+ignored+copied = %d", u.Total, u.Tracked+u.Ignored+u.Copied)
+	}
+	if u.Reclaimable != u.Total-u.Shared {
+		t.Errorf("Reclaimable = %d, want %d", u.Reclaimable, u.Total-u.Shared)
+	}
+
+	size, err := WorktreeSize(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size.Total != u.Total || size.Copied != u.Copied || size.Files != u.Files {
+		t.Errorf("WorktreeSize = %+v, want the Total, Copied and Files of %+v", size, u)
+	}
+}
+
+func TestMeasureWorktreeHardlinks(t *testing.T) {
+	wt := t.TempDir()
+	writeFile(t, filepath.Join(wt, "a.bin"), "contents")
+	if err := os.Link(filepath.Join(wt, "a.bin"), filepath.Join(wt, "b.bin")); err != nil {
+		t.Skip("hard links not supported:", err)
+	}
+
+	u, err := MeasureWorktree(wt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Files != 1 {
+		t.Errorf("Files = %d, want the two links counted once", u.Files)
+	}
+	if u.Shared != u.Total || u.Reclaimable != 0 {
+		t.Errorf("Shared = %d, Reclaimable = %d; want a hard-linked file counted as shared", u.Shared, u.Reclaimable)
+	}
+}
+
+func TestSharedCopySize(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", ".env"), "A=1\n")
+	writeFile(t, filepath.Join(shared, "symlink", "CLAUDE.md"), "notes")
+	writeFile(t, filepath.Join(shared, OverlaysDir, "web", "copy", "web.env"), "B=2\n")
+
+	cfg := &config.Config{
+		SharedDir: config.DefaultSharedDir,
+		Overlays:  []config.Overlay{{Name: "web", Branches: []string{"web/*"}}},
+	}
+	base := SharedCopySize(root, cfg, "main")
+	if base == 0 {
+		t.Fatal("SharedCopySize = 0, want the size of copy/.env")
+	}
+	if got := SharedCopySize(root, cfg, "web/login"); got <= base {
+		t.Errorf("SharedCopySize with the web overlay = %d, want more than the base %d", got, base)
+	}
+
+	// With rules, only what they copy counts; symlinks and hard links take
+	// no space in the worktree.
+	cfg.Shared.Rules = []config.SharedRule{
+		{Include: []string{"copy/**"}, Mode: config.RuleHardlink},
+		{Include: []string{"symlink/*"}, Mode: config.RuleSymlink},
+	}
+	if got := SharedCopySize(root, cfg, "main"); got != 0 {
+		t.Errorf("SharedCopySize with link rules = %d, want 0", got)
+	}
+	cfg.Shared.Rules[0].Mode = config.RuleCopy
+	if got := SharedCopySize(root, cfg, "main"); got != base {
+		t.Errorf("SharedCopySize with a copy rule = %d, want %d", got, base)
+	}
+}
@@ -0,0 +1,282 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+	"github.com/bkildow/wt-cli/internal/ui"
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchDebounce is how long Watch waits for changes to settle before
+// propagating them, so an editor's save or a git checkout is handled once.
+const WatchDebounce = 200 * time.Millisecond
+
+// Watch propagates changes under the shared dir to each target until ctx is
+// done. Only the files that changed are copied or re-rendered; a worktree
+// copy edited since it was applied is handled by the apply_conflict policy.
+// Symlinks already show changes to their source.
+func Watch(ctx context.Context, projectRoot string, cfg *config.Config, targets []HookTarget, debounce time.Duration, dryRun bool) error {
+	sharedDir := SharedPath(projectRoot, cfg)
+	if _, err := os.Stat(sharedDir); err != nil {
+		return err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = w.Close() }()
+
+	changed := make(map[string]bool)
+	if err := watchTree(w, sharedDir, nil); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			ui.Warning("watch: " + err.Error())
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			changed[ev.Name] = true
+			// fsnotify is not recursive: watch new directories, and treat
+			// what is already in them as changed.
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					if err := watchTree(w, ev.Name, changed); err != nil {
+						ui.Warning("watch: " + err.Error())
+					}
+				}
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			paths := slices.Sorted(maps.Keys(changed))
+			clear(changed)
+			propagateAll(projectRoot, cfg, targets, paths, dryRun)
+		}
+	}
+}
+
+// watchTree adds dir and the directories below it to w, marking the files
+// found in changed when it is non-nil.
+func watchTree(w *fsnotify.Watcher, dir string, changed map[string]bool) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			if changed != nil {
+				changed[p] = true
+			}
+			return nil
+		}
+		return w.Add(p)
+	})
+}
+
+// propagateAll applies one settled batch of changed paths to every target,
+// logging failures rather than stopping the watch.
+func propagateAll(projectRoot string, cfg *config.Config, targets []HookTarget, paths []string, dryRun bool) {
+	sharedDir := SharedPath(projectRoot, cfg)
+	// Read the secrets afresh for each batch, so rotated ones are picked up.
+	secrets := NewSecrets(projectRoot, cfg)
+	var total int
+	for _, t := range targets {
+		n, err := propagate(projectRoot, cfg, secrets, t, paths, dryRun)
+		total += n
+		if err != nil {
+			ui.Error(fmt.Sprintf("%s: %v", t.Branch, err))
+		}
+	}
+
+	var removed []string
+	for _, p := range paths {
+		if !exists(p) {
+			removed = append(removed, relOrAbs(sharedDir, p))
+		}
+	}
+	if total > 0 {
+		ui.Success(fmt.Sprintf("Propagated %d change(s) at %s", total, time.Now().Format(time.TimeOnly)))
+	}
+	if stale := staleSources(targets, removed); len(stale) > 0 {
+		ui.Warning(fmt.Sprintf("Removed from %s: %s; run 'wt apply --all --prune' to clean up the worktrees",
+			cfg.SharedDir, strings.Join(stale, ", ")))
+	}
+}
+
+// propagate writes the planned items for the target whose source is among
+// paths and records them in the worktree's manifest.
+func propagate(projectRoot string, cfg *config.Config, secrets *Secrets, t HookTarget, paths []string, dryRun bool) (int, error) {
+	vars := targetVars(cfg, t, secrets)
+	plan, err := planShared(projectRoot, cfg, Layers(projectRoot, cfg, t.Branch), &vars)
+	if err != nil {
+		return 0, err
+	}
+	items := plan.affected(t.WorktreePath, paths)
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	ui.Step("Updating " + t.Branch)
+	var rec *manifestRecorder
+	if !dryRun {
+		rec = &manifestRecorder{worktree: t.WorktreePath}
+	}
+	// A copy still matching what was last applied has no local changes to
+	// protect, even though it now differs from the shared file.
+	applied := make(map[string]ManifestEntry)
+	if m, err := ReadManifest(t.WorktreePath); err == nil && m != nil {
+		for _, e := range m.Entries {
+			applied[e.Path] = e
+		}
+	}
+
+	var count int
+	for _, it := range items {
+		policy := cfg.ApplyConflictOrDefault()
+		if e, ok := applied[it.Dest]; ok && e.Kind == ManifestCopy {
+			if same, err := e.unchanged(filepath.Join(t.WorktreePath, it.Dest)); err == nil && same {
+				policy = config.ConflictOverwrite
+			}
+		}
+		n, err := placeItem(it, t.WorktreePath, cfg, policy, dryRun, &vars, rec)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	if rec != nil {
+		if err := updateManifest(t.WorktreePath, rec.entries); err != nil {
+			return count, fmt.Errorf("write %s: %w", ManifestFile, err)
+		}
+	}
+	return count, nil
+}
+
+// affected returns the applied items whose source is one of paths, or is a
+// symlinked directory containing one. Symlinks already pointing at their
+// source are left out, since the change shows through them.
+func (p rulePlan) affected(worktreePath string, paths []string) []ruleItem {
+	sep := string(filepath.Separator)
+	var out []ruleItem
+	for _, i := range p.chosen {
+		it := p.items[i]
+		hit := slices.ContainsFunc(paths, func(path string) bool {
+			return path == it.Src || (it.IsDir && strings.HasPrefix(path, it.Src+sep))
+		})
+		if !hit {
+			continue
+		}
+		if it.Mode == config.RuleSymlink && linksTo(filepath.Join(worktreePath, it.Dest), it.Src) {
+			continue
+		}
+		out = append(out, it)
+	}
+	slices.SortFunc(out, func(a, b ruleItem) int { return strings.Compare(a.Dest, b.Dest) })
+	return out
+}
+
+// planShared returns the items apply places in a worktree with the given
+// layers, from the shared rules or else the directory convention.
+func planShared(projectRoot string, cfg *config.Config, layers []Layer, vars *TemplateVars) (rulePlan, error) {
+	if cfg.Shared.HasRules() {
+		return planRules(projectRoot, cfg, layers, vars)
+	}
+
+	plan := rulePlan{chosen: make(map[string]int)}
+	add := func(it ruleItem) {
+		plan.chosen[it.Dest] = len(plan.items)
+		plan.items = append(plan.items, it)
+	}
+
+	copies, err := planCopies(layers, vars)
+	if err != nil {
+		return plan, err
+	}
+	for i, layer := range layers {
+		copyDir := filepath.Join(layer.Dir, "copy")
+		err := filepath.WalkDir(copyDir, func(p string, d fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) && p == copyDir {
+				return filepath.SkipAll
+			}
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(copyDir, p)
+			if err != nil {
+				return err
+			}
+			dest := copyDest(rel, vars)
+			if copies.owns(i, dest) {
+				add(ruleItem{Dest: dest, Src: p, Source: layer.source("copy", rel), Mode: config.RuleCopy, Render: dest != rel, Rule: -1, Layer: i})
+			}
+			return nil
+		})
+		if err != nil {
+			return plan, err
+		}
+	}
+
+	// Symlinks are applied after copies and win at the same path.
+	links, err := planSymlinks(layers)
+	if err != nil {
+		return plan, err
+	}
+	for _, name := range slices.Sorted(maps.Keys(links)) {
+		layer := layers[links[name]]
+		src := filepath.Join(layer.Dir, "symlink", name)
+		info, err := os.Lstat(src)
+		if err != nil {
+			return plan, err
+		}
+		add(ruleItem{Dest: name, Src: src, Source: layer.source("symlink", name), Mode: config.RuleSymlink, IsDir: info.IsDir(), Rule: -1, Layer: links[name]})
+	}
+	return plan, nil
+}
+
+// staleSources returns the removed sources, relative to the shared dir,
+// that a target's manifest still lists.
+func staleSources(targets []HookTarget, removed []string) []string {
+	if len(removed) == 0 {
+		return nil
+	}
+	var stale []string
+	for _, t := range targets {
+		m, err := ReadManifest(t.WorktreePath)
+		if err != nil || m == nil {
+			continue
+		}
+		for _, e := range m.Entries {
+			for _, r := range removed {
+				if (e.Source == r || strings.HasPrefix(e.Source, r+string(filepath.Separator))) && !slices.Contains(stale, r) {
+					stale = append(stale, r)
+				}
+			}
+		}
+	}
+	slices.Sort(stale)
+	return stale
+}
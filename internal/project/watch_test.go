@@ -0,0 +1,122 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestPropagate(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", ".env"), "A=1\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "app.ini.template"), "branch=${BRANCH_NAME}\n")
+	writeFile(t, filepath.Join(shared, "copy", "untouched.txt"), "same\n")
+	writeFile(t, filepath.Join(shared, "symlink", "CLAUDE.md"), "notes")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir, ApplyConflict: config.ConflictSkipModified}
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	vars := NewTemplateVars(root, wt, "main")
+	if _, err := Apply(root, wt, cfg, false, &vars); err != nil {
+		t.Fatal(err)
+	}
+
+	// Edit a copy and a template, change one worktree copy locally, and
+	// add a new symlinked entry.
+	writeFile(t, filepath.Join(shared, "copy", ".env"), "A=2\n")
+	writeFile(t, filepath.Join(shared, "copy", "conf", "app.ini.template"), "name=${BRANCH_NAME}\n")
+	writeFile(t, filepath.Join(shared, "copy", "untouched.txt"), "changed upstream\n")
+	writeFile(t, filepath.Join(wt, "untouched.txt"), "local edit\n")
+	writeFile(t, filepath.Join(shared, "symlink", "tools", "run.sh"), "#!/bin/sh\n")
+	writeFile(t, filepath.Join(shared, "symlink", "CLAUDE.md"), "new notes")
+
+	target := HookTarget{ProjectRoot: root, WorktreePath: wt, Branch: "main"}
+	paths := []string{
+		filepath.Join(shared, "copy", ".env"),
+		filepath.Join(shared, "copy", "conf", "app.ini.template"),
+		filepath.Join(shared, "copy", "untouched.txt"),
+		filepath.Join(shared, "symlink", "tools"),
+		filepath.Join(shared, "symlink", "CLAUDE.md"),
+	}
+	n, err := propagate(root, cfg, nil, target, paths, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// .env, the template, and the new symlink; CLAUDE.md is already a
+	// link and untouched.txt keeps its local edit.
+	if n != 3 {
+		t.Errorf("propagate() = %d, want 3", n)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, ".env")); string(got) != "A=2\n" {
+		t.Errorf(".env = %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, "conf", "app.ini")); string(got) != "name=main\n" {
+		t.Errorf("conf/app.ini = %q", got)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wt, "untouched.txt")); string(got) != "local edit\n" {
+		t.Errorf("untouched.txt = %q, want the local edit kept", got)
+	}
+	if !linksTo(filepath.Join(wt, "tools"), filepath.Join(shared, "symlink", "tools")) {
+		t.Error("tools was not symlinked")
+	}
+
+	m, err := ReadManifest(wt)
+	if err != nil || m == nil {
+		t.Fatalf("ReadManifest() = %v, %v", m, err)
+	}
+	vars = NewTemplateVars(root, wt, "main")
+	drifts, err := CheckDrift(root, wt, cfg, &vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drifts) != 1 || drifts[0].Rel != "untouched.txt" {
+		t.Errorf("CheckDrift() = %+v, want only untouched.txt", drifts)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	root := t.TempDir()
+	shared := filepath.Join(root, "shared")
+	writeFile(t, filepath.Join(shared, "copy", ".env"), "A=1\n")
+
+	cfg := &config.Config{SharedDir: config.DefaultSharedDir}
+	wt := filepath.Join(root, "worktrees", "main")
+	if err := os.MkdirAll(wt, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, root, cfg, []HookTarget{{ProjectRoot: root, WorktreePath: wt, Branch: "main"}}, 10*time.Millisecond, false)
+	}()
+
+	// Give the watcher time to register, then add a file in a new directory.
+	dest := filepath.Join(wt, "nested", "b.txt")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		writeFile(t, filepath.Join(shared, "copy", "nested", "b.txt"), "b\n")
+		time.Sleep(50 * time.Millisecond)
+		if _, err := os.Stat(dest); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch() = %v", err)
+	}
+	if got, _ := os.ReadFile(dest); string(got) != "b\n" {
+		t.Errorf("nested/b.txt = %q, want it propagated", got)
+	}
+	if _, err := os.Stat(filepath.Join(wt, ".env")); !os.IsNotExist(err) {
+		t.Errorf(".env was copied without changing: %v", err)
+	}
+}
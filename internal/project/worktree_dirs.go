@@ -0,0 +1,69 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+// UnknownWorktreeDirs returns the directories under the worktrees dir that
+// are neither a worktree git knows, listed in known, nor a parent of one:
+// worktrees whose git metadata was pruned, or directories created by hand.
+// The git dir and shared dir, and their parents, are never unknown. A
+// worktrees dir that is or contains the project root isn't scanned, since
+// everything else in the project, or beside it, would look unknown.
+func UnknownWorktreeDirs(projectRoot string, cfg *config.Config, known []string) ([]string, error) {
+	dir := WorktreesPath(projectRoot, cfg)
+	root := ResolvePath(dir)
+	sep := string(filepath.Separator)
+	project := ResolvePath(projectRoot)
+	if project == root || strings.HasPrefix(project, root+sep) {
+		return nil, fmt.Errorf("worktree_dir %q holds the project itself; not scanning it for unknown directories", cfg.WorktreeDir)
+	}
+
+	worktrees := make(map[string]bool, len(known))
+	for _, k := range known {
+		worktrees[ResolvePath(k)] = true
+	}
+	protected := []string{ResolvePath(GitDirPath(projectRoot, cfg)), ResolvePath(SharedPath(projectRoot, cfg))}
+
+	var unknown []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if path == root || !d.IsDir() {
+			return nil
+		}
+		if worktrees[path] {
+			return filepath.SkipDir
+		}
+		for _, p := range protected {
+			if p == path {
+				return filepath.SkipDir
+			}
+			if strings.HasPrefix(p, path+sep) {
+				return nil // a parent of the git or shared dir
+			}
+		}
+		for wt := range worktrees {
+			if strings.HasPrefix(wt, path+sep) {
+				return nil // a parent of nested worktrees, like worktrees/feature
+			}
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		unknown = append(unknown, filepath.Join(dir, rel))
+		return filepath.SkipDir
+	})
+	return unknown, err
+}
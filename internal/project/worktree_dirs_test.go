@@ -0,0 +1,71 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/bkildow/wt-cli/internal/config"
+)
+
+func TestUnknownWorktreeDirs(t *testing.T) {
+	root := t.TempDir()
+	cfg := &config.Config{WorktreeDir: "worktrees"}
+	wts := filepath.Join(root, "worktrees")
+	for _, d := range []string{"main", "feature/auth", "feature/stray", "leftover/node_modules"} {
+		if err := os.MkdirAll(filepath.Join(wts, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(wts, "notes.txt"), "not a dir")
+
+	known := []string{filepath.Join(wts, "main"), filepath.Join(wts, "feature", "auth")}
+	got, err := UnknownWorktreeDirs(root, cfg, known)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(wts, "feature", "stray"), filepath.Join(wts, "leftover")}
+	if !slices.Equal(got, want) {
+		t.Errorf("UnknownWorktreeDirs = %v, want %v", got, want)
+	}
+
+	// A project without a worktrees dir has nothing unknown.
+	if got, err := UnknownWorktreeDirs(t.TempDir(), cfg, nil); err != nil || len(got) != 0 {
+		t.Errorf("without worktrees dir = %v, %v", got, err)
+	}
+}
+
+func TestUnknownWorktreeDirsSkipsProjectDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{".bare", "shared/copy", "worktrees/main", "stray"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The worktrees dir is the project root, or contains it.
+	for _, dir := range []string{".", ".."} {
+		cfg := &config.Config{GitDir: ".bare", WorktreeDir: dir, SharedDir: "shared"}
+		if got, err := UnknownWorktreeDirs(root, cfg, []string{filepath.Join(root, "worktrees", "main")}); err == nil || len(got) != 0 {
+			t.Errorf("worktree_dir %q = %v, %v; want an error and nothing to clean", dir, got, err)
+		}
+	}
+
+	// The git and shared dirs nested in the worktrees dir are left out.
+	nested := t.TempDir()
+	for _, d := range []string{"work/.bare", "work/config/shared", "work/config/stray", "work/main"} {
+		if err := os.MkdirAll(filepath.Join(nested, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{GitDir: "work/.bare", WorktreeDir: "work", SharedDir: "work/config/shared"}
+	got, err := UnknownWorktreeDirs(nested, cfg, []string{filepath.Join(nested, "work", "main")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(nested, "work", "config", "stray")}
+	if !slices.Equal(got, want) {
+		t.Errorf("UnknownWorktreeDirs = %v, want %v", got, want)
+	}
+}
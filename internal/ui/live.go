@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/ansi"
+	isatty "github.com/mattn/go-isatty"
+	"golang.org/x/sys/unix"
+)
+
+// SpinnerFrames are the animation frames for live status lines.
+var SpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// defaultTerminalWidth is used when the terminal size cannot be queried.
+const defaultTerminalWidth = 80
+
+// IsTerminal reports whether w writes directly to a terminal. Wrapped
+// writers (such as the colorprofile writer used for background setup logs)
+// are never terminals, so callers fall back to plain line output there.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// TerminalWidth returns the column count of the terminal behind w, or a
+// conservative default when w is not a terminal.
+func TerminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return defaultTerminalWidth
+	}
+	fd := int(f.Fd()) //nolint:gosec // G115: file descriptors always fit in an int
+	ws, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 {
+		return defaultTerminalWidth
+	}
+	return int(ws.Col)
+}
+
+// LiveRegion redraws a fixed block of status lines in place at the bottom
+// of a terminal. Lines are truncated to the terminal width so the cursor
+// arithmetic stays correct; wrapped lines would leave stale rows behind.
+type LiveRegion struct {
+	w     io.Writer
+	width int
+
+	mu    sync.Mutex
+	lines []string
+	drawn int
+}
+
+// NewLiveRegion returns a region of n lines that renders to w.
+func NewLiveRegion(w io.Writer, n int) *LiveRegion {
+	return &LiveRegion{
+		w:     w,
+		width: TerminalWidth(w),
+		lines: make([]string, n),
+	}
+}
+
+// Set replaces line i. The change is visible on the next Render.
+func (r *LiveRegion) Set(i int, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if i >= 0 && i < len(r.lines) {
+		r.lines[i] = line
+	}
+}
+
+// Render redraws the region over its previous frame.
+func (r *LiveRegion) Render() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	if r.drawn == 0 {
+		b.WriteString("\x1b[?25l") // hide the cursor while animating
+	} else {
+		b.WriteString("\x1b[" + strconv.Itoa(r.drawn) + "A")
+	}
+	for _, line := range r.lines {
+		b.WriteString("\r\x1b[2K")
+		b.WriteString(ansi.Truncate(line, r.width-1, "…"))
+		b.WriteByte('\n')
+	}
+	r.drawn = len(r.lines)
+	_, _ = io.WriteString(r.w, b.String())
+}
+
+// Stop erases the region and restores the cursor, leaving the terminal
+// ready for regular output.
+func (r *LiveRegion) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var b strings.Builder
+	if r.drawn > 0 {
+		b.WriteString("\x1b[" + strconv.Itoa(r.drawn) + "A\r\x1b[J")
+	}
+	b.WriteString("\x1b[?25h")
+	r.drawn = 0
+	_, _ = io.WriteString(r.w, b.String())
+}
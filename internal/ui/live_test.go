@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIsTerminalNonFile(t *testing.T) {
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Error("IsTerminal(buffer) = true, want false")
+	}
+}
+
+func TestLiveRegionRedrawsInPlace(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewLiveRegion(&buf, 2)
+
+	r.Set(0, "first")
+	r.Set(1, "second")
+	r.Render()
+	if out := buf.String(); !strings.HasPrefix(out, "\x1b[?25l") || strings.Contains(out, "\x1b[2A") {
+		t.Errorf("first frame = %q, want hidden cursor and no cursor movement", out)
+	}
+
+	buf.Reset()
+	r.Set(1, "updated")
+	r.Render()
+	out := buf.String()
+	if !strings.HasPrefix(out, "\x1b[2A") {
+		t.Errorf("second frame = %q, want cursor moved up 2 lines", out)
+	}
+	if !strings.Contains(out, "updated\n") || strings.Contains(out, "second") {
+		t.Errorf("second frame = %q, want updated line only", out)
+	}
+
+	buf.Reset()
+	r.Stop()
+	if out := buf.String(); out != "\x1b[2A\r\x1b[J\x1b[?25h" {
+		t.Errorf("Stop() wrote %q, want erase and show cursor", out)
+	}
+}
+
+func TestLiveRegionTruncatesToWidth(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewLiveRegion(&buf, 1)
+	r.Set(0, strings.Repeat("x", defaultTerminalWidth*2))
+	r.Render()
+
+	line := strings.TrimSuffix(strings.TrimPrefix(buf.String(), "\x1b[?25l\r\x1b[2K"), "\n")
+	if got := len([]rune(line)); got != defaultTerminalWidth-1 {
+		t.Errorf("rendered width = %d, want %d", got, defaultTerminalWidth-1)
+	}
+}